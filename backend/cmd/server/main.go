@@ -2,13 +2,19 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"finance-tracker/internal/auth"
 	"finance-tracker/internal/config"
+	"finance-tracker/internal/deposits"
+	"finance-tracker/internal/handlers"
 	"finance-tracker/internal/logger"
+	"finance-tracker/internal/navprovider"
+	"finance-tracker/internal/recurring"
 	"finance-tracker/internal/router"
 	"finance-tracker/internal/storage"
 )
@@ -22,15 +28,68 @@ func main() {
 	defer log.Close() // Ensure log file is closed on exit
 
 	// Log configuration
-	log.Info("Configuration: Port=%s, DataDir=%s, LogLevel=%s, LogDir=%s",
-		cfg.Port, cfg.DataDir, cfg.LogLevel, cfg.LogDir)
+	log.Info("Configuration: Port=%s, DataDir=%s, StorageDriver=%s, LogLevel=%s, LogDir=%s",
+		cfg.Port, cfg.DataDir, cfg.StorageDriver, cfg.LogLevel, cfg.LogDir)
 
 	// Initialize data store
-	store := storage.NewDataStore(cfg.DataDir)
-	log.Info("Data store initialized at %s", cfg.DataDir)
+	store, err := storage.Open(cfg.StorageDriver, cfg.DataDir)
+	if err != nil {
+		log.Error("Failed to initialize data store: %v", err)
+		log.Close()
+		os.Exit(1)
+	}
+	log.Info("Data store (%s) initialized at %s", cfg.StorageDriver, cfg.DataDir)
+
+	// NAV provider for mutual fund investments
+	provider, err := navprovider.New(cfg.NAVProvider, cfg.NAVSource)
+	if err != nil {
+		log.Error("Invalid NAV provider: %v", err)
+		log.Close()
+		os.Exit(1)
+	}
+	navInterval, err := parseInterval(cfg.NAVRefreshInterval)
+	if err != nil {
+		log.Error("Invalid nav_refresh_interval: %v", err)
+		log.Close()
+		os.Exit(1)
+	}
+	nav := navprovider.NewRefresher(store, provider)
+
+	// Logins
+	sessionTTL, err := parseInterval(cfg.SessionTTL)
+	if err != nil {
+		log.Error("Invalid session_ttl: %v", err)
+		log.Close()
+		os.Exit(1)
+	}
+	authenticator := auth.New(store, sessionTTL)
+	setupCode := ""
+	if len(store.GetUsers()) == 0 {
+		if setupCode, err = authenticator.SetupCode(); err != nil {
+			log.Error("Failed to make setup code: %v", err)
+			log.Close()
+			os.Exit(1)
+		}
+		log.Info("No users yet: create the first one with POST /v1/api/auth/setup and the setup code printed below")
+	}
+
+	// Background jobs
+	engine := recurring.NewEngine(store)
+	go runEvery(recurringInterval, func() { postRecurring(engine, log) })
+	depositEngine := deposits.NewEngine(store)
+	go runEvery(depositInterval, func() { accrueDeposits(depositEngine, log) })
+	if navInterval > 0 {
+		go runEvery(navInterval, func() { refreshNAV(nav, log) })
+		log.Info("NAV refresh (%s) scheduled every %s", provider.Name(), navInterval)
+	}
 
 	// Register all routes and get Mux router
-	r := router.RegisterRoutes(store)
+	r := router.RegisterRoutes(store, handlers.Services{
+		Recurring: engine,
+		NAV:       nav,
+		Deposits:  depositEngine,
+		Auth:      authenticator,
+	}, cfg.CORSOrigins)
 	log.Info("Routes registered")
 
 	// Start server
@@ -39,13 +98,75 @@ func main() {
 	fmt.Println("📝 Logs stored in: " + cfg.LogDir)
 	fmt.Println("\nAPI Endpoints:")
 	fmt.Println("  GET        /health (health check)")
+	fmt.Println("  POST       /v1/api/auth/{setup,login,logout,password}")
+	fmt.Println("  GET        /v1/api/auth/me")
+	fmt.Println("  GET/POST   /v1/api/users")
+	fmt.Println("  PUT/DELETE /v1/api/users/{id}")
+	fmt.Println("  GET/POST   /v1/api/tokens")
+	fmt.Println("  DELETE     /v1/api/tokens/{id}")
 	fmt.Println("  GET/POST   /v1/api/investments")
 	fmt.Println("  PUT/DELETE /v1/api/investments/{id}")
+	fmt.Println("  POST       /v1/api/investments/refresh-nav")
+	fmt.Println("  GET/POST   /v1/api/investments/{id}/transactions")
+	fmt.Println("  PUT/DELETE /v1/api/investments/{id}/transactions/{txnId}")
+	fmt.Println("  GET        /v1/api/investments/{id}/lots")
+	fmt.Println("  GET        /v1/api/investments/{id}/history")
+	fmt.Println("  GET        /v1/api/investments/{id}/returns")
+	fmt.Println("  GET        /v1/api/investments/{id}/deposit")
+	fmt.Println("  GET        /v1/api/deposits/maturities")
+	fmt.Println("  POST       /v1/api/deposits/accrue")
+	fmt.Println("  GET        /v1/api/portfolio/{history,returns}")
+	fmt.Println("  GET/POST   /v1/api/accounts")
+	fmt.Println("  GET/PUT/DELETE /v1/api/accounts/{id}")
+	fmt.Println("  GET        /v1/api/accounts/balances")
+	fmt.Println("  GET        /v1/api/accounts/{id}/{ledger,statements}")
+	fmt.Println("  GET/POST   /v1/api/transfers")
+	fmt.Println("  PUT/DELETE /v1/api/transfers/{id}")
 	fmt.Println("  GET/POST   /v1/api/expenses")
 	fmt.Println("  PUT/DELETE /v1/api/expenses/{id}")
+	fmt.Println("  GET/POST   /v1/api/budgets")
+	fmt.Println("  PUT/DELETE /v1/api/budgets/{id}")
+	fmt.Println("  GET        /v1/api/budgets/status")
+	fmt.Println("  GET/POST   /v1/api/recurring")
+	fmt.Println("  PUT/DELETE /v1/api/recurring/{id}")
+	fmt.Println("  GET        /v1/api/recurring/upcoming")
+	fmt.Println("  POST       /v1/api/recurring/{id}/occurrences/{date}/{confirm,skip}")
+	fmt.Println("  GET/POST   /v1/api/chits")
+	fmt.Println("  GET/PUT/DELETE /v1/api/chits/{id}")
+	fmt.Println("  GET        /v1/api/chits/{status,upcoming}")
+	fmt.Println("  POST       /v1/api/chits/{id}/auctions")
+	fmt.Println("  GET/POST   /v1/api/loans")
+	fmt.Println("  GET/PUT/DELETE /v1/api/loans/{id}")
+	fmt.Println("  GET        /v1/api/loans/{id}/schedule")
+	fmt.Println("  POST       /v1/api/loans/{id}/{prepayments,rate-changes}")
+	fmt.Println("  GET        /v1/api/loans/{status,tax}")
+	fmt.Println("  GET        /v1/api/networth")
+	fmt.Println("  GET        /v1/api/duplicates")
+	fmt.Println("  POST       /v1/api/duplicates/merge")
+	fmt.Println("  GET        /v1/api/duplicates/merges")
+	fmt.Println("  GET        /v1/api/balances")
+	fmt.Println("  POST       /v1/api/balances/settle")
+	fmt.Println("  GET/POST   /v1/api/settlements")
+	fmt.Println("  PUT/DELETE /v1/api/settlements/{id}")
+	fmt.Println("  GET        /v1/api/tags")
+	fmt.Println("  POST       /v1/api/tags/{rename,merge}")
+	fmt.Println("  GET/POST   /v1/api/rules")
+	fmt.Println("  PUT/DELETE /v1/api/rules/{id}")
+	fmt.Println("  GET/POST   /v1/api/rules/reapply")
 	fmt.Println("  GET/PUT    /v1/api/settings")
+	fmt.Println("  GET        /v1/api/reports/{summary,cashflow,breakdown,capital-gains}")
 	fmt.Println("  GET        /v1/api/export")
 	fmt.Println("  POST       /v1/api/import")
+	fmt.Println("  GET        /v1/api/export/{ofx,qif}")
+	fmt.Println("  POST       /v1/api/import/{ofx,qif}")
+	fmt.Println("  GET/POST   /v1/api/import/statement")
+	fmt.Println("  GET/PUT/DELETE /v1/api/import/statement/{id}")
+	fmt.Println("  POST       /v1/api/import/statement/{id}/commit")
+	fmt.Println("  GET/POST   /v1/api/import/statement/profiles")
+	fmt.Println("  PUT/DELETE /v1/api/import/statement/profiles/{id}")
+	if setupCode != "" {
+		fmt.Printf("\n🔑 No users yet. Setup code for POST /v1/api/auth/setup: %s\n", setupCode)
+	}
 
 	log.Info("Starting server on port %s", cfg.Port)
 
@@ -55,6 +176,11 @@ func main() {
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
 		log.Info("Shutting down server gracefully...")
+		if closer, ok := store.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Error("Failed to close data store: %v", err)
+			}
+		}
 		os.Exit(0)
 	}()
 
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"finance-tracker/internal/deposits"
+	"finance-tracker/internal/logger"
+	"finance-tracker/internal/navprovider"
+	"finance-tracker/internal/recurring"
+)
+
+// recurringInterval is how often due recurring transactions are posted
+const recurringInterval = time.Hour
+
+// depositInterval is how often deposits are accrued and their interest posted
+const depositInterval = 6 * time.Hour
+
+// navRefreshTimeout bounds one scheduled NAV refresh
+const navRefreshTimeout = 5 * time.Minute
+
+// parseInterval parses a schedule interval from config. Empty or "0" means
+// disabled and returns 0.
+func parseInterval(s string) (time.Duration, error) {
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("interval %s is shorter than a minute", d)
+	}
+	return d, nil
+}
+
+// runEvery calls job immediately and then once per interval, forever
+func runEvery(interval time.Duration, job func()) {
+	job()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		job()
+	}
+}
+
+// postRecurring runs one recurring-transaction pass and logs the outcome.
+// The first pass at startup catches up occurrences missed while the server
+// was down.
+func postRecurring(engine *recurring.Engine, log *logger.Logger) {
+	result := engine.Run()
+	if len(result.Posted) > 0 {
+		log.Info("Posted %d recurring transaction(s)", len(result.Posted))
+	}
+	for _, msg := range result.Errors {
+		log.Error("Recurring transaction failed: %s", msg)
+	}
+}
+
+// accrueDeposits brings FD and RD values up to date and logs any interest
+// posted or deposits closed on maturity
+func accrueDeposits(engine *deposits.Engine, log *logger.Logger) {
+	result := engine.Run()
+	for _, p := range result.Posted {
+		if p.Kind == "maturity" {
+			log.Info("Deposit %s matured on %s: %.2f", p.Name, p.Date, p.Amount)
+		} else {
+			log.Info("Posted %.2f interest from %s for %s", p.Amount, p.Name, p.Date)
+		}
+	}
+	for _, msg := range result.Errors {
+		log.Error("Deposit accrual failed: %s", msg)
+	}
+}
+
+// refreshNAV runs one scheduled NAV refresh and logs the outcome
+func refreshNAV(nav *navprovider.Refresher, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), navRefreshTimeout)
+	defer cancel()
+
+	result, err := nav.Refresh(ctx)
+	if err != nil {
+		log.Error("NAV refresh failed: %v", err)
+		return
+	}
+	if result.Total > 0 {
+		log.Info("NAV refresh (%s): updated %d of %d investment(s)", result.Provider, result.Updated, result.Total)
+	}
+	if len(result.Missing) > 0 {
+		log.Warn("NAV refresh: no NAV for scheme code(s) %v", result.Missing)
+	}
+	for _, msg := range result.Errors {
+		log.Error("NAV refresh: failed to update %s", msg)
+	}
+}
@@ -0,0 +1,238 @@
+// Package accounts derives account balances from the records that move
+// money. Expenses debit the account they name and incomes credit it,
+// investment purchases and sales debit and credit theirs, and transfers do
+// both. A UPI handle linked to a bank account moves that account's money.
+// Credit cards carry a negative balance for what is owed and are billed in
+// statement cycles (see Statements).
+package accounts
+
+import (
+	"math"
+	"sort"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// Entry kinds
+const (
+	EntryOpening    = "opening"
+	EntryExpense    = "expense"
+	EntryIncome     = "income"
+	EntryInvestment = "investment"
+	EntryTransfer   = "transfer"
+)
+
+// Entry is one movement of money in an account
+type Entry struct {
+	Date        string  `json:"date"`
+	Kind        string  `json:"kind"`  // One of the Entry* constants
+	RefID       string  `json:"refId"` // Expense, income, investment or transfer ID
+	Description string  `json:"description"`
+	Via         string  `json:"via,omitempty"` // UPI handle the money moved through
+	Amount      float64 `json:"amount"`        // Positive in, negative out
+	Balance     float64 `json:"balance"`       // Running balance after the entry
+}
+
+// Book is everything that can move money in an account
+type Book struct {
+	Accounts    []models.Account
+	Expenses    []models.Expense
+	Incomes     []models.Income
+	Investments []models.Investment
+	Transfers   []models.Transfer
+}
+
+// Find returns the account with id
+func (b Book) Find(id string) (models.Account, bool) {
+	for _, a := range b.Accounts {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return models.Account{}, false
+}
+
+// Ledgers lists the entries of every account in date order with running
+// balances. Linked UPI handles have no ledger of their own; their entries
+// appear in the linked account's.
+func (b Book) Ledgers() map[string][]Entry {
+	byID := map[string]models.Account{}
+	for _, a := range b.Accounts {
+		byID[a.ID] = a
+	}
+	ledgers := map[string][]Entry{}
+	add := func(accountID string, e Entry) {
+		a, ok := byID[accountID]
+		if !ok {
+			return
+		}
+		if a.Kind == models.AccountUPI && a.LinkedAccountID != "" {
+			e.Via = a.Name
+			accountID = a.LinkedAccountID
+		}
+		e.Date = calc.DateOnly(e.Date)
+		ledgers[accountID] = append(ledgers[accountID], e)
+	}
+
+	for _, a := range b.Accounts {
+		if a.OpeningBalance != 0 {
+			add(a.ID, Entry{Date: a.OpeningDate, Kind: EntryOpening, RefID: a.ID, Description: "Opening balance", Amount: a.OpeningBalance})
+		}
+	}
+	for _, exp := range b.Expenses {
+		if exp.AccountID != "" {
+			add(exp.AccountID, Entry{Date: exp.Date, Kind: EntryExpense, RefID: exp.ID, Description: exp.Desc, Amount: -exp.Amount})
+		}
+	}
+	for _, inc := range b.Incomes {
+		if inc.AccountID != "" {
+			add(inc.AccountID, Entry{Date: inc.Date, Kind: EntryIncome, RefID: inc.ID, Description: inc.Source, Amount: inc.Amount})
+		}
+	}
+	for _, inv := range b.Investments {
+		for _, t := range inv.Transactions {
+			if t.AccountID == "" {
+				continue
+			}
+			// Switches, reinvested dividends and bonuses move no cash
+			switch t.Type {
+			case models.TxnBuy, models.TxnSIP:
+				add(t.AccountID, Entry{Date: t.Date, Kind: EntryInvestment, RefID: inv.ID, Description: inv.Name + " " + t.Type, Amount: -t.Amount})
+			case models.TxnSell, models.TxnRedeem:
+				add(t.AccountID, Entry{Date: t.Date, Kind: EntryInvestment, RefID: inv.ID, Description: inv.Name + " " + t.Type, Amount: t.Amount})
+			}
+		}
+	}
+	for _, t := range b.Transfers {
+		add(t.FromAccountID, Entry{Date: t.Date, Kind: EntryTransfer, RefID: t.ID, Description: "Transfer to " + byID[t.ToAccountID].Name, Amount: -t.Amount})
+		add(t.ToAccountID, Entry{Date: t.Date, Kind: EntryTransfer, RefID: t.ID, Description: "Transfer from " + byID[t.FromAccountID].Name, Amount: t.Amount})
+	}
+
+	for id, entries := range ledgers {
+		// The opening balance comes first on its date
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Date != entries[j].Date {
+				return entries[i].Date < entries[j].Date
+			}
+			return entries[i].Kind == EntryOpening && entries[j].Kind != EntryOpening
+		})
+		var balance float64
+		for i := range entries {
+			balance += entries[i].Amount
+			entries[i].Balance = calc.Round2(balance)
+		}
+		ledgers[id] = entries
+	}
+	return ledgers
+}
+
+// BalanceOn is the balance after the entries dated on or before date
+func BalanceOn(entries []Entry, date string) float64 {
+	var balance float64
+	for _, e := range entries {
+		if e.Date > date {
+			break
+		}
+		balance = e.Balance
+	}
+	return balance
+}
+
+// References counts the records that name account id
+func (b Book) References(id string) int {
+	n := 0
+	for _, a := range b.Accounts {
+		if a.LinkedAccountID == id {
+			n++
+		}
+	}
+	for _, exp := range b.Expenses {
+		if exp.AccountID == id {
+			n++
+		}
+	}
+	for _, inc := range b.Incomes {
+		if inc.AccountID == id {
+			n++
+		}
+	}
+	for _, inv := range b.Investments {
+		for _, t := range inv.Transactions {
+			if t.AccountID == id {
+				n++
+			}
+		}
+	}
+	for _, t := range b.Transfers {
+		if t.FromAccountID == id || t.ToAccountID == id {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary is an account's balance on a date
+type Summary struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"`
+	Institution     string   `json:"institution,omitempty"`
+	Member          string   `json:"member,omitempty"`
+	LinkedAccountID string   `json:"linkedAccountId,omitempty"`
+	Closed          bool     `json:"closed"`
+	Balance         float64  `json:"balance"`
+	Outstanding     float64  `json:"outstanding,omitempty"`     // Credit cards: amount owed
+	AvailableCredit *float64 `json:"availableCredit,omitempty"` // Credit cards with a limit
+}
+
+// Report is the balance of every account on a date
+type Report struct {
+	AsOf     string    `json:"asOf"`
+	Accounts []Summary `json:"accounts"`
+	Total    float64   `json:"total"` // Cash and balances less card dues
+}
+
+// Summarise values account a on asOf from its ledger
+func Summarise(a models.Account, entries []Entry, asOf string) Summary {
+	s := Summary{
+		ID:              a.ID,
+		Name:            a.Name,
+		Kind:            a.Kind,
+		Institution:     a.Institution,
+		Member:          a.Member,
+		LinkedAccountID: a.LinkedAccountID,
+		Closed:          a.Closed,
+		Balance:         BalanceOn(entries, asOf),
+	}
+	if a.Kind == models.AccountCreditCard {
+		s.Outstanding = calc.Round2(math.Max(0, -s.Balance))
+		if a.CreditLimit > 0 {
+			available := calc.Round2(a.CreditLimit - s.Outstanding)
+			s.AvailableCredit = &available
+		}
+	}
+	return s
+}
+
+// Balances values every account on asOf. A linked UPI handle shows its bank
+// account's balance but is not counted twice in the total.
+func Balances(b Book, asOf string) Report {
+	ledgers := b.Ledgers()
+	r := Report{AsOf: asOf, Accounts: []Summary{}}
+	for _, a := range b.Accounts {
+		owner := a.ID
+		if a.Kind == models.AccountUPI && a.LinkedAccountID != "" {
+			owner = a.LinkedAccountID
+		}
+		s := Summarise(a, ledgers[owner], asOf)
+		r.Accounts = append(r.Accounts, s)
+		if owner == a.ID {
+			r.Total += s.Balance
+		}
+	}
+	r.Total = calc.Round2(r.Total)
+	return r
+}
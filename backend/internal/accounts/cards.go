@@ -0,0 +1,139 @@
+package accounts
+
+import (
+	"math"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+// Statement is one billing cycle of a credit card. Amounts owed are
+// positive.
+type Statement struct {
+	From    string  `json:"from"` // First day of the cycle
+	To      string  `json:"to"`   // Statement date
+	DueDate string  `json:"dueDate"`
+	Opening float64 `json:"opening"` // Owed at the start of the cycle
+	Charges float64 `json:"charges"` // Spends in the cycle
+	Credits float64 `json:"credits"` // Payments and refunds in the cycle
+	Closing float64 `json:"closing"` // Statement amount
+	Paid    float64 `json:"paid"`    // Paid towards it since the statement date
+	Due     float64 `json:"due"`     // Still to pay
+	Overdue bool    `json:"overdue"`
+}
+
+// CardStatus is a credit card's billing position on a date
+type CardStatus struct {
+	Summary
+	CreditLimit       float64     `json:"creditLimit"`
+	Unbilled          float64     `json:"unbilled"` // Spends since the last statement
+	NextStatementDate string      `json:"nextStatementDate"`
+	LastStatement     *Statement  `json:"lastStatement"`
+	Statements        []Statement `json:"statements"`
+}
+
+// statementDate is the card's statement date in a month, clamped to the
+// month's last day
+func statementDate(day int, year int, month time.Month) time.Time {
+	if last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// nextStatement is the first statement date after t
+func nextStatement(day int, t time.Time) time.Time {
+	d := statementDate(day, t.Year(), t.Month())
+	if !d.After(t) {
+		d = statementDate(day, t.Year(), t.Month()+1)
+	}
+	return d
+}
+
+// Statements builds card a's statements up to asOf from its ledger. The
+// first cycle starts after the opening date, or with the first entry when
+// there is none; the opening balance is what the first cycle starts owing.
+func Statements(a models.Account, entries []Entry, asOf time.Time) CardStatus {
+	day := asOf.Format(dateLayout)
+	st := CardStatus{
+		Summary:     Summarise(a, entries, day),
+		CreditLimit: a.CreditLimit,
+		Statements:  []Statement{},
+	}
+
+	// The day before the first cycle
+	var start time.Time
+	for _, e := range entries {
+		if e.Kind == EntryOpening {
+			continue
+		}
+		t, _ := time.Parse(dateLayout, e.Date)
+		start = t.AddDate(0, 0, -1)
+		break
+	}
+	if opened, err := time.Parse(dateLayout, a.OpeningDate); err == nil && (start.IsZero() || opened.Before(start)) {
+		start = opened
+	}
+	if start.IsZero() {
+		start = asOf
+	}
+
+	prev := start
+	for {
+		end := nextStatement(a.StatementDay, prev)
+		from, to := prev.Format(dateLayout), end.Format(dateLayout)
+		s := Statement{
+			From:    prev.AddDate(0, 0, 1).Format(dateLayout),
+			To:      to,
+			DueDate: end.AddDate(0, 0, a.DueDays).Format(dateLayout),
+			Opening: -BalanceOn(entries, from),
+		}
+		for _, e := range entries {
+			if e.Date <= from || e.Date > to || e.Date > day {
+				continue
+			}
+			if e.Amount < 0 {
+				s.Charges -= e.Amount
+			} else {
+				s.Credits += e.Amount
+			}
+		}
+		if end.After(asOf) {
+			st.Unbilled = calc.Round2(s.Charges)
+			st.NextStatementDate = to
+			break
+		}
+		s.Charges = calc.Round2(s.Charges)
+		s.Credits = calc.Round2(s.Credits)
+		s.Closing = calc.Round2(s.Opening + s.Charges - s.Credits)
+		st.Statements = append(st.Statements, s)
+		prev = end
+	}
+
+	// Payments made after a statement date, up to the next one, count
+	// towards that statement
+	for i := range st.Statements {
+		s := &st.Statements[i]
+		if s.Closing <= 0 {
+			continue
+		}
+		until := day
+		if i+1 < len(st.Statements) {
+			until = st.Statements[i+1].To
+		}
+		var paid float64
+		for _, e := range entries {
+			if e.Date > s.To && e.Date <= until && e.Amount > 0 {
+				paid += e.Amount
+			}
+		}
+		s.Paid = calc.Round2(math.Min(paid, s.Closing))
+		s.Due = calc.Round2(s.Closing - s.Paid)
+		s.Overdue = s.Due > 0 && s.DueDate < day
+	}
+	if n := len(st.Statements); n > 0 {
+		st.LastStatement = &st.Statements[n-1]
+	}
+	return st
+}
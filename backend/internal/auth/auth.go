@@ -0,0 +1,348 @@
+// Package auth checks who is calling the API. Users log in with a password,
+// kept only as a bcrypt hash, and get a random session token back both as a
+// cookie and in the response, for clients that send it as
+// "Authorization: Bearer <token>". Scripts use API tokens instead, which
+// are limited to their scopes (see tokens.go). Only the SHA-256 hash of a
+// token is stored.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/storage"
+)
+
+// CookieName is the cookie carrying the session token
+const CookieName = "session"
+
+// DefaultSessionTTL is how long a login lasts unless configured otherwise
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+// Passwords are at least MinPasswordLength characters; bcrypt reads no more
+// than MaxPasswordLength bytes
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+var (
+	// ErrNoCredentials is returned for requests without a session token
+	ErrNoCredentials = errors.New("login required")
+	// ErrInvalidSession is returned for unknown or expired sessions
+	ErrInvalidSession = errors.New("session is invalid or has expired")
+	// ErrInvalidToken is returned for unknown, expired or revoked API tokens
+	ErrInvalidToken = errors.New("API token is invalid, expired or revoked")
+	// ErrInvalidLogin is returned for a wrong username or password. It does
+	// not say which.
+	ErrInvalidLogin = errors.New("invalid username or password")
+	// ErrSetupDone is returned for a setup once there are users
+	ErrSetupDone = errors.New("setup is already done; log in instead")
+	// ErrInvalidSetupCode is returned for a setup without the code printed
+	// at startup
+	ErrInvalidSetupCode = errors.New("setup code is wrong; it is printed when the server starts")
+)
+
+// ValidatePassword checks a new password is long enough for bcrypt to be
+// worth it, and short enough for bcrypt to read all of it
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes", MaxPasswordLength)
+	}
+	return nil
+}
+
+// HashPassword validates a password and returns its bcrypt hash
+func HashPassword(password string) (string, error) {
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches a bcrypt hash
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NewToken returns a random token and the hash to store for it. It is in
+// hex, so a session token never starts with TokenPrefix.
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken is the SHA-256 hash of a token, in hex. Tokens are random, so
+// a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestToken is the token a request carries as a bearer token or, failing
+// that, in the session cookie
+func RequestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if scheme, token, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if cookie, err := r.Cookie(CookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// SetCookie sends the session token as an HTTP-only cookie the browser
+// keeps until the session expires
+func SetCookie(w http.ResponseWriter, r *http.Request, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// ClearCookie tells the browser to drop the session cookie
+func ClearCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// Authenticator logs users in and out and finds the user behind a request
+type Authenticator struct {
+	mu        sync.Mutex
+	store     storage.Storage
+	ttl       time.Duration
+	now       func() time.Time
+	setupCode string
+}
+
+// New creates an authenticator whose sessions last ttl (DefaultSessionTTL
+// when 0)
+func New(store storage.Storage, ttl time.Duration) *Authenticator {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &Authenticator{store: store, ttl: ttl, now: time.Now}
+}
+
+// dummyHash is checked against when the username is unknown, so a failed
+// login takes as long whether or not the user exists
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("finance-tracker"), bcrypt.DefaultCost)
+	return hash
+})
+
+// SetupCode returns the one-time code creating the first user needs,
+// making one if there is none yet. cmd/server prints it at startup while
+// there are no users, so only whoever runs the server can claim the data.
+func (a *Authenticator) SetupCode() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.setupCode == "" {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		a.setupCode = hex.EncodeToString(b)
+	}
+	return a.setupCode, nil
+}
+
+// Setup runs create, which adds the first user, if code is the setup code
+// and there are still no users. The code is used up once create succeeds.
+// Setups are run one at a time, so two cannot both add a first user.
+func (a *Authenticator) Setup(code string, create func() error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.store.GetUsers()) > 0 {
+		return ErrSetupDone
+	}
+	if a.setupCode == "" || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(code)), []byte(a.setupCode)) != 1 {
+		return ErrInvalidSetupCode
+	}
+	if err := create(); err != nil {
+		return err
+	}
+	a.setupCode = ""
+	return nil
+}
+
+// Login checks a username (ignoring case) and password and starts a session
+func (a *Authenticator) Login(username, password string) (models.User, models.Session, string, error) {
+	var user models.User
+	found := false
+	for _, u := range a.store.GetUsers() {
+		if strings.EqualFold(u.Username, username) {
+			user, found = u, true
+			break
+		}
+	}
+	if !found {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return models.User{}, models.Session{}, "", ErrInvalidLogin
+	}
+	if !CheckPassword(user.PasswordHash, password) || user.Disabled {
+		return models.User{}, models.Session{}, "", ErrInvalidLogin
+	}
+	sess, token, err := a.StartSession(user)
+	if err != nil {
+		return models.User{}, models.Session{}, "", err
+	}
+	return user, sess, token, nil
+}
+
+// StartSession records a new session for user and returns it with its
+// token. Expired sessions are cleared out on the way.
+func (a *Authenticator) StartSession(user models.User) (models.Session, string, error) {
+	token, hash, err := NewToken()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	now := a.now()
+	sess := models.Session{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(a.ttl).UTC().Format(time.RFC3339),
+		CreatedAt: now.Format(time.RFC3339),
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Copied, as deleting shifts the stored slice
+	for _, old := range append([]models.Session(nil), a.store.GetSessions()...) {
+		if expired(old.ExpiresAt, now) {
+			if err := a.store.DeleteSession(old.ID); err != nil {
+				return models.Session{}, "", err
+			}
+		}
+	}
+	if err := a.store.AddSession(sess); err != nil {
+		return models.Session{}, "", err
+	}
+	if err := a.store.SaveSessions(); err != nil {
+		return models.Session{}, "", err
+	}
+	return sess, token, nil
+}
+
+// Authenticate finds the active user behind the session or API token the
+// request carries. scopes are the API token's, or nil for a session.
+func (a *Authenticator) Authenticate(r *http.Request) (user models.User, scopes []string, err error) {
+	token := RequestToken(r)
+	if token == "" {
+		return models.User{}, nil, ErrNoCredentials
+	}
+	if strings.HasPrefix(token, TokenPrefix) {
+		return a.authenticateToken(token, r)
+	}
+	sess, found := a.session(token)
+	if !found || expired(sess.ExpiresAt, a.now()) {
+		return models.User{}, nil, ErrInvalidSession
+	}
+	user, found = a.activeUser(sess.UserID)
+	if !found {
+		return models.User{}, nil, ErrInvalidSession
+	}
+	return user, nil, nil
+}
+
+// activeUser finds a user who is not disabled
+func (a *Authenticator) activeUser(id string) (models.User, bool) {
+	for _, u := range a.store.GetUsers() {
+		if u.ID == id && !u.Disabled {
+			return u, true
+		}
+	}
+	return models.User{}, false
+}
+
+// Logout ends the session the request carries
+func (a *Authenticator) Logout(r *http.Request) error {
+	sess, found := a.session(RequestToken(r))
+	if !found {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.store.DeleteSession(sess.ID); err != nil {
+		return err
+	}
+	return a.store.SaveSessions()
+}
+
+// EndSessions logs a user out everywhere, apart from the session the
+// request r carries (when not nil)
+func (a *Authenticator) EndSessions(userID string, r *http.Request) error {
+	keep := ""
+	if r != nil {
+		if sess, found := a.session(RequestToken(r)); found {
+			keep = sess.ID
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, sess := range append([]models.Session(nil), a.store.GetSessions()...) {
+		if sess.UserID == userID && sess.ID != keep {
+			if err := a.store.DeleteSession(sess.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return a.store.SaveSessions()
+}
+
+// session finds the stored session for a token
+func (a *Authenticator) session(token string) (models.Session, bool) {
+	if token == "" {
+		return models.Session{}, false
+	}
+	hash := HashToken(token)
+	for _, sess := range a.store.GetSessions() {
+		if sess.TokenHash == hash {
+			return sess, true
+		}
+	}
+	return models.Session{}, false
+}
+
+func expired(expiresAt string, now time.Time) bool {
+	expires, err := time.Parse(time.RFC3339, expiresAt)
+	return err != nil || !now.Before(expires)
+}
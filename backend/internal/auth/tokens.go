@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finance-tracker/internal/models"
+)
+
+// TokenPrefix starts every API token, which tells them apart from session
+// tokens
+const TokenPrefix = "ft_"
+
+// lastUseInterval is how stale an API token's last use may get before it
+// is written again, so a busy script does not cause a write per request
+const lastUseInterval = time.Minute
+
+// NewAPIToken creates an API token for user and returns it with the token
+// itself, which is not stored. The caller adds it to the store.
+func NewAPIToken(user models.User, name string, scopes []string, expiresAt string) (models.APIToken, string, error) {
+	secret, _, err := NewToken()
+	if err != nil {
+		return models.APIToken{}, "", err
+	}
+	token := TokenPrefix + secret
+	tok := models.APIToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    token[:len(TokenPrefix)+6],
+		TokenHash: HashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	return tok, token, nil
+}
+
+// authenticateToken finds the active user behind an API token, and notes
+// when and from where it was used
+func (a *Authenticator) authenticateToken(token string, r *http.Request) (models.User, []string, error) {
+	hash := HashToken(token)
+	var tok models.APIToken
+	found := false
+	for _, t := range a.store.GetAPITokens() {
+		if t.TokenHash == hash {
+			tok, found = t, true
+			break
+		}
+	}
+	now := a.now()
+	if !found || (tok.ExpiresAt != "" && expired(tok.ExpiresAt, now)) {
+		return models.User{}, nil, ErrInvalidToken
+	}
+	user, found := a.activeUser(tok.UserID)
+	if !found {
+		return models.User{}, nil, ErrInvalidToken
+	}
+	a.recordUse(tok, clientIP(r), now)
+	return user, tok.Scopes, nil
+}
+
+// recordUse saves when and from where a token was used. Failing to is
+// logged but does not fail the request.
+func (a *Authenticator) recordUse(tok models.APIToken, ip string, now time.Time) {
+	if last, err := time.Parse(time.RFC3339, tok.LastUsedAt); err == nil &&
+		tok.LastUsedIP == ip && now.Sub(last) < lastUseInterval {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Read again, so a token revoked or changed meanwhile is not written back
+	found := false
+	for _, t := range a.store.GetAPITokens() {
+		if t.ID == tok.ID {
+			tok, found = t, true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+	tok.LastUsedAt = now.Format(time.RFC3339)
+	tok.LastUsedIP = ip
+	if err := a.store.UpdateAPIToken(tok.ID, tok); err != nil {
+		log.Printf("Warning: Failed to record API token use: %v", err)
+		return
+	}
+	if err := a.store.SaveAPITokens(); err != nil {
+		log.Printf("Warning: Failed to save API tokens: %v", err)
+	}
+}
+
+// clientIP is the address a request came from. Forwarding headers are not
+// trusted, as the server is reached directly.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
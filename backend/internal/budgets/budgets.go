@@ -0,0 +1,186 @@
+// Package budgets evaluates spending against budget limits.
+package budgets
+
+import (
+	"strings"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+// DefaultAlertThreshold is the percent of a limit that counts as "near"
+// when a budget does not set its own
+const DefaultAlertThreshold = 80
+
+// Budget states
+const (
+	StateOK   = "ok"
+	StateNear = "near"
+	StateOver = "over"
+)
+
+const dateLayout = "2006-01-02"
+
+// Status is a budget's position in the period containing the evaluation date
+type Status struct {
+	Budget        models.Budget `json:"budget"`
+	PeriodStart   string        `json:"periodStart"`
+	PeriodEnd     string        `json:"periodEnd"`
+	Spent         float64       `json:"spent"`
+	Carried       float64       `json:"carried"` // Rollover from earlier periods (negative if overspent)
+	Limit         float64       `json:"limit"`   // Budget limit plus Carried
+	Remaining     float64       `json:"remaining"`
+	PercentUsed   float64       `json:"percentUsed"`
+	Projected     float64       `json:"projected"` // Period-end spend at the current daily rate
+	NearLimit     bool          `json:"nearLimit"`
+	OverLimit     bool          `json:"overLimit"`
+	ProjectedOver bool          `json:"projectedOver"`
+	State         string        `json:"state"` // "ok", "near" or "over"
+}
+
+// PeriodBounds returns the first and last day of the period containing t.
+// Weeks start on Monday.
+func PeriodBounds(period string, t time.Time) (time.Time, time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case models.PeriodWeekly:
+		offset := (int(day.Weekday()) + 6) % 7
+		start := day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 6)
+	case models.PeriodYearly:
+		start := time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	default:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	}
+}
+
+// Applies reports whether exp counts against b
+func Applies(b models.Budget, exp models.Expense) bool {
+	if b.Member != "" && exp.AddedBy != b.Member {
+		return false
+	}
+	for _, c := range b.Categories {
+		if strings.EqualFold(c, exp.Category) {
+			return true
+		}
+	}
+	return false
+}
+
+// spentBetween sums the expenses counted against b from start to end
+// inclusive; of a split expense, only the lines b applies to count
+func spentBetween(b models.Budget, expenses []models.Expense, start, end time.Time) float64 {
+	from, to := start.Format(dateLayout), end.Format(dateLayout)
+	var total float64
+	for _, exp := range expenses {
+		if len(exp.Date) < len(dateLayout) {
+			continue
+		}
+		day := exp.Date[:len(dateLayout)]
+		if day < from || day > to {
+			continue
+		}
+		for _, line := range exp.Lines() {
+			if Applies(b, line) {
+				total += line.Amount
+			}
+		}
+	}
+	return total
+}
+
+// rolloverStart is the first day rollover counts from: StartDate if set,
+// otherwise the day the budget was created
+func rolloverStart(b models.Budget) (time.Time, bool) {
+	for _, s := range []string{b.StartDate, b.CreatedAt} {
+		if len(s) >= len(dateLayout) {
+			if t, err := time.Parse(dateLayout, s[:len(dateLayout)]); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// DataStart returns the earliest expense date Evaluate needs for b at asOf
+func DataStart(b models.Budget, asOf time.Time) time.Time {
+	start, _ := PeriodBounds(b.Period, asOf)
+	if b.Rollover {
+		if first, ok := rolloverStart(b); ok {
+			if fs, _ := PeriodBounds(b.Period, first); fs.Before(start) {
+				return fs
+			}
+		}
+	}
+	return start
+}
+
+// Evaluate computes b's status for the period containing asOf
+func Evaluate(b models.Budget, expenses []models.Expense, asOf time.Time) Status {
+	start, end := PeriodBounds(b.Period, asOf)
+
+	var carried float64
+	if b.Rollover {
+		if first, ok := rolloverStart(b); ok {
+			// Each finished period passes on whatever it left unspent
+			for ps, pe := PeriodBounds(b.Period, first); ps.Before(start); ps, pe = PeriodBounds(b.Period, pe.AddDate(0, 0, 1)) {
+				carried += b.Limit - spentBetween(b, expenses, ps, pe)
+			}
+		}
+	}
+
+	spent := spentBetween(b, expenses, start, end)
+	limit := b.Limit + carried
+
+	projected := spent
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(end) {
+		elapsed := day.Sub(start).Hours()/24 + 1
+		total := end.Sub(start).Hours()/24 + 1
+		projected = spent / elapsed * total
+	}
+
+	threshold := b.AlertThreshold
+	if threshold == 0 {
+		threshold = DefaultAlertThreshold
+	}
+
+	st := Status{
+		Budget:      b,
+		PeriodStart: start.Format(dateLayout),
+		PeriodEnd:   end.Format(dateLayout),
+		Spent:       calc.Round2(spent),
+		Carried:     calc.Round2(carried),
+		Limit:       calc.Round2(limit),
+		Remaining:   calc.Round2(limit - spent),
+		Projected:   calc.Round2(projected),
+	}
+	if limit > 0 {
+		st.PercentUsed = calc.Round2(spent / limit * 100)
+	}
+	st.OverLimit = spent > limit
+	st.NearLimit = !st.OverLimit && (limit <= 0 || st.PercentUsed >= threshold)
+	st.ProjectedOver = projected > limit
+
+	switch {
+	case st.OverLimit:
+		st.State = StateOver
+	case st.NearLimit || st.ProjectedOver:
+		st.State = StateNear
+	default:
+		st.State = StateOK
+	}
+	return st
+}
+
+// EvaluateAll computes the status of every budget
+func EvaluateAll(list []models.Budget, expenses []models.Expense, asOf time.Time) []Status {
+	statuses := make([]Status, 0, len(list))
+	for _, b := range list {
+		statuses = append(statuses, Evaluate(b, expenses, asOf))
+	}
+	return statuses
+}
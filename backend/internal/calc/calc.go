@@ -0,0 +1,43 @@
+// Package calc is the money and date arithmetic the other packages share:
+// rounding to paise, and stepping through months that keep their day of
+// month.
+package calc
+
+import (
+	"math"
+	"time"
+)
+
+// DateLayout is how dates are stored
+const DateLayout = "2006-01-02"
+
+// Round2 rounds an amount to paise
+func Round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// AddMonths moves t by n months (back, when n is negative), keeping its day
+// or clamping it to the end of shorter months: 31 Jan plus one month is
+// 28 or 29 Feb. The result is a UTC date.
+func AddMonths(t time.Time, n int) time.Time {
+	months := int(t.Month()) - 1 + n
+	year := t.Year() + months/12
+	if months %= 12; months < 0 {
+		months += 12
+		year--
+	}
+	month := time.Month(months + 1)
+	day := t.Day()
+	if last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// DateOnly trims a date or RFC 3339 timestamp to YYYY-MM-DD
+func DateOnly(s string) string {
+	if len(s) > len(DateLayout) {
+		return s[:len(DateLayout)]
+	}
+	return s
+}
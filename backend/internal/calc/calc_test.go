@@ -0,0 +1,59 @@
+package calc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		from string
+		n    int
+		want string
+	}{
+		{"2024-01-15", 1, "2024-02-15"},
+		{"2024-01-31", 1, "2024-02-29"}, // Leap year
+		{"2025-01-31", 1, "2025-02-28"},
+		{"2024-03-31", 1, "2024-04-30"},
+		{"2024-01-31", 2, "2024-03-31"}, // The day comes back after a short month
+		{"2024-11-30", 3, "2025-02-28"},
+		{"2024-12-10", 12, "2025-12-10"},
+		{"2024-02-29", 12, "2025-02-28"},
+		{"2024-05-31", 0, "2024-05-31"},
+		{"2024-03-31", -1, "2024-02-29"},
+		{"2024-01-15", -13, "2022-12-15"},
+	}
+	for _, tt := range tests {
+		from, _ := time.Parse(DateLayout, tt.from)
+		if got := AddMonths(from, tt.n).Format(DateLayout); got != tt.want {
+			t.Errorf("AddMonths(%s, %d) = %s, want %s", tt.from, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRound2(t *testing.T) {
+	tests := []struct{ in, want float64 }{
+		{1.005000001, 1.01},
+		{2.344, 2.34},
+		{-2.345000001, -2.35},
+		{1234.5, 1234.5},
+	}
+	for _, tt := range tests {
+		if got := Round2(tt.in); got != tt.want {
+			t.Errorf("Round2(%g) = %g, want %g", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDateOnly(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"2024-04-01", "2024-04-01"},
+		{"2024-04-01T10:30:00+05:30", "2024-04-01"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := DateOnly(tt.in); got != tt.want {
+			t.Errorf("DateOnly(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
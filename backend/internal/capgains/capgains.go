@@ -0,0 +1,279 @@
+// Package capgains builds the Indian capital gains report (STCG/LTCG) for an
+// April–March financial year from the FIFO lots matched to each sale.
+package capgains
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/portfolio"
+)
+
+const dateLayout = "2006-01-02"
+
+// FinancialYear is an April–March year, e.g. 2024-25
+type FinancialYear struct {
+	Start int // Calendar year the FY starts in
+}
+
+// ParseFY reads "2024-25", "2024-2025" or "2024"; empty means the year
+// containing now
+func ParseFY(s string, now time.Time) (FinancialYear, error) {
+	if s == "" {
+		return FinancialYear{Start: FYStart(now)}, nil
+	}
+	head, tail, hasTail := strings.Cut(s, "-")
+	start, err := strconv.Atoi(head)
+	if err != nil || len(head) != 4 {
+		return FinancialYear{}, fmt.Errorf("financial year must look like 2024-25, got %q", s)
+	}
+	if hasTail {
+		end, err := strconv.Atoi(tail)
+		if err != nil || (end != (start+1)%100 && end != start+1) {
+			return FinancialYear{}, fmt.Errorf("financial year must look like 2024-25, got %q", s)
+		}
+	}
+	return FinancialYear{Start: start}, nil
+}
+
+// String formats the year as 2024-25
+func (fy FinancialYear) String() string {
+	return fmt.Sprintf("%d-%02d", fy.Start, (fy.Start+1)%100)
+}
+
+// From is 1 April
+func (fy FinancialYear) From() time.Time { return date(fy.Start, time.April, 1) }
+
+// To is 31 March
+func (fy FinancialYear) To() time.Time { return date(fy.Start+1, time.March, 31) }
+
+// Entry is one lot (or part of one) sold during the year
+type Entry struct {
+	InvestmentID  string   `json:"investmentId"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	AssetClass    string   `json:"assetClass"`
+	SchemeCode    string   `json:"schemeCode,omitempty"`
+	TxnID         string   `json:"txnId"`
+	BuyDate       string   `json:"buyDate"`
+	SellDate      string   `json:"sellDate"`
+	HoldingDays   int      `json:"holdingDays"`
+	Units         float64  `json:"units"`
+	Proceeds      float64  `json:"proceeds"`
+	Cost          float64  `json:"cost"`         // Actual cost
+	AdjustedCost  float64  `json:"adjustedCost"` // After grandfathering or indexation
+	FMV2018       float64  `json:"fmv2018,omitempty"`
+	Grandfathered bool     `json:"grandfathered"`
+	FMVMissing    bool     `json:"fmvMissing,omitempty"` // Bought before Feb 2018 but no FMV known
+	Indexed       bool     `json:"indexed"`
+	CIIMissing    bool     `json:"ciiMissing,omitempty"` // Indexation due but no CII known for the year of sale
+	Gain          float64  `json:"gain"`
+	Term          string   `json:"term"`
+	Section       string   `json:"section"`
+	Rate          *float64 `json:"rate"` // Percent; nil means slab rate
+}
+
+// SectionTotal sums the entries taxed under one section at one rate
+type SectionTotal struct {
+	Section string   `json:"section"`
+	Term    string   `json:"term"`
+	Rate    *float64 `json:"rate"`
+	Gain    float64  `json:"gain"`
+}
+
+// Report is the capital gains of one financial year
+type Report struct {
+	FinancialYear string         `json:"financialYear"`
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+	ShortTerm     float64        `json:"shortTerm"`
+	LongTerm      float64        `json:"longTerm"`
+	Sections      []SectionTotal `json:"sections"`
+	LTCG112A      float64        `json:"ltcg112A"`      // Net Sec 112A gain
+	Exemption112A float64        `json:"exemption112A"` // Yearly threshold
+	Exempt112A    float64        `json:"exempt112A"`    // Part of LTCG112A covered by the threshold
+	Taxable112A   float64        `json:"taxable112A"`
+	Entries       []Entry        `json:"entries"`
+	Warnings      []string       `json:"warnings,omitempty"` // Holdings left out, gains left unindexed
+}
+
+// Build computes the report for fy. prices supplies the 31 Jan 2018 NAV for
+// grandfathering when a holding has no GrandfatheredFMV. Holdings whose
+// transactions cannot be replayed are left out with a warning, as are
+// indexed gains for a year whose CII is not yet in the table.
+func Build(investments []models.Investment, prices portfolio.Prices, fy FinancialYear) Report {
+	report := Report{
+		FinancialYear: fy.String(),
+		From:          fy.From().Format(dateLayout),
+		To:            fy.To().Format(dateLayout),
+		Exemption112A: Exemption112A(fy.Start),
+		Sections:      []SectionTotal{},
+		Entries:       []Entry{},
+	}
+	for _, inv := range investments {
+		class := ClassOf(inv)
+		if class == models.AssetNone {
+			continue
+		}
+		holding, err := portfolio.Build(inv.Transactions)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", inv.Name, err))
+			continue
+		}
+		for _, d := range holding.Disposals {
+			if d.SellDate < report.From || d.SellDate > report.To {
+				continue
+			}
+			entry, err := classify(inv, class, d, prices[inv.SchemeCode])
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", inv.Name, err))
+				continue
+			}
+			if entry.CIIMissing {
+				sell, _ := time.Parse(dateLayout, d.SellDate)
+				report.Warnings = append(report.Warnings, fmt.Sprintf(
+					"%s: no cost inflation index is known for FY %s yet, so the gain on the sale of %s is not indexed",
+					inv.Name, FinancialYear{Start: FYStart(sell)}, d.SellDate))
+			}
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return report.Entries[i].SellDate < report.Entries[j].SellDate
+	})
+	report.total()
+	return report
+}
+
+// classify applies the holding-period, grandfathering and indexation rules
+// to one matched lot
+func classify(inv models.Investment, class string, d portfolio.Disposal, prices []models.PricePoint) (Entry, error) {
+	buy, err := time.Parse(dateLayout, d.BuyDate)
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid purchase date %q", d.BuyDate)
+	}
+	sell, err := time.Parse(dateLayout, d.SellDate)
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid sale date %q", d.SellDate)
+	}
+
+	e := Entry{
+		InvestmentID: inv.ID,
+		Name:         inv.Name,
+		Type:         inv.Type,
+		AssetClass:   class,
+		SchemeCode:   inv.SchemeCode,
+		TxnID:        d.TxnID,
+		BuyDate:      d.BuyDate,
+		SellDate:     d.SellDate,
+		HoldingDays:  int(sell.Sub(buy).Hours() / 24),
+		Units:        d.Units,
+		Proceeds:     calc.Round2(d.Proceeds),
+		Cost:         calc.Round2(d.Cost),
+		AdjustedCost: calc.Round2(d.Cost),
+	}
+
+	r := ruleFor(class, buy, sell)
+	e.Term = r.term
+	if e.Term == "" {
+		e.Term = ShortTerm
+		if isLongTerm(buy, sell, r.months) {
+			e.Term = LongTerm
+		}
+	}
+
+	if e.Term == ShortTerm {
+		e.Section, e.Rate = r.stSec, r.stRate
+	} else {
+		e.Section, e.Rate = r.ltSec, r.ltRate
+		switch {
+		case e.Section == Sec112A && buy.Before(grandfatherCutoff):
+			grandfather(&e, inv, prices)
+		case r.indexed:
+			if cost, ok := indexCost(d.Cost, buy, sell); ok {
+				e.AdjustedCost, e.Indexed = calc.Round2(cost), true
+			} else {
+				e.CIIMissing = true
+			}
+		}
+	}
+
+	e.Gain = calc.Round2(e.Proceeds - e.AdjustedCost)
+	return e, nil
+}
+
+// grandfather raises the cost of pre-February-2018 equity to its value on
+// 31 Jan 2018, capped at the sale value: cost = max(cost, min(FMV, proceeds))
+func grandfather(e *Entry, inv models.Investment, prices []models.PricePoint) {
+	perUnit := inv.GrandfatheredFMV
+	if perUnit == 0 {
+		// A NAV from the last week of January counts as the 31 Jan value
+		if p, ok := portfolio.PriceOn(prices, grandfatherFMVDate); ok && priceDateOK(prices, grandfatherFMVDate) {
+			perUnit = p
+		}
+	}
+	if perUnit == 0 || e.Units == 0 {
+		e.FMVMissing = true
+		return
+	}
+
+	e.FMV2018 = calc.Round2(perUnit * e.Units)
+	cost := math.Max(e.Cost, math.Min(e.FMV2018, e.Proceeds))
+	if cost > e.Cost {
+		e.AdjustedCost = cost
+		e.Grandfathered = true
+	}
+}
+
+// priceDateOK reports whether the last price on or before day is recent
+// enough to stand for it
+func priceDateOK(prices []models.PricePoint, day string) bool {
+	i := sort.Search(len(prices), func(i int) bool { return prices[i].Date > day })
+	if i == 0 {
+		return false
+	}
+	return prices[i-1].Date >= "2018-01-24"
+}
+
+// total fills in the term and section totals and the 112A exemption
+func (r *Report) total() {
+	index := map[string]int{}
+	for _, e := range r.Entries {
+		if e.Term == LongTerm {
+			r.LongTerm += e.Gain
+		} else {
+			r.ShortTerm += e.Gain
+		}
+		if e.Section == Sec112A {
+			r.LTCG112A += e.Gain
+		}
+
+		key := e.Section + "|" + e.Term + "|slab"
+		if e.Rate != nil {
+			key = fmt.Sprintf("%s|%s|%g", e.Section, e.Term, *e.Rate)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(r.Sections)
+			index[key] = i
+			r.Sections = append(r.Sections, SectionTotal{Section: e.Section, Term: e.Term, Rate: e.Rate})
+		}
+		r.Sections[i].Gain += e.Gain
+	}
+
+	for i := range r.Sections {
+		r.Sections[i].Gain = calc.Round2(r.Sections[i].Gain)
+	}
+	r.ShortTerm = calc.Round2(r.ShortTerm)
+	r.LongTerm = calc.Round2(r.LongTerm)
+	r.LTCG112A = calc.Round2(r.LTCG112A)
+	r.Exempt112A = math.Min(r.Exemption112A, math.Max(0, r.LTCG112A))
+	r.Taxable112A = calc.Round2(math.Max(0, r.LTCG112A-r.Exempt112A))
+}
@@ -0,0 +1,142 @@
+package capgains
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/portfolio"
+)
+
+func TestGrandfather(t *testing.T) {
+	near := []models.PricePoint{{Date: "2018-01-24", Price: 80}, {Date: "2018-01-29", Price: 130}, {Date: "2018-02-01", Price: 200}}
+	stale := []models.PricePoint{{Date: "2018-01-10", Price: 130}}
+
+	tests := []struct {
+		name          string
+		fmv           float64 // Per unit, on the investment
+		prices        []models.PricePoint
+		proceeds      float64
+		adjusted      float64
+		grandfathered bool
+		missing       bool
+	}{
+		{"FMV between cost and proceeds", 150, nil, 2000, 1500, true, false},
+		{"FMV above proceeds is capped at them", 300, nil, 2000, 2000, true, false},
+		{"FMV below cost keeps the cost", 80, nil, 2000, 1000, false, false},
+		{"sold at a loss keeps the cost", 150, nil, 900, 1000, false, false},
+		{"FMV from the last January NAV", 0, near, 2000, 1300, true, false},
+		{"the stored FMV wins over NAVs", 110, near, 2000, 1100, true, false},
+		{"a NAV from before the last week is not used", 0, stale, 2000, 1000, false, true},
+		{"no FMV", 0, nil, 2000, 1000, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Entry{Units: 10, Cost: 1000, AdjustedCost: 1000, Proceeds: tt.proceeds}
+			grandfather(&e, models.Investment{GrandfatheredFMV: tt.fmv}, tt.prices)
+			if e.AdjustedCost != tt.adjusted || e.Grandfathered != tt.grandfathered || e.FMVMissing != tt.missing {
+				t.Errorf("grandfather() = adjusted %g, grandfathered %v, missing %v, want %g, %v, %v",
+					e.AdjustedCost, e.Grandfathered, e.FMVMissing, tt.adjusted, tt.grandfathered, tt.missing)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	txn := func(typ, date string, units, amount float64) models.InvestmentTransaction {
+		return models.InvestmentTransaction{ID: typ + date, Type: typ, Date: date, Units: units, Amount: amount}
+	}
+	investments := []models.Investment{
+		{ID: "mf", Name: "Flexi Cap", Type: "Mutual Fund", SchemeCode: "118955", Transactions: []models.InvestmentTransaction{
+			txn(models.TxnBuy, "2017-06-01", 10, 1000), // Grandfathered
+			txn(models.TxnSIP, "2023-01-10", 10, 2000),
+			txn(models.TxnSell, "2024-06-01", 10, 2500),  // Long term at 10%
+			txn(models.TxnRedeem, "2024-08-01", 5, 1500), // Long term at 12.5%
+		}},
+		{ID: "st", Name: "Infosys", Type: "Stocks", Transactions: []models.InvestmentTransaction{
+			txn(models.TxnBuy, "2024-05-01", 20, 10000),
+			txn(models.TxnSell, "2024-09-01", 10, 4000),
+			txn(models.TxnSell, "2025-04-02", 10, 6000), // Next year
+		}},
+		{ID: "debt", Name: "Liquid Fund", Type: "Mutual Fund", AssetClass: models.AssetDebtFund, Transactions: []models.InvestmentTransaction{
+			txn(models.TxnBuy, "2023-05-01", 100, 10000),
+			txn(models.TxnRedeem, "2024-10-01", 100, 10800), // Sec 50AA
+		}},
+		{ID: "gold", Name: "Gold coins", Type: "Gold", Transactions: []models.InvestmentTransaction{
+			txn(models.TxnBuy, "2020-06-01", 0, 50000),
+			txn(models.TxnSell, "2024-06-15", 0, 70000), // Indexed, all of it
+		}},
+		{ID: "fd", Name: "SBI FD", Type: "FD", Transactions: []models.InvestmentTransaction{
+			txn(models.TxnBuy, "2023-06-01", 0, 100000),
+			txn(models.TxnRedeem, "2024-06-01", 0, 100000),
+		}},
+		{ID: "bad", Name: "Broken", Type: "Stocks", Transactions: []models.InvestmentTransaction{
+			txn(models.TxnBuy, "2024-05-01", 5, 500),
+			txn(models.TxnSell, "2024-06-01", 10, 1200),
+		}},
+	}
+	prices := portfolio.Prices{"118955": {{Date: "2018-01-31", Price: 130}}}
+
+	report := Build(investments, prices, FinancialYear{Start: 2024})
+
+	type line struct {
+		id, sell, term, section, rate string
+		adjusted, gain                float64
+		grandfathered, indexed        bool
+	}
+	var got []line
+	for _, e := range report.Entries {
+		got = append(got, line{e.InvestmentID, e.SellDate, e.Term, e.Section, rateString(e.Rate), e.AdjustedCost, e.Gain, e.Grandfathered, e.Indexed})
+	}
+	want := []line{
+		{"mf", "2024-06-01", LongTerm, Sec112A, "10", 1300, 1200, true, false},
+		{"gold", "2024-06-15", LongTerm, Sec112, "20", 60299, 9701, false, true},
+		{"mf", "2024-08-01", LongTerm, Sec112A, "12.5", 1000, 500, false, false},
+		{"st", "2024-09-01", ShortTerm, Sec111A, "20", 5000, -1000, false, false},
+		{"debt", "2024-10-01", ShortTerm, Sec50AA, "slab", 10000, 800, false, false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build() entries =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if report.FinancialYear != "2024-25" || report.From != "2024-04-01" || report.To != "2025-03-31" {
+		t.Errorf("Build() year = %s %s..%s, want 2024-25 2024-04-01..2025-03-31", report.FinancialYear, report.From, report.To)
+	}
+	if report.ShortTerm != -200 || report.LongTerm != 11401 {
+		t.Errorf("Build() short, long = %g, %g, want -200, 11401", report.ShortTerm, report.LongTerm)
+	}
+	if report.LTCG112A != 1700 || report.Exemption112A != 125000 || report.Exempt112A != 1700 || report.Taxable112A != 0 {
+		t.Errorf("Build() 112A = %g, exemption %g, exempt %g, taxable %g, want 1700, 125000, 1700, 0",
+			report.LTCG112A, report.Exemption112A, report.Exempt112A, report.Taxable112A)
+	}
+
+	var sections []string
+	for _, s := range report.Sections {
+		sections = append(sections, s.Section+" "+s.Term+" "+rateString(s.Rate))
+	}
+	wantSections := []string{"112A long 10", "112 long 20", "112A long 12.5", "111A short 20", "50AA short slab"}
+	if !reflect.DeepEqual(sections, wantSections) {
+		t.Errorf("Build() sections = %v, want %v", sections, wantSections)
+	}
+
+	if len(report.Warnings) != 1 || !strings.HasPrefix(report.Warnings[0], "Broken: ") {
+		t.Errorf("Build() warnings = %v, want one for Broken", report.Warnings)
+	}
+}
+
+func TestBuildTaxable112A(t *testing.T) {
+	investments := []models.Investment{
+		{ID: "mf", Name: "Index Fund", Type: "Mutual Fund", Transactions: []models.InvestmentTransaction{
+			{ID: "b", Type: models.TxnBuy, Date: "2021-05-01", Units: 1000, Amount: 100000},
+			{ID: "s", Type: models.TxnSell, Date: "2023-11-01", Units: 1000, Amount: 250000},
+		}},
+	}
+
+	report := Build(investments, nil, FinancialYear{Start: 2023})
+	if report.LTCG112A != 150000 || report.Exemption112A != 100000 || report.Exempt112A != 100000 || report.Taxable112A != 50000 {
+		t.Errorf("Build() 112A = %g, exemption %g, exempt %g, taxable %g, want 150000, 100000, 100000, 50000",
+			report.LTCG112A, report.Exemption112A, report.Exempt112A, report.Taxable112A)
+	}
+}
@@ -0,0 +1,75 @@
+package capgains
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvHeader follows the columns of schedule CG / 112A of the ITR forms
+var csvHeader = []string{
+	"Section", "Term", "Asset class", "Name", "Scheme code",
+	"Date of acquisition", "Date of transfer", "Units",
+	"Full value of consideration", "Cost of acquisition",
+	"FMV on 31-01-2018", "Cost after grandfathering/indexation", "Gain",
+	"Rate (%)",
+}
+
+// WriteCSV writes the report entries, one row per matched lot, followed by
+// the section totals and the 112A exemption
+func WriteCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, e := range r.Entries {
+		fmv := ""
+		if e.FMV2018 > 0 {
+			fmv = money(e.FMV2018)
+		}
+		row := []string{
+			e.Section, e.Term, e.AssetClass, e.Name, e.SchemeCode,
+			e.BuyDate, e.SellDate, strconv.FormatFloat(e.Units, 'f', -1, 64),
+			money(e.Proceeds), money(e.Cost),
+			fmv, money(e.AdjustedCost), money(e.Gain),
+			rateString(e.Rate),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	blank := make([]string, len(csvHeader))
+	cw.Write(blank)
+	for _, s := range r.Sections {
+		row := make([]string, len(csvHeader))
+		row[0], row[1] = s.Section, s.Term
+		row[3] = "Total"
+		row[12], row[13] = money(s.Gain), rateString(s.Rate)
+		cw.Write(row)
+	}
+	for _, line := range [][2]string{
+		{"LTCG u/s 112A", money(r.LTCG112A)},
+		{"Exempt u/s 112A", money(r.Exempt112A)},
+		{"Taxable u/s 112A", money(r.Taxable112A)},
+	} {
+		row := make([]string, len(csvHeader))
+		row[0], row[3], row[12] = Sec112A, line[0], line[1]
+		cw.Write(row)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func money(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func rateString(rate *float64) string {
+	if rate == nil {
+		return "slab"
+	}
+	return strconv.FormatFloat(*rate, 'f', -1, 64)
+}
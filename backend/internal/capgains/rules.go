@@ -0,0 +1,153 @@
+package capgains
+
+import (
+	"strings"
+	"time"
+
+	"finance-tracker/internal/models"
+)
+
+// Key dates in the capital gains rules
+var (
+	// Equity bought before this date is grandfathered (Sec 112A)
+	grandfatherCutoff = date(2018, 2, 1)
+	// FMV date used for grandfathering
+	grandfatherFMVDate = "2018-01-31"
+	// Debt funds bought from this date are always short term (Sec 50AA)
+	specifiedFundCutoff = date(2023, 4, 1)
+	// Finance (No. 2) Act 2024: new rates and holding periods for transfers
+	// from this date
+	budget2024 = date(2024, 7, 23)
+)
+
+// Terms
+const (
+	ShortTerm = "short"
+	LongTerm  = "long"
+)
+
+// Sections, as reported in schedule CG / 112A
+const (
+	Sec111A = "111A" // STCG on equity with STT
+	Sec112A = "112A" // LTCG on equity with STT
+	Sec112  = "112"  // Other LTCG
+	Sec50AA = "50AA" // Specified mutual funds, deemed short term
+	SecSlab = "slab" // Other STCG, taxed at slab rates
+)
+
+// rule is how one disposal is treated
+type rule struct {
+	months  int      // Held for more than this many months is long term
+	term    string   // Set when the term is fixed regardless of holding period
+	stRate  *float64 // nil means slab rate
+	ltRate  *float64
+	stSec   string
+	ltSec   string
+	indexed bool // Long-term cost is indexed
+}
+
+func rate(r float64) *float64 { return &r }
+
+// ClassOf returns the investment's asset class, defaulting from its Type
+func ClassOf(inv models.Investment) string {
+	if inv.AssetClass != "" {
+		return inv.AssetClass
+	}
+	switch strings.ToLower(inv.Type) {
+	case "mutual fund":
+		return models.AssetEquityFund
+	case "stocks", "stock", "shares":
+		return models.AssetStock
+	case "gold":
+		return models.AssetGold
+	case "fd", "rd", "ppf", "nps", "chit":
+		return models.AssetNone
+	default:
+		return models.AssetOther
+	}
+}
+
+// ruleFor returns the treatment of a disposal of class bought on buy and
+// sold on sell
+func ruleFor(class string, buy, sell time.Time) rule {
+	after := !sell.Before(budget2024)
+
+	switch class {
+	case models.AssetEquityFund, models.AssetStock:
+		r := rule{months: 12, stSec: Sec111A, ltSec: Sec112A, stRate: rate(15), ltRate: rate(10)}
+		if after {
+			r.stRate, r.ltRate = rate(20), rate(12.5)
+		}
+		return r
+
+	case models.AssetDebtFund:
+		if !buy.Before(specifiedFundCutoff) {
+			return rule{term: ShortTerm, stSec: Sec50AA}
+		}
+		if after {
+			return rule{months: 24, stSec: SecSlab, ltSec: Sec112, ltRate: rate(12.5)}
+		}
+		return rule{months: 36, stSec: SecSlab, ltSec: Sec112, ltRate: rate(20), indexed: true}
+
+	default: // gold and other capital assets
+		if after {
+			return rule{months: 24, stSec: SecSlab, ltSec: Sec112, ltRate: rate(12.5)}
+		}
+		return rule{months: 36, stSec: SecSlab, ltSec: Sec112, ltRate: rate(20), indexed: true}
+	}
+}
+
+// isLongTerm reports whether an asset held from buy to sell for more than
+// months is long term
+func isLongTerm(buy, sell time.Time, months int) bool {
+	return sell.After(buy.AddDate(0, months, 0))
+}
+
+// Exemption112A is the yearly LTCG exemption under Sec 112A for the
+// financial year starting in fyStart
+func Exemption112A(fyStart int) float64 {
+	if fyStart >= 2024 {
+		return 125000
+	}
+	return 100000
+}
+
+// costInflationIndex by financial year start (base year 2001-02). Add each
+// year's index as CBDT notifies it; until then, gains that year are
+// reported unindexed with a warning.
+var costInflationIndex = map[int]float64{
+	2001: 100, 2002: 105, 2003: 109, 2004: 113, 2005: 117, 2006: 122,
+	2007: 129, 2008: 137, 2009: 148, 2010: 167, 2011: 184, 2012: 200,
+	2013: 220, 2014: 240, 2015: 254, 2016: 264, 2017: 272, 2018: 280,
+	2019: 289, 2020: 301, 2021: 317, 2022: 331, 2023: 348, 2024: 363,
+	2025: 376,
+}
+
+// indexCost applies cost inflation indexation from the year of purchase
+// (or 2001-02 for older assets) to the year of sale. ok is false when the
+// index of either year is not known.
+func indexCost(cost float64, buy, sell time.Time) (float64, bool) {
+	from := FYStart(buy)
+	if from < 2001 {
+		from = 2001
+	}
+	ciiBuy, ok1 := costInflationIndex[from]
+	ciiSell, ok2 := costInflationIndex[FYStart(sell)]
+	if !ok1 || !ok2 {
+		return cost, false
+	}
+	return cost * ciiSell / ciiBuy, true
+}
+
+// FYStart returns the calendar year in which t's April–March financial
+// year starts
+func FYStart(t time.Time) int {
+	if t.Month() < time.April {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
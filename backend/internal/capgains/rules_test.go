@@ -0,0 +1,115 @@
+package capgains
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"finance-tracker/internal/models"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestRuleFor(t *testing.T) {
+	tests := []struct {
+		name         string
+		class        string
+		buy, sell    string
+		months       int
+		term         string
+		stSec, ltSec string
+		stRate       string
+		ltRate       string
+		indexed      bool
+	}{
+		{"equity before the budget", models.AssetEquityFund, "2023-01-10", "2024-07-22", 12, "", Sec111A, Sec112A, "15", "10", false},
+		{"equity from the budget", models.AssetEquityFund, "2023-01-10", "2024-07-23", 12, "", Sec111A, Sec112A, "20", "12.5", false},
+		{"stock from the budget", models.AssetStock, "2024-01-10", "2025-02-01", 12, "", Sec111A, Sec112A, "20", "12.5", false},
+		{"debt fund from April 2023", models.AssetDebtFund, "2023-04-01", "2026-05-01", 0, ShortTerm, Sec50AA, "", "slab", "slab", false},
+		{"older debt fund before the budget", models.AssetDebtFund, "2023-03-31", "2024-07-22", 36, "", SecSlab, Sec112, "slab", "20", true},
+		{"older debt fund from the budget", models.AssetDebtFund, "2023-03-31", "2024-07-23", 24, "", SecSlab, Sec112, "slab", "12.5", false},
+		{"gold before the budget", models.AssetGold, "2020-06-01", "2024-06-15", 36, "", SecSlab, Sec112, "slab", "20", true},
+		{"gold from the budget", models.AssetGold, "2020-06-01", "2024-08-01", 24, "", SecSlab, Sec112, "slab", "12.5", false},
+		{"other from the budget", models.AssetOther, "2022-06-01", "2025-01-01", 24, "", SecSlab, Sec112, "slab", "12.5", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ruleFor(tt.class, day(tt.buy), day(tt.sell))
+			if r.months != tt.months || r.term != tt.term || r.stSec != tt.stSec || r.ltSec != tt.ltSec || r.indexed != tt.indexed {
+				t.Errorf("ruleFor() = %+v, want months %d, term %q, sections %s/%s, indexed %v",
+					r, tt.months, tt.term, tt.stSec, tt.ltSec, tt.indexed)
+			}
+			if rateString(r.stRate) != tt.stRate || rateString(r.ltRate) != tt.ltRate {
+				t.Errorf("ruleFor() rates = %s/%s, want %s/%s", rateString(r.stRate), rateString(r.ltRate), tt.stRate, tt.ltRate)
+			}
+		})
+	}
+}
+
+func TestIsLongTerm(t *testing.T) {
+	tests := []struct {
+		buy, sell string
+		months    int
+		want      bool
+	}{
+		{"2023-01-15", "2024-01-15", 12, false}, // exactly 12 months is not more than 12
+		{"2023-01-15", "2024-01-16", 12, true},
+		{"2023-01-15", "2023-12-31", 12, false},
+		{"2022-07-23", "2024-07-23", 24, false},
+		{"2022-07-23", "2024-07-24", 24, true},
+		{"2021-04-01", "2024-04-01", 36, false},
+		{"2021-04-01", "2024-04-02", 36, true},
+	}
+	for _, tt := range tests {
+		if got := isLongTerm(day(tt.buy), day(tt.sell), tt.months); got != tt.want {
+			t.Errorf("isLongTerm(%s, %s, %d) = %v, want %v", tt.buy, tt.sell, tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestIndexCost(t *testing.T) {
+	tests := []struct {
+		name      string
+		buy, sell string
+		want      float64
+		ok        bool
+	}{
+		{"FY 2015-16 to FY 2023-24", "2015-06-01", "2023-06-01", 100000 * 348.0 / 254, true},
+		{"January is the year before", "2016-01-20", "2024-03-31", 100000 * 348.0 / 254, true},
+		{"April starts the year", "2016-04-01", "2024-04-01", 100000 * 363.0 / 264, true},
+		{"bought before 2001 indexes from 2001-02", "1995-06-01", "2023-06-01", 100000 * 348.0 / 100, true},
+		{"same year", "2024-05-01", "2024-06-01", 100000, true},
+		{"no index for the year of sale", "2020-06-01", "2026-06-01", 100000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := indexCost(100000, day(tt.buy), day(tt.sell))
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("indexCost() = %g, %v, want %g, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFYStart(t *testing.T) {
+	tests := []struct {
+		date string
+		want int
+	}{
+		{"2024-03-31", 2023},
+		{"2024-04-01", 2024},
+		{"2024-12-31", 2024},
+	}
+	for _, tt := range tests {
+		if got := FYStart(day(tt.date)); got != tt.want {
+			t.Errorf("FYStart(%s) = %d, want %d", tt.date, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Package chits derives the figures of a chit fund membership from its
+// auction records: what has been paid, what is still owed, the upcoming
+// instalments and the effective return (XIRR) of the money put in against
+// the prize taken out.
+package chits
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/returns"
+)
+
+const dateLayout = "2006-01-02"
+
+// Month is one resolved auction record: missing dates, dividends and net
+// payments are filled in from the chit's terms
+type Month struct {
+	models.ChitAuction
+	Won bool `json:"won"` // The month we took the pot
+}
+
+// Instalment is a scheduled payment not yet recorded
+type Instalment struct {
+	ChitID       string  `json:"chitId"`
+	Name         string  `json:"name"`
+	Month        int     `json:"month"`
+	Date         string  `json:"date"`
+	Subscription float64 `json:"subscription"` // Full instalment, before the month's dividend
+	Estimate     float64 `json:"estimate"`     // Subscription less the average dividend so far
+	Overdue      bool    `json:"overdue"`
+}
+
+// Status is the derived state of a chit on a date
+type Status struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Foreman      string  `json:"foreman"`
+	ChitValue    float64 `json:"chitValue"`
+	Tenure       int     `json:"tenure"`
+	Subscription float64 `json:"subscription"`
+	Commission   float64 `json:"commission"` // Foreman's cut per auction
+
+	MonthsPaid     int     `json:"monthsPaid"`
+	TotalPaid      float64 `json:"totalPaid"`
+	DividendEarned float64 `json:"dividendEarned"`
+	Won            bool    `json:"won"`
+	WonMonth       int     `json:"wonMonth"`
+	PrizeAmount    float64 `json:"prizeAmount"` // 0 while the won month's bid is unknown
+	PrizeDate      string  `json:"prizeDate,omitempty"`
+
+	// Instalments still to pay: RemainingLiability at the full subscription,
+	// EstimatedLiability after the average dividend seen so far
+	RemainingInstalments int     `json:"remainingInstalments"`
+	RemainingLiability   float64 `json:"remainingLiability"`
+	EstimatedLiability   float64 `json:"estimatedLiability"`
+	Overdue              int     `json:"overdue"` // Scheduled months before today with no record
+
+	// Returns treats instalments as payments and the prize as a withdrawal.
+	// Projected is set when it includes estimated future instalments or,
+	// before winning, a prize of ChitValue less commission in the last month.
+	Returns   returns.Summary `json:"returns"`
+	Projected bool            `json:"projected"`
+
+	NextInstalment *Instalment `json:"nextInstalment"`
+	Months         []Month     `json:"months"`
+}
+
+// DueDate is the scheduled date of month m (1-based), on the start date's
+// day of month or the last day of shorter months
+func DueDate(c models.Chit, m int) time.Time {
+	start, _ := time.Parse(dateLayout, c.StartDate)
+	return calc.AddMonths(start, m-1)
+}
+
+// Commission is the foreman's cut of each auction
+func Commission(c models.Chit) float64 {
+	return calc.Round2(c.ChitValue * c.CommissionPct / 100)
+}
+
+// Dividend is one member's share of a winning bid: the discount less the
+// foreman's commission, split across all members
+func Dividend(c models.Chit, bid float64) float64 {
+	if c.Tenure <= 0 {
+		return 0
+	}
+	return calc.Round2(math.Max(0, bid-Commission(c)) / float64(c.Tenure))
+}
+
+// Resolve returns the chit's auction records in month order with dates,
+// dividends and net payments filled in where they were left at zero
+func Resolve(c models.Chit) []Month {
+	months := make([]Month, 0, len(c.Auctions))
+	for _, a := range c.Auctions {
+		if a.Date == "" {
+			a.Date = DueDate(c, a.Month).Format(dateLayout)
+		}
+		if a.Dividend == 0 && a.BidAmount > 0 {
+			a.Dividend = Dividend(c, a.BidAmount)
+		}
+		if a.NetPaid == 0 {
+			a.NetPaid = calc.Round2(math.Max(0, c.Subscription-a.Dividend))
+		}
+		months = append(months, Month{ChitAuction: a, Won: a.Month == c.WonMonth})
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Month < months[j].Month })
+	return months
+}
+
+// Prize is the amount received on winning: PrizeAmount if recorded,
+// otherwise the chit value less the winning bid. It returns 0 before the
+// chit is won or while the won month's bid is unknown.
+func Prize(c models.Chit) (amount float64, date time.Time) {
+	if c.WonMonth == 0 {
+		return 0, time.Time{}
+	}
+	date = DueDate(c, c.WonMonth)
+	amount = c.PrizeAmount
+	for _, a := range c.Auctions {
+		if a.Month != c.WonMonth {
+			continue
+		}
+		if a.Date != "" {
+			date, _ = time.Parse(dateLayout, a.Date)
+		}
+		if amount == 0 && a.BidAmount > 0 {
+			amount = calc.Round2(c.ChitValue - a.BidAmount)
+		}
+	}
+	if c.PrizeDate != "" {
+		date, _ = time.Parse(dateLayout, c.PrizeDate)
+	}
+	return amount, date
+}
+
+// Pending lists the months with no auction record, in order
+func Pending(c models.Chit) []int {
+	recorded := make(map[int]bool, len(c.Auctions))
+	for _, a := range c.Auctions {
+		recorded[a.Month] = true
+	}
+	var months []int
+	for m := 1; m <= c.Tenure; m++ {
+		if !recorded[m] {
+			months = append(months, m)
+		}
+	}
+	return months
+}
+
+// estimate is the expected net instalment of a future month: the
+// subscription less the average dividend recorded so far
+func estimate(c models.Chit, months []Month) float64 {
+	if len(months) == 0 {
+		return c.Subscription
+	}
+	var dividends float64
+	for _, m := range months {
+		dividends += m.Dividend
+	}
+	return calc.Round2(math.Max(0, c.Subscription-dividends/float64(len(months))))
+}
+
+// Upcoming lists the unrecorded instalments of c due on or before horizon,
+// flagging those already past today as overdue
+func Upcoming(c models.Chit, today, horizon time.Time) []Instalment {
+	est := estimate(c, Resolve(c))
+	var list []Instalment
+	for _, m := range Pending(c) {
+		due := DueDate(c, m)
+		if due.After(horizon) {
+			break
+		}
+		list = append(list, Instalment{
+			ChitID:       c.ID,
+			Name:         c.Name,
+			Month:        m,
+			Date:         due.Format(dateLayout),
+			Subscription: c.Subscription,
+			Estimate:     est,
+			Overdue:      due.Before(today),
+		})
+	}
+	return list
+}
+
+// Compute derives the status of c on today
+func Compute(c models.Chit, today time.Time) Status {
+	months := Resolve(c)
+	s := Status{
+		ID:           c.ID,
+		Name:         c.Name,
+		Foreman:      c.Foreman,
+		ChitValue:    c.ChitValue,
+		Tenure:       c.Tenure,
+		Subscription: c.Subscription,
+		Commission:   Commission(c),
+		MonthsPaid:   len(months),
+		Won:          c.WonMonth > 0,
+		WonMonth:     c.WonMonth,
+		Months:       months,
+	}
+
+	var flows []returns.CashFlow
+	for _, m := range months {
+		s.TotalPaid += m.NetPaid
+		s.DividendEarned += m.Dividend
+		date, _ := time.Parse(dateLayout, m.Date)
+		if m.NetPaid > 0 {
+			flows = append(flows, returns.CashFlow{Date: date, Amount: -m.NetPaid})
+		}
+	}
+	s.TotalPaid = calc.Round2(s.TotalPaid)
+	s.DividendEarned = calc.Round2(s.DividendEarned)
+
+	est := estimate(c, months)
+	pending := Pending(c)
+	s.RemainingInstalments = len(pending)
+	s.RemainingLiability = calc.Round2(float64(len(pending)) * c.Subscription)
+	s.EstimatedLiability = calc.Round2(float64(len(pending)) * est)
+	for _, m := range pending {
+		due := DueDate(c, m)
+		if due.Before(today) {
+			s.Overdue++
+		}
+		if s.NextInstalment == nil {
+			s.NextInstalment = &Instalment{
+				ChitID:       c.ID,
+				Name:         c.Name,
+				Month:        m,
+				Date:         due.Format(dateLayout),
+				Subscription: c.Subscription,
+				Estimate:     est,
+				Overdue:      due.Before(today),
+			}
+		}
+	}
+
+	// Returns: actual flows plus, until the chit is over, the estimated
+	// remaining instalments and (before winning) the last month's pot
+	prize, prizeDate := Prize(c)
+	if prize > 0 {
+		s.PrizeAmount = prize
+		s.PrizeDate = prizeDate.Format(dateLayout)
+		flows = append(flows, returns.CashFlow{Date: prizeDate, Amount: prize})
+	}
+	asOf := today
+	if len(pending) > 0 && est > 0 {
+		s.Projected = true
+		for _, m := range pending {
+			flows = append(flows, returns.CashFlow{Date: DueDate(c, m), Amount: -est})
+		}
+	}
+	if c.WonMonth == 0 {
+		s.Projected = true
+		flows = append(flows, returns.CashFlow{Date: DueDate(c, c.Tenure), Amount: c.ChitValue - s.Commission})
+	}
+	for _, f := range flows {
+		if f.Date.After(asOf) {
+			asOf = f.Date
+		}
+	}
+	sort.Slice(flows, func(i, j int) bool { return flows[i].Date.Before(flows[j].Date) })
+	s.Returns = returns.Compute(flows, 0, asOf)
+	return s
+}
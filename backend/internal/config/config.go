@@ -5,26 +5,42 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Config holds application configuration
 type Config struct {
-	Port     string `json:"port"`
-	DataDir  string `json:"data_dir"`
-	LogLevel string `json:"log_level"`
-	LogDir   string `json:"log_dir"`
-	Debug    bool   `json:"debug"`
+	Port          string `json:"port"`
+	DataDir       string `json:"data_dir"`
+	StorageDriver string `json:"storage_driver"` // "json" (default) or "sqlite"
+	LogLevel      string `json:"log_level"`
+	LogDir        string `json:"log_dir"`
+	Debug         bool   `json:"debug"`
+
+	NAVProvider        string `json:"nav_provider"`         // "mfapi" (default) or "amfi"
+	NAVSource          string `json:"nav_source"`           // Provider base URL or NAVAll.txt path; empty for the public source
+	NAVRefreshInterval string `json:"nav_refresh_interval"` // Go duration, e.g. "12h"; "0" disables scheduled refresh
+
+	SessionTTL  string   `json:"session_ttl"`  // Go duration a login lasts, e.g. "720h"
+	CORSOrigins []string `json:"cors_origins"` // Origins browsers may call the API from, e.g. "http://192.168.1.100:5173"
 }
 
 // Load reads configuration from config.json file
 // Falls back to environment variables and defaults if file not found
 func Load() *Config {
 	cfg := &Config{
-		Port:     "5000",
-		DataDir:  "./data",
-		LogLevel: "info",
-		LogDir:   "./logs",
-		Debug:    false,
+		Port:          "5000",
+		DataDir:       "./data",
+		StorageDriver: "json",
+		LogLevel:      "info",
+		LogDir:        "./logs",
+		Debug:         false,
+
+		NAVProvider:        "mfapi",
+		NAVRefreshInterval: "12h",
+
+		SessionTTL:  "720h",
+		CORSOrigins: []string{"http://localhost:5173", "http://127.0.0.1:5173"},
 	}
 
 	// Try to load from config.json
@@ -46,6 +62,9 @@ func Load() *Config {
 	if dataDir := os.Getenv("DATA_DIR"); dataDir != "" {
 		cfg.DataDir = dataDir
 	}
+	if driver := os.Getenv("STORAGE_DRIVER"); driver != "" {
+		cfg.StorageDriver = driver
+	}
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		cfg.LogLevel = logLevel
 	}
@@ -55,6 +74,24 @@ func Load() *Config {
 	if debug := os.Getenv("DEBUG"); debug == "true" {
 		cfg.Debug = true
 	}
+	if provider := os.Getenv("NAV_PROVIDER"); provider != "" {
+		cfg.NAVProvider = provider
+	}
+	if source := os.Getenv("NAV_SOURCE"); source != "" {
+		cfg.NAVSource = source
+	}
+	if interval := os.Getenv("NAV_REFRESH_INTERVAL"); interval != "" {
+		cfg.NAVRefreshInterval = interval
+	}
+	if ttl := os.Getenv("SESSION_TTL"); ttl != "" {
+		cfg.SessionTTL = ttl
+	}
+	if origins := os.Getenv("CORS_ORIGINS"); origins != "" {
+		cfg.CORSOrigins = strings.Split(origins, ",")
+		for i := range cfg.CORSOrigins {
+			cfg.CORSOrigins[i] = strings.TrimSpace(cfg.CORSOrigins[i])
+		}
+	}
 
 	return cfg
 }
@@ -0,0 +1,250 @@
+// Package deposits values fixed and recurring deposits from their terms. It
+// projects interest and maturity proceeds, and its Engine keeps each
+// deposit's Current accrued to today, adds RD instalments as they fall due
+// and, when asked to, posts interest as income and closes matured deposits.
+package deposits
+
+import (
+	"math"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// Payout is one interest payment of a payout FD
+type Payout struct {
+	Date  string  `json:"date"`
+	Gross float64 `json:"gross"`
+	TDS   float64 `json:"tds"`
+	Net   float64 `json:"net"`
+}
+
+// Projection is a deposit valued on a date and projected to maturity.
+// Interest is shown before TDS; NetProceeds is what the bank pays out at
+// maturity after deducting it.
+type Projection struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Kind           string   `json:"kind"`
+	Rate           float64  `json:"rate"`
+	StartDate      string   `json:"startDate"`
+	MaturityDate   string   `json:"maturityDate"`
+	DaysToMaturity int      `json:"daysToMaturity"` // Negative once matured
+	Matured        bool     `json:"matured"`
+	Closed         bool     `json:"closed"` // Maturity proceeds recorded
+	Principal      float64  `json:"principal"`
+	Deposited      float64  `json:"deposited"` // Paid in so far
+	Value          float64  `json:"value"`     // Accrued value on the date
+	Accrued        float64  `json:"accrued"`   // Interest in Value not yet paid out
+	MaturityValue  float64  `json:"maturityValue"`
+	Interest       float64  `json:"interest"` // Over the whole term
+	TDS            float64  `json:"tds"`
+	NetProceeds    float64  `json:"netProceeds"`
+	Payouts        []Payout `json:"payouts,omitempty"`
+}
+
+// MaturityTxnID is the ID of the redemption that closes a matured deposit
+const MaturityTxnID = "maturity"
+
+func periodsPerYear(compounding string) float64 {
+	switch compounding {
+	case models.FrequencyMonthly:
+		return 12
+	case models.FrequencyHalfYearly:
+		return 2
+	case models.FrequencyYearly:
+		return 1
+	default:
+		return 4
+	}
+}
+
+func payoutMonths(payout string) int {
+	switch payout {
+	case models.FrequencyMonthly:
+		return 1
+	case models.FrequencyQuarterly:
+		return 3
+	case models.FrequencyHalfYearly:
+		return 6
+	case models.FrequencyYearly:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// grow compounds amount from one date to another at the deposit's rate
+func grow(d *models.Deposit, amount float64, from, to time.Time) float64 {
+	if !to.After(from) {
+		return amount
+	}
+	n := periodsPerYear(d.Compounding)
+	years := to.Sub(from).Hours() / 24 / 365
+	return amount * math.Pow(1+d.Rate/100/n, n*years)
+}
+
+// simple is simple interest on amount between two dates
+func simple(d *models.Deposit, amount float64, from, to time.Time) float64 {
+	if !to.After(from) {
+		return 0
+	}
+	return amount * d.Rate / 100 * to.Sub(from).Hours() / 24 / 365
+}
+
+// Start is the deposit's opening date: the holding's first transaction
+func Start(inv models.Investment) time.Time {
+	date := inv.Date
+	if len(inv.Transactions) > 0 {
+		date = inv.Transactions[0].Date
+	}
+	t, _ := time.Parse(dateLayout, calc.DateOnly(date))
+	return t
+}
+
+// Maturity is the maturity date: as given, or the start plus the tenure
+func Maturity(inv models.Investment) time.Time {
+	if t, err := time.Parse(dateLayout, inv.Deposit.MaturityDate); err == nil {
+		return t
+	}
+	return calc.AddMonths(Start(inv), inv.Deposit.Tenure)
+}
+
+// Instalments are the due dates of an RD's monthly deposits
+func Instalments(inv models.Investment) []time.Time {
+	start := Start(inv)
+	dates := make([]time.Time, 0, inv.Deposit.Tenure)
+	for k := 0; k < inv.Deposit.Tenure; k++ {
+		dates = append(dates, calc.AddMonths(start, k))
+	}
+	return dates
+}
+
+// principal is an FD's amount deposited: what its purchases paid in
+func principal(inv models.Investment) float64 {
+	var total float64
+	for _, t := range inv.Transactions {
+		if t.IsAcquisition() {
+			total += t.Amount
+		}
+	}
+	if total == 0 {
+		total = inv.Invested
+	}
+	return total
+}
+
+// closed reports whether the maturity redemption has been recorded
+func closed(inv models.Investment) bool {
+	for _, t := range inv.Transactions {
+		if t.ID == MaturityTxnID {
+			return true
+		}
+	}
+	return false
+}
+
+// Project values inv (which must have deposit terms) on asOf
+func Project(inv models.Investment, asOf time.Time) Projection {
+	d := inv.Deposit
+	start, maturity := Start(inv), Maturity(inv)
+	p := Projection{
+		ID:             inv.ID,
+		Name:           inv.Name,
+		Kind:           d.Kind,
+		Rate:           d.Rate,
+		StartDate:      start.Format(dateLayout),
+		MaturityDate:   maturity.Format(dateLayout),
+		DaysToMaturity: int(maturity.Sub(asOf).Hours() / 24),
+		Matured:        !asOf.Before(maturity),
+		Closed:         closed(inv),
+	}
+	on := asOf
+	if p.Matured {
+		on = maturity
+	}
+
+	switch {
+	case d.Kind == models.DepositRD:
+		for _, due := range Instalments(inv) {
+			p.Principal += d.Instalment
+			p.MaturityValue += grow(d, d.Instalment, due, maturity)
+			if !due.After(on) {
+				p.Deposited += d.Instalment
+				p.Value += grow(d, d.Instalment, due, on)
+			}
+		}
+		p.Interest = p.MaturityValue - p.Principal
+		p.Accrued = p.Value - p.Deposited
+
+	case payoutMonths(d.Payout) > 0:
+		p.Principal = principal(inv)
+		p.Deposited = p.Principal
+		p.MaturityValue = p.Principal
+		last := start
+		for _, payout := range payouts(d, p.Principal, start, maturity) {
+			p.Interest += payout.Gross
+			p.Payouts = append(p.Payouts, payout)
+			if date, _ := time.Parse(dateLayout, payout.Date); !date.After(on) {
+				last = date
+			}
+		}
+		p.Accrued = simple(d, p.Principal, last, on)
+		p.Value = p.Principal + p.Accrued
+
+	default:
+		p.Principal = principal(inv)
+		p.Deposited = p.Principal
+		p.MaturityValue = grow(d, p.Principal, start, maturity)
+		p.Interest = p.MaturityValue - p.Principal
+		p.Value = grow(d, p.Principal, start, on)
+		p.Accrued = p.Value - p.Principal
+	}
+
+	p.Principal = calc.Round2(p.Principal)
+	p.Deposited = calc.Round2(p.Deposited)
+	p.Value = calc.Round2(p.Value)
+	p.Accrued = calc.Round2(p.Accrued)
+	p.MaturityValue = calc.Round2(p.MaturityValue)
+	p.Interest = calc.Round2(p.Interest)
+	if len(p.Payouts) > 0 {
+		// Payouts carry their own TDS; only principal comes back at maturity
+		for _, payout := range p.Payouts {
+			p.TDS += payout.TDS
+		}
+		p.NetProceeds = p.MaturityValue
+	} else {
+		p.TDS = tds(d, p.Interest)
+		p.NetProceeds = calc.Round2(p.MaturityValue - p.TDS)
+	}
+	p.TDS = calc.Round2(p.TDS)
+	return p
+}
+
+// payouts lists a payout FD's interest payments: one per full period, and
+// a last one at maturity for any part period
+func payouts(d *models.Deposit, principal float64, start, maturity time.Time) []Payout {
+	months := payoutMonths(d.Payout)
+	var list []Payout
+	prev := start
+	for k := 1; prev.Before(maturity); k++ {
+		date := calc.AddMonths(start, k*months)
+		gross := principal * d.Rate / 100 * float64(months) / 12
+		if date.After(maturity) {
+			date = maturity
+			gross = simple(d, principal, prev, maturity)
+		}
+		gross = calc.Round2(gross)
+		t := tds(d, gross)
+		list = append(list, Payout{Date: date.Format(dateLayout), Gross: gross, TDS: t, Net: calc.Round2(gross - t)})
+		prev = date
+	}
+	return list
+}
+
+func tds(d *models.Deposit, interest float64) float64 {
+	return calc.Round2(interest * d.TDSRate / 100)
+}
@@ -0,0 +1,262 @@
+package deposits
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/portfolio"
+	"finance-tracker/internal/storage"
+)
+
+// IncomeCategory is the category of posted interest
+const IncomeCategory = "Interest"
+
+// incomeNamespace seeds the deterministic IDs of posted interest
+var incomeNamespace = uuid.MustParse("4f7d2c8a-1b6e-4c35-9a0e-6d2f8b3c5e71")
+
+// IncomeID is the ID of the income posted for deposit id on date
+func IncomeID(id, date string) string {
+	return uuid.NewSHA1(incomeNamespace, []byte(id+"/"+date)).String()
+}
+
+// Posting is one income posted or holding closed by a run
+type Posting struct {
+	InvestmentID string  `json:"investmentId"`
+	Name         string  `json:"name"`
+	Date         string  `json:"date"`
+	Kind         string  `json:"kind"` // "interest" or "maturity"
+	Amount       float64 `json:"amount"`
+}
+
+// RunResult summarises one accrual pass
+type RunResult struct {
+	Updated int       `json:"updated"` // Deposits whose value or transactions changed
+	Posted  []Posting `json:"posted"`
+	Errors  []string  `json:"errors"`
+}
+
+// Engine accrues deposits. Like the recurring engine it serialises runs, and
+// every posted record has a deterministic ID so a run interrupted half way
+// never posts twice.
+type Engine struct {
+	mu    sync.Mutex
+	store storage.Storage
+	now   func() time.Time
+}
+
+// NewEngine creates an engine backed by store
+func NewEngine(store storage.Storage) *Engine {
+	return &Engine{store: store, now: time.Now}
+}
+
+func (e *Engine) today() time.Time {
+	now := e.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Run brings every deposit up to date
+func (e *Engine) Run() RunResult {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := RunResult{Posted: []Posting{}, Errors: []string{}}
+	today := e.today()
+	for _, inv := range e.store.GetInvestments() {
+		if inv.Deposit == nil {
+			continue
+		}
+		posted, changed, err := e.accrue(inv, today)
+		result.Posted = append(result.Posted, posted...)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", inv.Name, err))
+			continue
+		}
+		if changed {
+			result.Updated++
+		}
+	}
+	return result
+}
+
+// Maturities lists open deposits maturing within days of today, those
+// already matured but not closed first
+func (e *Engine) Maturities(days int) []Projection {
+	today := e.today()
+	horizon := today.AddDate(0, 0, days)
+
+	list := []Projection{}
+	for _, inv := range e.store.GetInvestments() {
+		if inv.Deposit == nil {
+			continue
+		}
+		p := Project(inv, today)
+		if p.Closed || Maturity(inv).After(horizon) {
+			continue
+		}
+		list = append(list, p)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].MaturityDate < list[j].MaturityDate })
+	return list
+}
+
+// accrue updates one deposit: RD instalments due, interest to post, the
+// maturity redemption and finally Current
+func (e *Engine) accrue(inv models.Investment, today time.Time) ([]Posting, bool, error) {
+	d := inv.Deposit
+	prev := inv
+	prev.Transactions = append([]models.InvestmentTransaction(nil), inv.Transactions...)
+	var posted []Posting
+
+	if d.Kind == models.DepositRD {
+		addInstalments(&inv, today)
+	}
+	p := Project(inv, today)
+	if d.PostIncome {
+		for _, payout := range p.Payouts {
+			if payout.Date > today.Format(dateLayout) {
+				break
+			}
+			ok, err := e.postInterest(inv, payout.Date, payout.Net, payout.TDS)
+			if err != nil {
+				return posted, false, err
+			}
+			if ok {
+				posted = append(posted, Posting{InvestmentID: inv.ID, Name: inv.Name, Date: payout.Date, Kind: "interest", Amount: payout.Net})
+			}
+		}
+		if p.Matured && !p.Closed {
+			if len(p.Payouts) == 0 {
+				net := calc.Round2(p.Interest - p.TDS)
+				ok, err := e.postInterest(inv, p.MaturityDate, net, p.TDS)
+				if err != nil {
+					return posted, false, err
+				}
+				if ok {
+					posted = append(posted, Posting{InvestmentID: inv.ID, Name: inv.Name, Date: p.MaturityDate, Kind: "interest", Amount: net})
+				}
+			}
+			// The interest is income now, so the holding returns what was
+			// paid in and books no gain of its own
+			inv.Transactions = append(inv.Transactions, models.InvestmentTransaction{
+				ID:     MaturityTxnID,
+				Type:   models.TxnRedeem,
+				Date:   p.MaturityDate,
+				Amount: p.Deposited,
+				Note:   fmt.Sprintf("Matured: %.2f paid out", p.NetProceeds),
+			})
+			posted = append(posted, Posting{InvestmentID: inv.ID, Name: inv.Name, Date: p.MaturityDate, Kind: "maturity", Amount: p.NetProceeds})
+			p.Closed = true
+		}
+	}
+
+	if _, err := portfolio.Apply(&inv); err != nil {
+		return posted, false, err
+	}
+	inv.Current = p.Value
+	if p.Closed {
+		inv.Current = 0
+	}
+	if inv.Current == prev.Current && len(inv.Transactions) == len(prev.Transactions) {
+		return posted, false, nil
+	}
+
+	inv.UpdatedAt = e.now().Format(time.RFC3339)
+	if err := e.store.UpdateInvestment(inv.ID, inv); err != nil {
+		return posted, false, err
+	}
+	if err := e.store.SaveInvestments(); err != nil {
+		return posted, false, err
+	}
+	return posted, true, nil
+}
+
+// Accrue adds the RD instalments due by today and sets Current to the
+// deposit's accrued value, without posting anything. Handlers use it so a
+// deposit is valued as soon as it is saved.
+func Accrue(inv *models.Investment, today time.Time) error {
+	if inv.Deposit == nil {
+		return nil
+	}
+	if inv.Deposit.Kind == models.DepositRD {
+		addInstalments(inv, today)
+	}
+	if _, err := portfolio.Apply(inv); err != nil {
+		return err
+	}
+	inv.Current = Project(*inv, today).Value
+	if closed(*inv) {
+		inv.Current = 0
+	}
+	return nil
+}
+
+// addInstalments adds an SIP transaction for each RD instalment due by
+// today, unless a purchase is already recorded on that date
+func addInstalments(inv *models.Investment, today time.Time) {
+	bought := map[string]bool{}
+	for _, t := range inv.Transactions {
+		if t.IsAcquisition() {
+			bought[t.Date] = true
+		}
+	}
+	for k, due := range Instalments(*inv) {
+		date := due.Format(dateLayout)
+		if due.After(today) {
+			break
+		}
+		if bought[date] {
+			continue
+		}
+		inv.Transactions = append(inv.Transactions, models.InvestmentTransaction{
+			ID:     fmt.Sprintf("rd-%d", k+1),
+			Type:   models.TxnSIP,
+			Date:   date,
+			Amount: inv.Deposit.Instalment,
+			Note:   fmt.Sprintf("Instalment %d", k+1),
+		})
+	}
+}
+
+// postInterest records interest received from inv on date as income. It
+// reports false when the income already exists.
+func (e *Engine) postInterest(inv models.Investment, date string, net, tds float64) (bool, error) {
+	id := IncomeID(inv.ID, date)
+	for _, inc := range e.store.GetIncomes() {
+		if inc.ID == id {
+			return false, nil
+		}
+	}
+	if net <= 0 {
+		return false, nil
+	}
+
+	source := inv.Name + " interest"
+	if tds > 0 {
+		source = fmt.Sprintf("%s interest (TDS %.2f)", inv.Name, tds)
+	}
+	stamp := e.now().Format(time.RFC3339)
+	inc := models.Income{
+		ID:            id,
+		Source:        source,
+		Amount:        net,
+		Category:      IncomeCategory,
+		Date:          date,
+		AddedBy:       inv.Deposit.Member,
+		PaymentMethod: "Bank Transfer",
+		CreatedAt:     stamp,
+		UpdatedAt:     stamp,
+	}
+	if err := e.store.AddIncome(inc); err != nil {
+		return false, err
+	}
+	if err := e.store.SaveIncomes(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,268 @@
+// Package duplicates finds expenses and incomes that were likely recorded
+// twice, e.g. typed in by two family members or imported from a statement
+// after being entered by hand.
+package duplicates
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+// DefaultThreshold is the score from which two records are suspected
+// duplicates: the same amount on the same day paid the same way, or the
+// same amount a day or two apart with a similar description. Records whose
+// descriptions share nothing never reach it (see Score).
+const DefaultThreshold = 0.75
+
+// MaxDays is how far apart two records may be dated and still match
+const MaxDays = 3
+
+// Weights of the score's parts; they add up to 1
+const (
+	amountWeight = 0.4
+	dateWeight   = 0.3
+	descWeight   = 0.2
+	methodWeight = 0.1
+)
+
+// amountTolerance is the relative difference still scored as a near match,
+// e.g. a card charge against a bill rounded by hand
+const amountTolerance = 0.01
+
+// Record is an expense or income as the detector sees it
+type Record struct {
+	Kind          string  `json:"kind"` // "expense" or "income"
+	ID            string  `json:"id"`
+	Date          string  `json:"date"`
+	Amount        float64 `json:"amount"`
+	Description   string  `json:"description"`
+	Category      string  `json:"category"`
+	PaymentMethod string  `json:"paymentMethod"`
+	AddedBy       string  `json:"addedBy"`
+	AccountID     string  `json:"accountId,omitempty"`
+	FITID         string  `json:"fitId,omitempty"`
+}
+
+// FromExpense is the record of an expense
+func FromExpense(e models.Expense) Record {
+	return Record{models.KindExpense, e.ID, calc.DateOnly(e.Date), e.Amount, e.Desc, e.Category, e.PaymentMethod, e.AddedBy, e.AccountID, e.FITID}
+}
+
+// FromIncome is the record of an income
+func FromIncome(i models.Income) Record {
+	return Record{models.KindIncome, i.ID, calc.DateOnly(i.Date), i.Amount, i.Source, i.Category, i.PaymentMethod, i.AddedBy, i.AccountID, i.FITID}
+}
+
+// Records lists expenses and incomes as records
+func Records(expenses []models.Expense, incomes []models.Income) []Record {
+	records := make([]Record, 0, len(expenses)+len(incomes))
+	for _, e := range expenses {
+		records = append(records, FromExpense(e))
+	}
+	for _, i := range incomes {
+		records = append(records, FromIncome(i))
+	}
+	return records
+}
+
+// Score rates from 0 to 1 how likely a and b are the same transaction.
+// The same bank transaction ID in one account scores 1. Records of
+// different kinds, amounts more than 1% apart, dates more than MaxDays
+// apart or descriptions with no word in common score 0, so two ₹50 UPI
+// payments on one day for "Milk" and "Auto" are not taken for one.
+func Score(a, b Record) float64 {
+	if a.Kind != b.Kind || a.ID != "" && a.ID == b.ID {
+		return 0
+	}
+	if a.FITID != "" && a.FITID == b.FITID && a.AccountID == b.AccountID {
+		return 1
+	}
+
+	var score float64
+	diff := math.Abs(a.Amount - b.Amount)
+	switch {
+	case diff < 0.005:
+		score += amountWeight
+	case diff <= amountTolerance*math.Max(a.Amount, b.Amount):
+		score += amountWeight / 2
+	default:
+		return 0
+	}
+
+	days, ok := daysApart(a.Date, b.Date)
+	if !ok || days > MaxDays {
+		return 0
+	}
+	score += dateWeight * float64(MaxDays+1-days) / (MaxDays + 1)
+
+	similarity := Similarity(a.Description, b.Description)
+	if similarity == 0 {
+		return 0
+	}
+	score += descWeight * similarity
+
+	ma, mb := strings.ToLower(a.PaymentMethod), strings.ToLower(b.PaymentMethod)
+	switch {
+	case ma == mb:
+		score += methodWeight
+	case ma == "" || mb == "":
+		score += methodWeight / 2
+	}
+	return math.Round(score*100) / 100
+}
+
+// noise are words of bank narrations that say nothing about the payee
+var noise = map[string]bool{
+	"upi": true, "neft": true, "imps": true, "rtgs": true, "pos": true, "atm": true,
+	"ref": true, "txn": true, "payment": true, "paid": true, "transfer": true,
+	"from": true, "the": true, "and": true, "for": true, "via": true, "ltd": true,
+	"pvt": true, "india": true, "bank": true, "debit": true, "credit": true, "card": true,
+}
+
+// Words normalises a description to the words that identify a payee:
+// lower-case, split on anything but letters, without noise words and
+// words shorter than three letters
+func Words(desc string) []string {
+	var words []string
+	seen := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(desc), func(r rune) bool {
+		return r < 'a' || r > 'z'
+	}) {
+		if len(w) < 3 || noise[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words
+}
+
+// Similarity is the share of the shorter description's words found in the
+// other, so "Swiggy" matches "UPI-SWIGGY-swiggy@icici-Food order".
+// A description with no such words (a bare "UPI/4201") says nothing about
+// the payee, so it counts as half alike to any other.
+func Similarity(a, b string) float64 {
+	wa, wb := Words(a), Words(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0.5
+	}
+	if len(wa) > len(wb) {
+		wa, wb = wb, wa
+	}
+	in := map[string]bool{}
+	for _, w := range wb {
+		in[w] = true
+	}
+	common := 0
+	for _, w := range wa {
+		if in[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wa))
+}
+
+// Match is a record scored against another
+type Match struct {
+	Record Record  `json:"record"`
+	Score  float64 `json:"score"`
+}
+
+// Matches lists the records scoring at least threshold against r, best
+// first
+func Matches(r Record, records []Record, threshold float64) []Match {
+	var matches []Match
+	for _, other := range records {
+		if s := Score(r, other); s >= threshold {
+			matches = append(matches, Match{other, s})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches
+}
+
+// Group is a set of records suspected to be one transaction. Score is the
+// best score between two of them.
+type Group struct {
+	Kind    string   `json:"kind"`
+	Score   float64  `json:"score"`
+	Records []Record `json:"records"`
+}
+
+// Groups collects the records that score at least threshold against
+// another into groups, most likely first
+func Groups(records []Record, threshold float64) []Group {
+	sorted := append([]Record(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+
+	// Union-find over record positions; only records up to MaxDays apart
+	// need comparing
+	parent := make([]int, len(sorted))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	best := map[int]float64{}
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			if days, ok := daysApart(sorted[i].Date, sorted[j].Date); ok && days > MaxDays {
+				break
+			}
+			s := Score(sorted[i], sorted[j])
+			if s < threshold {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri != rj {
+				parent[rj] = ri
+				best[ri] = math.Max(best[ri], best[rj])
+			}
+			best[ri] = math.Max(best[ri], s)
+		}
+	}
+
+	members := map[int][]Record{}
+	var roots []int
+	for i, r := range sorted {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], r)
+	}
+	var groups []Group
+	for _, root := range roots {
+		if len(members[root]) < 2 {
+			continue
+		}
+		groups = append(groups, Group{Kind: members[root][0].Kind, Score: best[root], Records: members[root]})
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Score > groups[j].Score })
+	return groups
+}
+
+// daysApart is the number of days between two dates, false when either
+// is not a date
+func daysApart(a, b string) (int, bool) {
+	ta, errA := time.Parse("2006-01-02", a)
+	tb, errB := time.Parse("2006-01-02", b)
+	if errA != nil || errB != nil {
+		return 0, false
+	}
+	days := int(math.Round(tb.Sub(ta).Hours() / 24))
+	if days < 0 {
+		days = -days
+	}
+	return days, true
+}
@@ -0,0 +1,105 @@
+package duplicates
+
+import (
+	"reflect"
+	"testing"
+
+	"finance-tracker/internal/models"
+)
+
+func expense(id, date string, amount float64, desc, method string) Record {
+	return Record{Kind: models.KindExpense, ID: id, Date: date, Amount: amount, Description: desc, PaymentMethod: method}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Swiggy", "UPI-SWIGGY-swiggy@icici-Food order", 1},
+		{"Big Basket groceries", "BIGBASKET", 0},
+		{"Big Basket groceries", "basket", 1},
+		{"Milk", "Auto", 0},
+		{"Dinner at Toit", "Toit brewpub", 0.5},
+		{"Toit", "Dinner at Toit brewpub", 1},
+		{"Dinner at Toit", "Lunch at Toit", 0.5},
+		{"UPI/4201", "NEFT/991", 0.5},
+		{"UPI/4201", "Swiggy", 0.5},
+		{"", "", 0.5},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %g, want %g", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	milk := expense("1", "2026-10-01", 50, "Milk", "UPI")
+	fitA, fitB := expense("f1", "2026-10-01", 500, "Amazon", "Card"), expense("f2", "2026-10-09", 480, "Refund", "Card")
+	fitA.FITID, fitB.FITID = "T123", "T123"
+
+	tests := []struct {
+		name string
+		a, b Record
+		want float64
+	}{
+		{"same day, amount, method and payee", milk, expense("2", "2026-10-01", 50, "milk", "UPI"), 1},
+		{"unrelated payees on one day", milk, expense("2", "2026-10-01", 50, "Auto", "UPI"), 0},
+		{"bare narration against a payee", milk, expense("2", "2026-10-01", 50, "UPI/4201", "UPI"), 0.9},
+		{"two days apart", milk, expense("2", "2026-10-03", 50, "Milk", "UPI"), 0.85},
+		{"within 1%", expense("1", "2026-10-01", 1000, "Rent", "NEFT"), expense("2", "2026-10-01", 1008, "Rent", "NEFT"), 0.8},
+		{"method missing on one", milk, expense("2", "2026-10-01", 50, "Milk", ""), 0.95},
+		{"different methods", milk, expense("2", "2026-10-01", 50, "Milk", "Cash"), 0.9},
+		{"amounts apart", milk, expense("2", "2026-10-01", 60, "Milk", "UPI"), 0},
+		{"too far apart", milk, expense("2", "2026-10-05", 50, "Milk", "UPI"), 0},
+		{"expense and income", milk, Record{Kind: models.KindIncome, ID: "2", Date: "2026-10-01", Amount: 50, Description: "Milk"}, 0},
+		{"itself", milk, milk, 0},
+		{"same FITID", fitA, fitB, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Score(tt.a, tt.b); got != tt.want {
+				t.Errorf("Score() = %g, want %g", got, tt.want)
+			}
+			if got := Score(tt.b, tt.a); got != tt.want {
+				t.Errorf("Score() reversed = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroups(t *testing.T) {
+	records := []Record{
+		expense("milk", "2026-10-01", 50, "Milk", "UPI"),
+		expense("auto", "2026-10-01", 50, "Auto", "UPI"),
+		expense("swiggy1", "2026-10-02", 420, "Swiggy", "UPI"),
+		expense("swiggy2", "2026-10-02", 420, "UPI-SWIGGY-swiggy@icici", "UPI"),
+		expense("swiggy3", "2026-10-03", 420, "swiggy order", ""),
+		expense("rent1", "2026-10-05", 25000, "Rent", "NEFT"),
+		expense("rent2", "2026-10-20", 25000, "Rent", "NEFT"),
+		expense("milk2", "2026-10-01", 50, "Milk packet", "Cash"),
+	}
+
+	groups := Groups(records, DefaultThreshold)
+	var got [][]string
+	for _, g := range groups {
+		var ids []string
+		for _, r := range g.Records {
+			ids = append(ids, r.ID)
+		}
+		got = append(got, ids)
+	}
+	// Best group first; Auto and the month-apart rents stay out
+	want := [][]string{{"swiggy1", "swiggy2", "swiggy3"}, {"milk", "milk2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Groups() = %v, want %v", got, want)
+	}
+	if groups[0].Score != 1 || groups[1].Score != 0.9 {
+		t.Errorf("Groups() scores = %g, %g, want 1, 0.9", groups[0].Score, groups[1].Score)
+	}
+
+	if got := Groups(records, 1.01); got != nil {
+		t.Errorf("Groups() above any score = %v, want nil", got)
+	}
+}
@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/accounts"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+)
+
+// ----- ACCOUNTS -----
+
+// GetAccounts handles GET /api/accounts
+func (h *Handler) GetAccounts(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.store.GetAccounts(), http.StatusOK)
+}
+
+// CreateAccount handles POST /api/accounts
+func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	var a models.Account
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	a.ID = uuid.New().String()
+	a.CreatedAt = time.Now().Format(time.RFC3339)
+	a.UpdatedAt = a.CreatedAt
+
+	// Validate account
+	if err := a.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkLink(a); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddAccount(a); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add account: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveAccounts(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save account: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, a, http.StatusCreated)
+}
+
+// GetAccount handles GET /api/accounts/{id}
+// The account's balance today
+func (h *Handler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	book := h.book()
+	id := mux.Vars(r)["id"]
+	for _, s := range accounts.Balances(book, today().Format("2006-01-02")).Accounts {
+		if s.ID == id {
+			middleware.JSONResponse(w, s, http.StatusOK)
+			return
+		}
+	}
+	middleware.ErrorResponse(w, "Account not found", http.StatusNotFound)
+}
+
+// UpdateAccount handles PUT /api/accounts/{id}
+func (h *Handler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.Account
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	original, found := h.findAccount(id)
+	if !found {
+		middleware.ErrorResponse(w, "Account not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkLink(updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateAccount(id, updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update account: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveAccounts(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save account: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, updates, http.StatusOK)
+}
+
+// DeleteAccount handles DELETE /api/accounts/{id}
+// Accounts still named by records are closed rather than deleted
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if n := h.book().References(id); n > 0 {
+		middleware.ErrorResponse(w, fmt.Sprintf("Account is used by %d record(s); close it instead", n), http.StatusConflict)
+		return
+	}
+
+	if err := h.store.DeleteAccount(id); err != nil {
+		middleware.ErrorResponse(w, "Account not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveAccounts(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save account: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Account deleted successfully")
+}
+
+// AccountBalances handles GET /api/accounts/balances?date=YYYY-MM-DD
+// The balance of every account on a date (default today)
+func (h *Handler) AccountBalances(w http.ResponseWriter, r *http.Request) {
+	asOf, ok := dateParam(w, r, "date", today())
+	if !ok {
+		return
+	}
+	middleware.JSONResponse(w, accounts.Balances(h.book(), asOf.Format("2006-01-02")), http.StatusOK)
+}
+
+// AccountLedger handles GET /api/accounts/{id}/ledger?from=&to=
+// Entries with running balances, and the balances either side of the range
+func (h *Handler) AccountLedger(w http.ResponseWriter, r *http.Request) {
+	book := h.book()
+	a, found := book.Find(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Account not found", http.StatusNotFound)
+		return
+	}
+	if a.Kind == models.AccountUPI && a.LinkedAccountID != "" {
+		middleware.ErrorResponse(w, "A linked UPI handle's entries are in its bank account's ledger", http.StatusBadRequest)
+		return
+	}
+	from, ok := dateParam(w, r, "from", time.Time{})
+	if !ok {
+		return
+	}
+	to, ok := dateParam(w, r, "to", today())
+	if !ok {
+		return
+	}
+
+	fromDay, toDay := "", to.Format("2006-01-02")
+	if !from.IsZero() {
+		fromDay = from.Format("2006-01-02")
+	}
+	all := book.Ledgers()[a.ID]
+	entries := []accounts.Entry{}
+	opening := 0.0
+	for _, e := range all {
+		switch {
+		case e.Date < fromDay:
+			opening = e.Balance
+		case e.Date <= toDay:
+			entries = append(entries, e)
+		}
+	}
+	middleware.JSONResponse(w, map[string]interface{}{
+		"account":        a,
+		"from":           fromDay,
+		"to":             toDay,
+		"openingBalance": opening,
+		"closingBalance": accounts.BalanceOn(all, toDay),
+		"entries":        entries,
+	}, http.StatusOK)
+}
+
+// CardStatements handles GET /api/accounts/{id}/statements?date=YYYY-MM-DD
+// A credit card's statements, outstanding amount and unbilled spends
+func (h *Handler) CardStatements(w http.ResponseWriter, r *http.Request) {
+	book := h.book()
+	a, found := book.Find(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Account not found", http.StatusNotFound)
+		return
+	}
+	if a.Kind != models.AccountCreditCard {
+		middleware.ErrorResponse(w, "Statements are only kept for credit cards", http.StatusBadRequest)
+		return
+	}
+	asOf, ok := dateParam(w, r, "date", today())
+	if !ok {
+		return
+	}
+	middleware.JSONResponse(w, accounts.Statements(a, book.Ledgers()[a.ID], asOf), http.StatusOK)
+}
+
+// ----- TRANSFERS -----
+
+// GetTransfers handles GET /api/transfers
+func (h *Handler) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	list := append([]models.Transfer{}, h.store.GetTransfers()...)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Date < list[j].Date })
+	middleware.JSONResponse(w, list, http.StatusOK)
+}
+
+// CreateTransfer handles POST /api/transfers
+func (h *Handler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var t models.Transfer
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t.ID = uuid.New().String()
+	t.CreatedAt = time.Now().Format(time.RFC3339)
+	t.UpdatedAt = t.CreatedAt
+
+	// Validate transfer
+	if err := t.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkTransfer(t); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddTransfer(t); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveTransfers(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, t, http.StatusCreated)
+}
+
+// UpdateTransfer handles PUT /api/transfers/{id}
+func (h *Handler) UpdateTransfer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.Transfer
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var original models.Transfer
+	found := false
+	for _, t := range h.store.GetTransfers() {
+		if t.ID == id {
+			original = t
+			found = true
+			break
+		}
+	}
+	if !found {
+		middleware.ErrorResponse(w, "Transfer not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkTransfer(updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateTransfer(id, updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveTransfers(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, updates, http.StatusOK)
+}
+
+// DeleteTransfer handles DELETE /api/transfers/{id}
+func (h *Handler) DeleteTransfer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteTransfer(id); err != nil {
+		middleware.ErrorResponse(w, "Transfer not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveTransfers(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Transfer deleted successfully")
+}
+
+// book gathers the records that move money in accounts
+func (h *Handler) book() accounts.Book {
+	return accounts.Book{
+		Accounts:    h.store.GetAccounts(),
+		Expenses:    h.store.GetExpenses(),
+		Incomes:     h.store.GetIncomes(),
+		Investments: h.store.GetInvestments(),
+		Transfers:   h.store.GetTransfers(),
+	}
+}
+
+func (h *Handler) findAccount(id string) (models.Account, bool) {
+	for _, a := range h.store.GetAccounts() {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return models.Account{}, false
+}
+
+// checkAccount checks that a record's account exists; an empty ID is fine
+func (h *Handler) checkAccount(id string) error {
+	if id == "" {
+		return nil
+	}
+	if _, found := h.findAccount(id); !found {
+		return fmt.Errorf("account %s not found", id)
+	}
+	return nil
+}
+
+// checkLink checks that a UPI handle links to an existing account that is
+// not itself a linked handle
+func (h *Handler) checkLink(a models.Account) error {
+	if a.LinkedAccountID == "" {
+		return nil
+	}
+	linked, found := h.findAccount(a.LinkedAccountID)
+	if !found {
+		return fmt.Errorf("linked account %s not found", a.LinkedAccountID)
+	}
+	if linked.Kind == models.AccountUPI {
+		return fmt.Errorf("cannot link to another UPI handle")
+	}
+	return nil
+}
+
+// checkTransfer checks that both accounts of a transfer exist
+func (h *Handler) checkTransfer(t models.Transfer) error {
+	if err := h.checkAccount(t.FromAccountID); err != nil {
+		return err
+	}
+	return h.checkAccount(t.ToAccountID)
+}
+
+// dateParam reads an optional YYYY-MM-DD query parameter, writing the error
+// response when it is malformed
+func dateParam(w http.ResponseWriter, r *http.Request, name string, def time.Time) (time.Time, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, true
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		middleware.ErrorResponse(w, name+" must be in YYYY-MM-DD format", http.StatusBadRequest)
+		return t, false
+	}
+	return t, true
+}
+
+// AccountsHandler routes account requests
+func (h *Handler) AccountsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetAccounts(w, r)
+	case "POST":
+		h.CreateAccount(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// AccountHandler routes single account requests
+func (h *Handler) AccountHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetAccount(w, r)
+	case "PUT":
+		h.UpdateAccount(w, r)
+	case "DELETE":
+		h.DeleteAccount(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// TransfersHandler routes transfer requests
+func (h *Handler) TransfersHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetTransfers(w, r)
+	case "POST":
+		h.CreateTransfer(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// TransferHandler routes single transfer requests
+func (h *Handler) TransferHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		h.UpdateTransfer(w, r)
+	case "DELETE":
+		h.DeleteTransfer(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/auth"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+)
+
+// ----- AUTHENTICATION -----
+
+// credentials is the body of a login or first-user setup
+type credentials struct {
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	Member    string `json:"member"`    // Setup only
+	SetupCode string `json:"setupCode"` // Setup only: printed when the server starts
+}
+
+// loginResponse is the logged-in user and their session token, also set as
+// the session cookie
+type loginResponse struct {
+	User      models.User `json:"user"`
+	Token     string      `json:"token"`
+	ExpiresAt string      `json:"expiresAt"`
+}
+
+// publicUser is a user as sent to clients, without the password hash
+func publicUser(u models.User) models.User {
+	u.PasswordHash = ""
+	u.Role = roleOf(u)
+	return u
+}
+
+// startSession logs user in and sends the session back
+func (h *Handler) startSession(w http.ResponseWriter, r *http.Request, user models.User, status int) {
+	sess, token, err := h.auth.StartSession(user)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to start session: %v", err), http.StatusInternalServerError)
+		return
+	}
+	h.sendSession(w, r, user, sess, token, status)
+}
+
+// sendSession sets the session cookie and responds with the session
+func (h *Handler) sendSession(w http.ResponseWriter, r *http.Request, user models.User, sess models.Session, token string, status int) {
+	expires, _ := time.Parse(time.RFC3339, sess.ExpiresAt)
+	auth.SetCookie(w, r, token, expires)
+	middleware.JSONResponse(w, loginResponse{publicUser(user), token, sess.ExpiresAt}, status)
+}
+
+// Setup handles POST /api/auth/setup
+// Creates the first user, an owner, and logs them in. Only allowed while
+// there are no users, with the setup code the server printed at startup.
+func (h *Handler) Setup(w http.ResponseWriter, r *http.Request) {
+	var req credentials
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var user models.User
+	status := http.StatusInternalServerError
+	err := h.auth.Setup(req.SetupCode, func() error {
+		var err error
+		user, err = h.newUser(userRequest{Username: req.Username, Member: req.Member, Role: models.RoleOwner, Password: req.Password})
+		if err != nil {
+			status = http.StatusBadRequest
+			return fmt.Errorf("Validation error: %v", err)
+		}
+		if err := h.store.AddUser(user); err != nil {
+			return fmt.Errorf("Failed to add user: %v", err)
+		}
+		if err := h.store.SaveUsers(); err != nil {
+			return fmt.Errorf("Failed to save user: %v", err)
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, auth.ErrSetupDone):
+		middleware.ErrorResponse(w, "Setup is already done; log in instead", http.StatusConflict)
+		return
+	case errors.Is(err, auth.ErrInvalidSetupCode):
+		middleware.ErrorResponse(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		middleware.ErrorResponse(w, err.Error(), status)
+		return
+	}
+
+	h.startSession(w, r, user, http.StatusCreated)
+}
+
+// Login handles POST /api/auth/login
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req credentials
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, sess, token, err := h.auth.Login(req.Username, req.Password)
+	if errors.Is(err, auth.ErrInvalidLogin) {
+		middleware.ErrorResponse(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to start session: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.sendSession(w, r, user, sess, token, http.StatusOK)
+}
+
+// Logout handles POST /api/auth/logout
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.auth.Logout(r); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to end session: %v", err), http.StatusInternalServerError)
+		return
+	}
+	auth.ClearCookie(w, r)
+	middleware.SuccessMessage(w, "Logged out successfully")
+}
+
+// Me handles GET /api/auth/me
+func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
+	user, _ := middleware.CurrentUser(r)
+	middleware.JSONResponse(w, publicUser(user), http.StatusOK)
+}
+
+// passwordRequest is the body of a password change
+type passwordRequest struct {
+	Current string `json:"current"`
+	New     string `json:"new"`
+}
+
+// ChangePassword handles POST /api/auth/password
+// Any user can change their own password, which logs them out everywhere
+// else
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req passwordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := middleware.CurrentUser(r)
+	if !auth.CheckPassword(user.PasswordHash, req.Current) {
+		middleware.ErrorResponse(w, "Current password is wrong", http.StatusForbidden)
+		return
+	}
+	hash, err := auth.HashPassword(req.New)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	user.PasswordHash = hash
+	user.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := h.store.UpdateUser(user.ID, user); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveUsers(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.auth.EndSessions(user.ID, r); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to end sessions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Password changed successfully")
+}
+
+// ----- USERS -----
+
+// userRequest is the body of a user create or update. An update without a
+// password or role keeps the current one.
+type userRequest struct {
+	Username string `json:"username"`
+	Member   string `json:"member"`
+	Role     string `json:"role"` // Default: viewer
+	Password string `json:"password"`
+	Disabled bool   `json:"disabled"`
+}
+
+// newUser builds a user from a create request
+func (h *Handler) newUser(req userRequest) (models.User, error) {
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return models.User{}, err
+	}
+	if req.Role == "" {
+		req.Role = models.RoleViewer
+	}
+	user := models.User{
+		ID:           uuid.New().String(),
+		Username:     strings.TrimSpace(req.Username),
+		Member:       req.Member,
+		Role:         req.Role,
+		PasswordHash: hash,
+		Disabled:     req.Disabled,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+	}
+	user.UpdatedAt = user.CreatedAt
+	if err := user.Validate(); err != nil {
+		return models.User{}, err
+	}
+	return user, h.checkUser(user)
+}
+
+// checkUser checks a user is a family member without another login, and
+// that the username is free
+func (h *Handler) checkUser(user models.User) error {
+	if err := h.checkMembers(user.Member); err != nil {
+		return err
+	}
+	for _, u := range h.store.GetUsers() {
+		if u.ID == user.ID {
+			continue
+		}
+		if strings.EqualFold(u.Username, user.Username) {
+			return fmt.Errorf("username %s is taken", user.Username)
+		}
+		if u.Member == user.Member {
+			return fmt.Errorf("%s already has a login", user.Member)
+		}
+	}
+	return nil
+}
+
+// activeOwner reports whether u is an owner who can log in
+func activeOwner(u models.User) bool {
+	return roleOf(u) == models.RoleOwner && !u.Disabled
+}
+
+// otherOwners reports whether an owner other than id can log in
+func (h *Handler) otherOwners(id string) bool {
+	for _, u := range h.store.GetUsers() {
+		if u.ID != id && activeOwner(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUsers handles GET /api/users
+func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	users := []models.User{}
+	for _, u := range h.store.GetUsers() {
+		users = append(users, publicUser(u))
+	}
+	middleware.JSONResponse(w, users, http.StatusOK)
+}
+
+// CreateUser handles POST /api/users
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req userRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.newUser(req)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddUser(user); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveUsers(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, publicUser(user), http.StatusCreated)
+}
+
+// UpdateUser handles PUT /api/users/{id}
+// A new password, or disabling the user, logs them out everywhere else
+func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req userRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var original models.User
+	found := false
+	for _, u := range h.store.GetUsers() {
+		if u.ID == id {
+			original = u
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		middleware.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	updates := original
+	updates.Username = strings.TrimSpace(req.Username)
+	updates.Member = req.Member
+	updates.Disabled = req.Disabled
+	if req.Role != "" {
+		updates.Role = req.Role
+	}
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+	if req.Password != "" {
+		hash, err := auth.HashPassword(req.Password)
+		if err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+			return
+		}
+		updates.PasswordHash = hash
+	}
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkUser(updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if activeOwner(original) && !activeOwner(updates) && !h.otherOwners(id) {
+		middleware.ErrorResponse(w, "Validation error: cannot disable or demote the last owner", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateUser(id, updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveUsers(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if req.Password != "" || updates.Disabled {
+		if err := h.auth.EndSessions(id, r); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to end sessions: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	middleware.JSONResponse(w, publicUser(updates), http.StatusOK)
+}
+
+// DeleteUser handles DELETE /api/users/{id}
+// Ends the user's sessions and revokes their API tokens; the member's
+// records are kept
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	for _, u := range h.store.GetUsers() {
+		if u.ID == id && activeOwner(u) && !h.otherOwners(id) {
+			middleware.ErrorResponse(w, "Validation error: cannot delete the last owner", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.store.DeleteUser(id); err != nil {
+		middleware.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveUsers(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.auth.EndSessions(id, nil); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to end sessions: %v", err), http.StatusInternalServerError)
+		return
+	}
+	// Copied, as deleting shifts the stored slice
+	for _, tok := range append([]models.APIToken(nil), h.store.GetAPITokens()...) {
+		if tok.UserID != id {
+			continue
+		}
+		if err := h.store.DeleteAPIToken(tok.ID); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to revoke token: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := h.store.SaveAPITokens(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save tokens: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "User deleted successfully")
+}
+
+// UsersHandler routes user requests
+func (h *Handler) UsersHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetUsers(w, r)
+	case "POST":
+		h.CreateUser(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// UserHandler routes single user requests
+func (h *Handler) UserHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		h.UpdateUser(w, r)
+	case "DELETE":
+		h.DeleteUser(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/budgets"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/storage"
+)
+
+// ----- BUDGETS -----
+
+// GetBudgets handles GET /api/budgets
+func (h *Handler) GetBudgets(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.store.GetBudgets(), http.StatusOK)
+}
+
+// CreateBudget handles POST /api/budgets
+func (h *Handler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	var b models.Budget
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	b.ID = uuid.New().String()
+	b.CreatedAt = time.Now().Format(time.RFC3339)
+	b.UpdatedAt = b.CreatedAt
+	if b.Name == "" && len(b.Categories) > 0 {
+		b.Name = b.Categories[0]
+	}
+
+	// Validate budget
+	if err := b.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddBudget(b); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add budget: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveBudgets(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save budget: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, b, http.StatusCreated)
+}
+
+// UpdateBudget handles PUT /api/budgets/{id}
+func (h *Handler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.Budget
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var original models.Budget
+	found := false
+	for _, b := range h.store.GetBudgets() {
+		if b.ID == id {
+			original = b
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		middleware.ErrorResponse(w, "Budget not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+	if updates.Name == "" && len(updates.Categories) > 0 {
+		updates.Name = updates.Categories[0]
+	}
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateBudget(id, updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update budget: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveBudgets(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save budget: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, updates, http.StatusOK)
+}
+
+// DeleteBudget handles DELETE /api/budgets/{id}
+func (h *Handler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteBudget(id); err != nil {
+		middleware.ErrorResponse(w, "Budget not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveBudgets(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save budget: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Budget deleted successfully")
+}
+
+// BudgetStatus handles GET /api/budgets/status?date=YYYY-MM-DD
+// Spent vs limit, projected period-end spend and near/over flags per budget
+func (h *Handler) BudgetStatus(w http.ResponseWriter, r *http.Request) {
+	asOf := time.Now()
+	if s := r.URL.Query().Get("date"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			middleware.ErrorResponse(w, "date must be in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
+		asOf = t
+	}
+
+	list := h.store.GetBudgets()
+
+	// Only load expenses back to the earliest period (or rollover start) needed
+	from := asOf
+	for _, b := range list {
+		if start := budgets.DataStart(b, asOf); start.Before(from) {
+			from = start
+		}
+	}
+
+	expenses, _, err := h.store.QueryExpenses(storage.Query{From: from.Format("2006-01-02")})
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to load expenses: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, budgets.EvaluateAll(list, expenses, asOf), http.StatusOK)
+}
+
+// BudgetsHandler routes budget requests
+func (h *Handler) BudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetBudgets(w, r)
+	case "POST":
+		h.CreateBudget(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// BudgetHandler routes single budget requests
+func (h *Handler) BudgetHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		h.UpdateBudget(w, r)
+	case "DELETE":
+		h.DeleteBudget(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/chits"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+)
+
+// ----- CHIT FUNDS -----
+
+// GetChits handles GET /api/chits
+func (h *Handler) GetChits(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.store.GetChits(), http.StatusOK)
+}
+
+// CreateChit handles POST /api/chits
+func (h *Handler) CreateChit(w http.ResponseWriter, r *http.Request) {
+	var c models.Chit
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.ID = uuid.New().String()
+	c.CreatedAt = time.Now().Format(time.RFC3339)
+	c.UpdatedAt = c.CreatedAt
+	if c.Auctions == nil {
+		c.Auctions = []models.ChitAuction{}
+	}
+
+	// Validate chit
+	if err := c.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddChit(c); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add chit: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveChits(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save chit: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, c, http.StatusCreated)
+}
+
+// UpdateChit handles PUT /api/chits/{id}
+// Auction records are kept from the stored chit when none are sent
+func (h *Handler) UpdateChit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.Chit
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	original, found := h.findChit(id)
+	if !found {
+		middleware.ErrorResponse(w, "Chit not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+	if updates.Auctions == nil {
+		updates.Auctions = original.Auctions
+	}
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	h.saveChit(w, updates, http.StatusOK)
+}
+
+// DeleteChit handles DELETE /api/chits/{id}
+func (h *Handler) DeleteChit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteChit(id); err != nil {
+		middleware.ErrorResponse(w, "Chit not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveChits(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save chit: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Chit deleted successfully")
+}
+
+// GetChitStatus handles GET /api/chits/{id}
+// Total paid, remaining liability, next instalment and effective return
+func (h *Handler) GetChitStatus(w http.ResponseWriter, r *http.Request) {
+	c, found := h.findChit(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Chit not found", http.StatusNotFound)
+		return
+	}
+	middleware.JSONResponse(w, chits.Compute(c, today()), http.StatusOK)
+}
+
+// ChitsStatus handles GET /api/chits/status
+func (h *Handler) ChitsStatus(w http.ResponseWriter, r *http.Request) {
+	now := today()
+	list := []chits.Status{}
+	for _, c := range h.store.GetChits() {
+		list = append(list, chits.Compute(c, now))
+	}
+	middleware.JSONResponse(w, list, http.StatusOK)
+}
+
+// UpcomingInstalments handles GET /api/chits/upcoming?days=30
+// Unrecorded instalments across all chits, overdue ones first
+func (h *Handler) UpcomingInstalments(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if s := r.URL.Query().Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > 366 {
+			middleware.ErrorResponse(w, "days must be an integer between 0 and 366", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	now := today()
+	list := []chits.Instalment{}
+	for _, c := range h.store.GetChits() {
+		list = append(list, chits.Upcoming(c, now, now.AddDate(0, 0, days))...)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Date < list[j].Date })
+	middleware.JSONResponse(w, list, http.StatusOK)
+}
+
+// RecordChitAuction handles POST /api/chits/{id}/auctions
+// Adds or replaces one month's auction record. Dividend and net paid may be
+// left at 0 to derive them from the bid; "won": true marks the month as ours.
+func (h *Handler) RecordChitAuction(w http.ResponseWriter, r *http.Request) {
+	c, found := h.findChit(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Chit not found", http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		models.ChitAuction
+		Won         bool    `json:"won"`
+		PrizeAmount float64 `json:"prizeAmount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	auctions := make([]models.ChitAuction, 0, len(c.Auctions)+1)
+	for _, a := range c.Auctions {
+		if a.Month != body.Month {
+			auctions = append(auctions, a)
+		}
+	}
+	c.Auctions = append(auctions, body.ChitAuction)
+	sort.Slice(c.Auctions, func(i, j int) bool { return c.Auctions[i].Month < c.Auctions[j].Month })
+	if body.Won {
+		c.WonMonth = body.Month
+		c.PrizeAmount = body.PrizeAmount
+	}
+	c.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := c.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	h.saveChit(w, c, http.StatusOK)
+}
+
+// saveChit stores an updated chit and writes it back
+func (h *Handler) saveChit(w http.ResponseWriter, c models.Chit, status int) {
+	if err := h.store.UpdateChit(c.ID, c); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update chit: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveChits(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save chit: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, c, status)
+}
+
+func (h *Handler) findChit(id string) (models.Chit, bool) {
+	for _, c := range h.store.GetChits() {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return models.Chit{}, false
+}
+
+// today is the current date at midnight UTC
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ChitsHandler routes chit requests
+func (h *Handler) ChitsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetChits(w, r)
+	case "POST":
+		h.CreateChit(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ChitHandler routes single chit requests
+func (h *Handler) ChitHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetChitStatus(w, r)
+	case "PUT":
+		h.UpdateChit(w, r)
+	case "DELETE":
+		h.DeleteChit(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
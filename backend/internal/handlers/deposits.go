@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/deposits"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+)
+
+// ----- DEPOSITS -----
+
+// InvestmentDeposit handles GET /api/investments/{id}/deposit
+// Accrued value, maturity value, interest, TDS and payouts of an FD or RD
+func (h *Handler) InvestmentDeposit(w http.ResponseWriter, r *http.Request) {
+	inv, found := h.findInvestment(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Investment not found", http.StatusNotFound)
+		return
+	}
+	if inv.Deposit == nil {
+		middleware.ErrorResponse(w, "Investment has no deposit terms", http.StatusBadRequest)
+		return
+	}
+	middleware.JSONResponse(w, deposits.Project(inv, today()), http.StatusOK)
+}
+
+// DepositMaturities handles GET /api/deposits/maturities?days=90
+// Open deposits maturing in the next N days, including matured ones not yet closed
+func (h *Handler) DepositMaturities(w http.ResponseWriter, r *http.Request) {
+	days := 90
+	if s := r.URL.Query().Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > 3660 {
+			middleware.ErrorResponse(w, "days must be an integer between 0 and 3660", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	middleware.JSONResponse(w, h.deposits.Maturities(days), http.StatusOK)
+}
+
+// AccrueDeposits handles POST /api/deposits/accrue
+// Accrues deposits and posts due interest now instead of waiting for the scheduler
+func (h *Handler) AccrueDeposits(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.deposits.Run(), http.StatusOK)
+}
+
+// normalizeDeposit fills in the deposit defaults: the kind from the
+// investment type, quarterly compounding and cumulative payout
+func normalizeDeposit(inv *models.Investment) {
+	d := inv.Deposit
+	if d == nil {
+		return
+	}
+	if d.Kind == "" {
+		d.Kind = models.DepositFD
+		if strings.EqualFold(inv.Type, "RD") {
+			d.Kind = models.DepositRD
+		}
+	}
+	if d.Compounding == "" {
+		d.Compounding = models.FrequencyQuarterly
+	}
+	if d.Payout == "" {
+		d.Payout = models.PayoutCumulative
+	}
+}
+
+// accrueDeposit values a deposit as it is saved
+func accrueDeposit(inv *models.Investment) error {
+	normalizeDeposit(inv)
+	if inv.Deposit == nil {
+		return nil
+	}
+	if err := inv.Deposit.Validate(); err != nil {
+		return err
+	}
+	return deposits.Accrue(inv, today())
+}
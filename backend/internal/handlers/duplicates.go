@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finance-tracker/internal/duplicates"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+)
+
+// ----- DUPLICATES -----
+
+// GetDuplicates handles GET /api/duplicates?kind=expense&threshold=0.75
+// Groups of expenses and incomes suspected to be the same transaction.
+// Takes the list filters (from, to, accountId, ...) to narrow the records.
+func (h *Handler) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Limit, q.Offset = 0, 0
+
+	threshold := duplicates.DefaultThreshold
+	if s := r.URL.Query().Get("threshold"); s != "" {
+		if threshold, err = strconv.ParseFloat(s, 64); err != nil || threshold <= 0 || threshold > 1 {
+			middleware.ErrorResponse(w, "threshold must be a number above 0 and at most 1", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var expenses []models.Expense
+	var incomes []models.Income
+	kind := r.URL.Query().Get("kind")
+	switch kind {
+	case "", models.KindExpense, models.KindIncome:
+	default:
+		middleware.ErrorResponse(w, "kind must be expense or income", http.StatusBadRequest)
+		return
+	}
+	if kind != models.KindIncome {
+		if expenses, _, err = h.store.QueryExpenses(q); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to query expenses: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if kind != models.KindExpense {
+		if incomes, _, err = h.store.QueryIncomes(q); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to query incomes: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	groups := duplicates.Groups(duplicates.Records(expenses, incomes), threshold)
+	if groups == nil {
+		groups = []duplicates.Group{}
+	}
+	middleware.JSONResponse(w, groups, http.StatusOK)
+}
+
+// mergeRequest names the record to keep and those folded into it
+type mergeRequest struct {
+	Kind     string   `json:"kind"`
+	KeepID   string   `json:"keepId"`
+	MergeIDs []string `json:"mergeIds"`
+	MergedBy string   `json:"mergedBy"` // Ignored when logged in
+}
+
+// MergeDuplicates handles POST /api/duplicates/merge
+// Deletes the merged records after filling in what the kept one lacks
+// (account, FITID, loan instalment, payment method) from them, and records
+// the merge in the history
+func (h *Handler) MergeDuplicates(w http.ResponseWriter, r *http.Request) {
+	var req mergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.KeepID == "" || len(req.MergeIDs) == 0 {
+		middleware.ErrorResponse(w, "keepId and mergeIds are required", http.StatusBadRequest)
+		return
+	}
+	seen := map[string]bool{req.KeepID: true}
+	for _, id := range req.MergeIDs {
+		if seen[id] {
+			middleware.ErrorResponse(w, fmt.Sprintf("Record %s is named twice", id), http.StatusBadRequest)
+			return
+		}
+		seen[id] = true
+	}
+
+	// Who merged is the logged-in member, when there is one
+	if user, ok := middleware.CurrentUser(r); ok {
+		req.MergedBy = user.Member
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	m := models.Merge{
+		ID:        uuid.New().String(),
+		Kind:      req.Kind,
+		KeptID:    req.KeepID,
+		MergedBy:  req.MergedBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var kept interface{}
+	switch req.Kind {
+	case models.KindExpense:
+		exp, ok := h.mergeExpenses(w, req, &m)
+		if !ok {
+			return
+		}
+		kept = exp
+	case models.KindIncome:
+		inc, ok := h.mergeIncomes(w, req, &m)
+		if !ok {
+			return
+		}
+		kept = inc
+	default:
+		middleware.ErrorResponse(w, "kind must be expense or income", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddMerge(m); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add merge: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveMerges(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save merge: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, map[string]interface{}{"kept": kept, "merge": m}, http.StatusCreated)
+}
+
+// mergeExpenses folds the expenses of req into the kept one, adding them
+// to m as they were
+func (h *Handler) mergeExpenses(w http.ResponseWriter, req mergeRequest, m *models.Merge) (models.Expense, bool) {
+	byID := map[string]models.Expense{}
+	for _, exp := range h.store.GetExpenses() {
+		byID[exp.ID] = exp
+	}
+	kept, found := byID[req.KeepID]
+	if !found {
+		middleware.ErrorResponse(w, "Expense not found", http.StatusNotFound)
+		return kept, false
+	}
+	for _, id := range req.MergeIDs {
+		exp, found := byID[id]
+		if !found {
+			middleware.ErrorResponse(w, fmt.Sprintf("Expense %s not found", id), http.StatusNotFound)
+			return kept, false
+		}
+		if kept.LoanID != "" && exp.LoanID != "" && kept.LoanID != exp.LoanID {
+			middleware.ErrorResponse(w, "Expenses paying different loans cannot be merged", http.StatusBadRequest)
+			return kept, false
+		}
+		m.Expenses = append(m.Expenses, exp)
+	}
+
+	for _, exp := range m.Expenses {
+		if kept.AccountID == "" {
+			kept.AccountID = exp.AccountID
+		}
+		if kept.FITID == "" {
+			kept.FITID = exp.FITID
+		}
+		if kept.LoanID == "" {
+			kept.LoanID, kept.Instalment = exp.LoanID, exp.Instalment
+		}
+		if kept.PaymentMethod == "" {
+			kept.PaymentMethod = exp.PaymentMethod
+		}
+	}
+	kept.UpdatedAt = m.UpdatedAt
+
+	if err := h.store.UpdateExpense(kept.ID, kept); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update expense: %v", err), http.StatusInternalServerError)
+		return kept, false
+	}
+	for _, exp := range m.Expenses {
+		if err := h.store.DeleteExpense(exp.ID); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to delete expense: %v", err), http.StatusInternalServerError)
+			return kept, false
+		}
+	}
+	if err := h.store.SaveExpenses(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save expenses: %v", err), http.StatusInternalServerError)
+		return kept, false
+	}
+	return kept, true
+}
+
+// mergeIncomes folds the incomes of req into the kept one, adding them to
+// m as they were
+func (h *Handler) mergeIncomes(w http.ResponseWriter, req mergeRequest, m *models.Merge) (models.Income, bool) {
+	byID := map[string]models.Income{}
+	for _, inc := range h.store.GetIncomes() {
+		byID[inc.ID] = inc
+	}
+	kept, found := byID[req.KeepID]
+	if !found {
+		middleware.ErrorResponse(w, "Income not found", http.StatusNotFound)
+		return kept, false
+	}
+	for _, id := range req.MergeIDs {
+		inc, found := byID[id]
+		if !found {
+			middleware.ErrorResponse(w, fmt.Sprintf("Income %s not found", id), http.StatusNotFound)
+			return kept, false
+		}
+		m.Incomes = append(m.Incomes, inc)
+	}
+
+	for _, inc := range m.Incomes {
+		if kept.AccountID == "" {
+			kept.AccountID = inc.AccountID
+		}
+		if kept.FITID == "" {
+			kept.FITID = inc.FITID
+		}
+		if kept.PaymentMethod == "" {
+			kept.PaymentMethod = inc.PaymentMethod
+		}
+	}
+	kept.UpdatedAt = m.UpdatedAt
+
+	if err := h.store.UpdateIncome(kept.ID, kept); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update income: %v", err), http.StatusInternalServerError)
+		return kept, false
+	}
+	for _, inc := range m.Incomes {
+		if err := h.store.DeleteIncome(inc.ID); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to delete income: %v", err), http.StatusInternalServerError)
+			return kept, false
+		}
+	}
+	if err := h.store.SaveIncomes(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save incomes: %v", err), http.StatusInternalServerError)
+		return kept, false
+	}
+	return kept, true
+}
+
+// GetMerges handles GET /api/duplicates/merges
+// The history of merges, latest first
+func (h *Handler) GetMerges(w http.ResponseWriter, r *http.Request) {
+	merges := h.store.GetMerges()
+	history := make([]models.Merge, 0, len(merges))
+	for i := len(merges) - 1; i >= 0; i-- {
+		history = append(history, merges[i])
+	}
+	middleware.JSONResponse(w, history, http.StatusOK)
+}
@@ -1,27 +1,52 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
+	"finance-tracker/internal/auth"
+	"finance-tracker/internal/deposits"
 	"finance-tracker/internal/middleware"
 	"finance-tracker/internal/models"
+	"finance-tracker/internal/navprovider"
+	"finance-tracker/internal/recurring"
 	"finance-tracker/internal/storage"
 )
 
 // Handler wraps the storage and provides HTTP handlers
 type Handler struct {
-	store storage.Storage
+	store     storage.Storage
+	recurring *recurring.Engine
+	nav       *navprovider.Refresher
+	deposits  *deposits.Engine
+	auth      *auth.Authenticator
 }
 
-// NewHandler creates a new handler with the given storage
-func NewHandler(store storage.Storage) *Handler {
-	return &Handler{store: store}
+// Services are long-lived components the handlers share with the background
+// jobs started in cmd/server
+type Services struct {
+	Recurring *recurring.Engine
+	NAV       *navprovider.Refresher
+	Deposits  *deposits.Engine
+	Auth      *auth.Authenticator
+}
+
+// NewHandler creates a new handler with the given storage and services
+func NewHandler(store storage.Storage, svc Services) *Handler {
+	return &Handler{
+		store:     store,
+		recurring: svc.Recurring,
+		nav:       svc.NAV,
+		deposits:  svc.Deposits,
+		auth:      svc.Auth,
+	}
 }
 
 // ----- HEALTH CHECK -----
@@ -40,9 +65,28 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 // ----- INVESTMENTS -----
 
 // GetInvestments handles GET /api/investments
+// Supports filtering, sorting and pagination (see parseListQuery)
 func (h *Handler) GetInvestments(w http.ResponseWriter, r *http.Request) {
-	investments := h.store.GetInvestments()
-	middleware.JSONResponse(w, investments, http.StatusOK)
+	// Investments have no member, payment method or account to filter on
+	for _, name := range []string{"addedBy", "paymentMethod", "accountId"} {
+		if r.URL.Query().Has(name) {
+			middleware.ErrorResponse(w, fmt.Sprintf("%s does not apply to investments", name), http.StatusBadRequest)
+			return
+		}
+	}
+
+	q, err := parseListQuery(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	investments, total, err := h.store.QueryInvestments(q)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to query investments: %v", err), http.StatusInternalServerError)
+		return
+	}
+	middleware.PagedResponse(w, investments, pageMeta(q, len(investments), total))
 }
 
 // CreateInvestment handles POST /api/investments
@@ -57,6 +101,17 @@ func (h *Handler) CreateInvestment(w http.ResponseWriter, r *http.Request) {
 	inv.CreatedAt = time.Now().Format(time.RFC3339)
 	inv.UpdatedAt = inv.CreatedAt
 
+	// Flat Invested/Units/Date become the opening transaction
+	inv.EnsureTransactions()
+	if err := h.deriveHolding(&inv); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := accrueDeposit(&inv); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	// Validate investment
 	if err := inv.Validate(); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
@@ -108,6 +163,24 @@ func (h *Handler) UpdateInvestment(w http.ResponseWriter, r *http.Request) {
 	updates.CreatedAt = original.CreatedAt
 	updates.UpdatedAt = time.Now().Format(time.RFC3339)
 
+	// Clients that don't know about deposits keep an FD's terms, and its tags
+	if updates.Deposit == nil {
+		updates.Deposit = original.Deposit
+	}
+	if updates.Tags == nil {
+		updates.Tags = original.Tags
+	}
+
+	applyFlatEdit(&updates, original)
+	if err := h.deriveHolding(&updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := accrueDeposit(&updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	// Validate before updating
 	if err := updates.Validate(); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
@@ -124,6 +197,14 @@ func (h *Handler) UpdateInvestment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only a changed value says anything about today's NAV
+	if updates.Current != original.Current || updates.Units != original.Units {
+		if err := h.recordPrice(updates, "manual"); err != nil {
+			middleware.ErrorResponse(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	middleware.JSONResponse(w, updates, http.StatusOK)
 }
 
@@ -148,9 +229,25 @@ func (h *Handler) DeleteInvestment(w http.ResponseWriter, r *http.Request) {
 // ----- EXPENSES -----
 
 // GetExpenses handles GET /api/expenses
+// Supports filtering, sorting and pagination (see parseListQuery)
 func (h *Handler) GetExpenses(w http.ResponseWriter, r *http.Request) {
-	expenses := h.store.GetExpenses()
-	middleware.JSONResponse(w, expenses, http.StatusOK)
+	q, err := parseListQuery(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Contributors see only their own
+	if member, own := ownMember(r); own {
+		q.AddedBy = member
+	}
+
+	expenses, total, err := h.store.QueryExpenses(q)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to query expenses: %v", err), http.StatusInternalServerError)
+		return
+	}
+	middleware.PagedResponse(w, expenses, pageMeta(q, len(expenses), total))
 }
 
 // CreateExpense handles POST /api/expenses
@@ -161,15 +258,41 @@ func (h *Handler) CreateExpense(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	member, own := ownMember(r)
+	if own && exp.AddedBy == "" {
+		exp.AddedBy = member
+	}
+
 	exp.ID = uuid.New().String()
 	exp.CreatedAt = time.Now().Format(time.RFC3339)
 	exp.UpdatedAt = exp.CreatedAt
+	// Rules fill in what the client left out
+	h.ruleEngine().Expense(&exp, true)
+
+	if own {
+		if err := checkOwnExpense(exp, member); err != nil {
+			middleware.ErrorResponse(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+			return
+		}
+	}
 
 	// Validate expense
 	if err := exp.Validate(); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
+	if err := h.linkEMI(&exp); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkAccount(exp.AccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkSharing(&exp); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	if err := h.store.AddExpense(exp); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add expense: %v", err), http.StatusInternalServerError)
@@ -210,16 +333,63 @@ func (h *Handler) UpdateExpense(w http.ResponseWriter, r *http.Request) {
 		middleware.ErrorResponse(w, "Expense not found", http.StatusNotFound)
 		return
 	}
+	member, own := ownMember(r)
+	if own && original.AddedBy != member {
+		middleware.ErrorResponse(w, "Forbidden: contributors can only change their own expenses", http.StatusForbidden)
+		return
+	}
 
 	updates.ID = id
 	updates.CreatedAt = original.CreatedAt
 	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+	updates.FITID = original.FITID
+	// Clients that don't know about loans keep an EMI's link,
+	if updates.LoanID == "" {
+		updates.LoanID = original.LoanID
+		updates.Instalment = original.Instalment
+	}
+	// and an expense's account and tags
+	if updates.AccountID == "" {
+		updates.AccountID = original.AccountID
+	}
+	if updates.Tags == nil {
+		updates.Tags = original.Tags
+	}
+	// Split lines and shares are kept unless given; an empty list removes
+	// them
+	if updates.Splits == nil {
+		updates.Splits = original.Splits
+	}
+	if updates.SharedAmong == nil {
+		updates.SharedAmong, updates.ShareMode = original.SharedAmong, original.ShareMode
+		if updates.PaidBy == "" {
+			updates.PaidBy = original.PaidBy
+		}
+	}
+	if own {
+		if err := checkOwnExpense(updates, member); err != nil {
+			middleware.ErrorResponse(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+			return
+		}
+	}
 
 	// Validate before updating
 	if err := updates.Validate(); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
+	if err := h.linkEMI(&updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkAccount(updates.AccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkSharing(&updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	if err := h.store.UpdateExpense(id, updates); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update expense: %v", err), http.StatusInternalServerError)
@@ -234,11 +404,37 @@ func (h *Handler) UpdateExpense(w http.ResponseWriter, r *http.Request) {
 	middleware.JSONResponse(w, updates, http.StatusOK)
 }
 
+// GetExpense handles GET /api/expenses/{id}
+func (h *Handler) GetExpense(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	for _, exp := range h.store.GetExpenses() {
+		if exp.ID != id {
+			continue
+		}
+		if member, own := ownMember(r); own && exp.AddedBy != member {
+			middleware.ErrorResponse(w, "Forbidden: contributors can only see their own expenses", http.StatusForbidden)
+			return
+		}
+		middleware.JSONResponse(w, exp, http.StatusOK)
+		return
+	}
+	middleware.ErrorResponse(w, "Expense not found", http.StatusNotFound)
+}
+
 // DeleteExpense handles DELETE /api/expenses/{id}
 func (h *Handler) DeleteExpense(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if member, own := ownMember(r); own {
+		for _, exp := range h.store.GetExpenses() {
+			if exp.ID == id && exp.AddedBy != member {
+				middleware.ErrorResponse(w, "Forbidden: contributors can only delete their own expenses", http.StatusForbidden)
+				return
+			}
+		}
+	}
+
 	if err := h.store.DeleteExpense(id); err != nil {
 		middleware.ErrorResponse(w, "Expense not found", http.StatusNotFound)
 		return
@@ -255,9 +451,25 @@ func (h *Handler) DeleteExpense(w http.ResponseWriter, r *http.Request) {
 // ----- INCOMES -----
 
 // GetIncomes handles GET /api/incomes
+// Supports filtering, sorting and pagination (see parseListQuery)
 func (h *Handler) GetIncomes(w http.ResponseWriter, r *http.Request) {
-	incomes := h.store.GetIncomes()
-	middleware.JSONResponse(w, incomes, http.StatusOK)
+	q, err := parseListQuery(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Contributors see only their own
+	if member, own := ownMember(r); own {
+		q.AddedBy = member
+	}
+
+	incomes, total, err := h.store.QueryIncomes(q)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to query incomes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	middleware.PagedResponse(w, incomes, pageMeta(q, len(incomes), total))
 }
 
 // CreateIncome handles POST /api/incomes
@@ -268,15 +480,31 @@ func (h *Handler) CreateIncome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if member, own := ownMember(r); own {
+		if inc.AddedBy == "" {
+			inc.AddedBy = member
+		}
+		if inc.AddedBy != member {
+			middleware.ErrorResponse(w, "Forbidden: contributors can only add their own incomes", http.StatusForbidden)
+			return
+		}
+	}
+
 	inc.ID = uuid.New().String()
 	inc.CreatedAt = time.Now().Format(time.RFC3339)
 	inc.UpdatedAt = inc.CreatedAt
+	// Rules fill in what the client left out
+	h.ruleEngine().Income(&inc, true)
 
 	// Validate income
 	if err := inc.Validate(); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
+	if err := h.checkAccount(inc.AccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	if err := h.store.AddIncome(inc); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add income: %v", err), http.StatusInternalServerError)
@@ -317,16 +545,32 @@ func (h *Handler) UpdateIncome(w http.ResponseWriter, r *http.Request) {
 		middleware.ErrorResponse(w, "Income not found", http.StatusNotFound)
 		return
 	}
+	if member, own := ownMember(r); own && (original.AddedBy != member || updates.AddedBy != member) {
+		middleware.ErrorResponse(w, "Forbidden: contributors can only change their own incomes", http.StatusForbidden)
+		return
+	}
 
 	updates.ID = id
 	updates.CreatedAt = original.CreatedAt
 	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+	updates.FITID = original.FITID
+	// Clients that don't know about accounts and tags keep the income's
+	if updates.AccountID == "" {
+		updates.AccountID = original.AccountID
+	}
+	if updates.Tags == nil {
+		updates.Tags = original.Tags
+	}
 
 	// Validate before updating
 	if err := updates.Validate(); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
+	if err := h.checkAccount(updates.AccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	if err := h.store.UpdateIncome(id, updates); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update income: %v", err), http.StatusInternalServerError)
@@ -341,11 +585,37 @@ func (h *Handler) UpdateIncome(w http.ResponseWriter, r *http.Request) {
 	middleware.JSONResponse(w, updates, http.StatusOK)
 }
 
+// GetIncome handles GET /api/incomes/{id}
+func (h *Handler) GetIncome(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	for _, inc := range h.store.GetIncomes() {
+		if inc.ID != id {
+			continue
+		}
+		if member, own := ownMember(r); own && inc.AddedBy != member {
+			middleware.ErrorResponse(w, "Forbidden: contributors can only see their own incomes", http.StatusForbidden)
+			return
+		}
+		middleware.JSONResponse(w, inc, http.StatusOK)
+		return
+	}
+	middleware.ErrorResponse(w, "Income not found", http.StatusNotFound)
+}
+
 // DeleteIncome handles DELETE /api/incomes/{id}
 func (h *Handler) DeleteIncome(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if member, own := ownMember(r); own {
+		for _, inc := range h.store.GetIncomes() {
+			if inc.ID == id && inc.AddedBy != member {
+				middleware.ErrorResponse(w, "Forbidden: contributors can only delete their own incomes", http.StatusForbidden)
+				return
+			}
+		}
+	}
+
 	if err := h.store.DeleteIncome(id); err != nil {
 		middleware.ErrorResponse(w, "Income not found", http.StatusNotFound)
 		return
@@ -364,6 +634,9 @@ func (h *Handler) DeleteIncome(w http.ResponseWriter, r *http.Request) {
 // GetSettings handles GET /api/settings
 func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
 	settings := h.store.GetSettings()
+	if settings.Tags == nil {
+		settings.Tags = []string{}
+	}
 	middleware.JSONResponse(w, settings, http.StatusOK)
 }
 
@@ -374,6 +647,10 @@ func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	// Clients that don't know about tags keep the registry
+	if settings.Tags == nil {
+		settings.Tags = h.store.GetSettings().Tags
+	}
 
 	if err := h.store.UpdateSettings(settings); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update settings: %v", err), http.StatusInternalServerError)
@@ -416,6 +693,11 @@ func (h *Handler) ImportData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.store.SaveIncomes(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save incomes: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	if err := h.store.SaveExpenses(); err != nil {
 		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save expenses: %v", err), http.StatusInternalServerError)
 		return
@@ -426,6 +708,61 @@ func (h *Handler) ImportData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.store.SaveBudgets(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save budgets: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveRecurringRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save recurring rules: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SavePrices(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save prices: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveChits(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save chits: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveLoans(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save loans: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveAccounts(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save accounts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveTransfers(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save transfers: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveStatementProfiles(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save statement profiles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveMerges(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save merges: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save rules: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveSettlements(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save settlements: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	middleware.SuccessMessage(w, "Data imported successfully")
 }
 
@@ -470,6 +807,8 @@ func (h *Handler) IncomesHandler(w http.ResponseWriter, r *http.Request) {
 // IncomeHandler routes single income requests
 func (h *Handler) IncomeHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
+	case "GET":
+		h.GetIncome(w, r)
 	case "PUT":
 		h.UpdateIncome(w, r)
 	case "DELETE":
@@ -494,6 +833,8 @@ func (h *Handler) ExpensesHandler(w http.ResponseWriter, r *http.Request) {
 // ExpenseHandler routes single expense requests
 func (h *Handler) ExpenseHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
+	case "GET":
+		h.GetExpense(w, r)
 	case "PUT":
 		h.UpdateExpense(w, r)
 	case "DELETE":
@@ -516,13 +857,36 @@ func (h *Handler) SettingsHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // RefreshNAV handles POST /api/investments/refresh-nav
-// This endpoint will be called from frontend to update all mutual fund NAVs
+// With an empty body the server fetches the latest NAVs itself and recomputes
+// every investment with a scheme code. Older frontends post the investments
+// they refreshed in the browser instead, which are stored as sent.
 func (h *Handler) RefreshNAV(w http.ResponseWriter, r *http.Request) {
-	// Frontend will handle the NAV fetching and send updated investments
-	// This is a placeholder for future server-side NAV refresh if needed
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		middleware.ErrorResponse(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		result, err := h.nav.Refresh(r.Context())
+		if err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("NAV refresh failed: %v", err), http.StatusBadGateway)
+			return
+		}
+		response := map[string]interface{}{
+			"message":  "NAV refresh completed",
+			"provider": result.Provider,
+			"updated":  result.Updated,
+			"total":    result.Total,
+			"missing":  result.Missing,
+			"errors":   result.Errors,
+		}
+		middleware.JSONResponse(w, response, http.StatusOK)
+		return
+	}
 
 	var updates []models.Investment
-	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+	if err := json.Unmarshal(body, &updates); err != nil {
 		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -534,11 +898,24 @@ func (h *Handler) RefreshNAV(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		original, ok := h.findInvestment(inv.ID)
+		if !ok {
+			continue
+		}
+		inv.CreatedAt = original.CreatedAt
 		inv.UpdatedAt = time.Now().Format(time.RFC3339)
+		applyFlatEdit(&inv, original)
+		if err := h.deriveHolding(&inv); err != nil {
+			continue
+		}
 		if err := h.store.UpdateInvestment(inv.ID, inv); err != nil {
 			// Log error but continue with other investments
 			continue
 		}
+		if err := h.recordPrice(inv, "client"); err != nil {
+			middleware.ErrorResponse(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 		updatedCount++
 	}
 
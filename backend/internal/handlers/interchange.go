@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finance-tracker/internal/accounts"
+	"finance-tracker/internal/duplicates"
+	"finance-tracker/internal/interchange"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+)
+
+// ----- OFX / QIF -----
+
+// interchangeResult reports what an OFX or QIF import recorded
+type interchangeResult struct {
+	Expenses   int `json:"expenses"`
+	Incomes    int `json:"incomes"`
+	Duplicates int `json:"duplicates"` // already imported (same FITID and account)
+	// Likely duplicates of records already there, not recorded unless
+	// allowDuplicates is set
+	Warnings []duplicateWarning `json:"warnings"`
+}
+
+// duplicateWarning is a record held back from an import and the record it
+// likely duplicates
+type duplicateWarning struct {
+	Record      duplicates.Record `json:"record"`
+	DuplicateOf duplicates.Match  `json:"duplicateOf"`
+}
+
+// ImportOFX handles POST /api/import/ofx
+// A multipart form with the OFX or QFX `file` and optional `accountId`,
+// `addedBy` (default: the account's member) and `allowDuplicates`
+func (h *Handler) ImportOFX(w http.ResponseWriter, r *http.Request) {
+	data, _, ok := readUpload(w, r)
+	if !ok {
+		return
+	}
+	s, err := interchange.ReadOFX(bytes.NewReader(data))
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.importStatement(w, r, s)
+}
+
+// ImportQIF handles POST /api/import/qif
+// As ImportOFX, plus `dateFormat` (e.g. mm/dd/yyyy; default: day first)
+func (h *Handler) ImportQIF(w http.ResponseWriter, r *http.Request) {
+	data, _, ok := readUpload(w, r)
+	if !ok {
+		return
+	}
+	s, err := interchange.ReadQIF(bytes.NewReader(data), r.FormValue("dateFormat"))
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.importStatement(w, r, s)
+}
+
+// importStatement records the expenses and incomes read from a file after
+// running the rules on them, leaving out those imported before and, with a
+// warning, those likely recorded another way. Nothing is recorded if any
+// is invalid.
+func (h *Handler) importStatement(w http.ResponseWriter, r *http.Request, s interchange.Statement) {
+	accountID, addedBy := r.FormValue("accountId"), r.FormValue("addedBy")
+	if err := h.checkAccount(accountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if a, found := h.findAccount(accountID); found && addedBy == "" {
+		addedBy = a.Member
+	}
+
+	existingExpenses, existingIncomes := h.store.GetExpenses(), h.store.GetIncomes()
+	imported := map[string]bool{}
+	for _, exp := range existingExpenses {
+		if exp.FITID != "" {
+			imported[exp.AccountID+"|"+exp.FITID] = true
+		}
+	}
+	for _, inc := range existingIncomes {
+		if inc.FITID != "" {
+			imported[inc.AccountID+"|"+inc.FITID] = true
+		}
+	}
+	isNew := func(fitID string) bool {
+		if fitID == "" {
+			return true
+		}
+		key := accountID + "|" + fitID
+		seen := imported[key]
+		imported[key] = true
+		return !seen
+	}
+
+	engine := h.ruleEngine()
+	allowDuplicates := r.FormValue("allowDuplicates") == "true"
+	recorded := duplicates.Records(existingExpenses, existingIncomes)
+	result := interchangeResult{Warnings: []duplicateWarning{}}
+	likelyDuplicate := func(rec duplicates.Record) bool {
+		if allowDuplicates {
+			return false
+		}
+		matches := duplicates.Matches(rec, recorded, duplicates.DefaultThreshold)
+		if len(matches) == 0 {
+			return false
+		}
+		result.Warnings = append(result.Warnings, duplicateWarning{rec, matches[0]})
+		return true
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	var expenses []models.Expense
+	var incomes []models.Income
+	for _, exp := range s.Expenses {
+		if !isNew(exp.FITID) {
+			result.Duplicates++
+			continue
+		}
+		exp.AddedBy, exp.AccountID = addedBy, accountID
+		engine.Expense(&exp, false)
+		if likelyDuplicate(duplicates.FromExpense(exp)) {
+			continue
+		}
+		exp.ID = uuid.New().String()
+		exp.CreatedAt, exp.UpdatedAt = now, now
+		if err := exp.Validate(); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %s %s: %v", exp.Date, exp.Desc, err), http.StatusBadRequest)
+			return
+		}
+		expenses = append(expenses, exp)
+	}
+	for _, inc := range s.Incomes {
+		if !isNew(inc.FITID) {
+			result.Duplicates++
+			continue
+		}
+		inc.AddedBy, inc.AccountID = addedBy, accountID
+		engine.Income(&inc, false)
+		if likelyDuplicate(duplicates.FromIncome(inc)) {
+			continue
+		}
+		inc.ID = uuid.New().String()
+		inc.CreatedAt, inc.UpdatedAt = now, now
+		if err := inc.Validate(); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %s %s: %v", inc.Date, inc.Source, err), http.StatusBadRequest)
+			return
+		}
+		incomes = append(incomes, inc)
+	}
+
+	for _, exp := range expenses {
+		if err := h.store.AddExpense(exp); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to add expense: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, inc := range incomes {
+		if err := h.store.AddIncome(inc); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to add income: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := h.store.SaveExpenses(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save expenses: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.SaveIncomes(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save incomes: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result.Expenses, result.Incomes = len(expenses), len(incomes)
+	middleware.JSONResponse(w, result, http.StatusCreated)
+}
+
+// ExportOFX handles GET /api/export/ofx
+// Takes the expense and income list filters (from, to, accountId, ...)
+func (h *Handler) ExportOFX(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.exportStatement(w, r)
+	if !ok {
+		return
+	}
+	var b bytes.Buffer
+	if err := interchange.WriteOFX(&b, s, time.Now()); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to write OFX: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ofx")
+	w.Header().Set("Content-Disposition", "attachment; filename=finance-tracker.ofx")
+	w.Write(b.Bytes())
+}
+
+// ExportQIF handles GET /api/export/qif
+// As ExportOFX, plus dateFormat (default dd/mm/yyyy)
+func (h *Handler) ExportQIF(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.exportStatement(w, r)
+	if !ok {
+		return
+	}
+	var b bytes.Buffer
+	if err := interchange.WriteQIF(&b, s, r.URL.Query().Get("dateFormat")); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to write QIF: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/qif")
+	w.Header().Set("Content-Disposition", "attachment; filename=finance-tracker.qif")
+	w.Write(b.Bytes())
+}
+
+// exportStatement collects the expenses and incomes matching the list
+// filters. For one account the file carries its number and balance.
+func (h *Handler) exportStatement(w http.ResponseWriter, r *http.Request) (interchange.Statement, bool) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return interchange.Statement{}, false
+	}
+	q.Limit, q.Offset = 0, 0
+
+	s := interchange.Statement{From: q.From, To: q.To}
+	if s.Expenses, _, err = h.store.QueryExpenses(q); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to query expenses: %v", err), http.StatusInternalServerError)
+		return s, false
+	}
+	if s.Incomes, _, err = h.store.QueryIncomes(q); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to query incomes: %v", err), http.StatusInternalServerError)
+		return s, false
+	}
+
+	if q.AccountID != "" {
+		a, found := h.findAccount(q.AccountID)
+		if !found {
+			middleware.ErrorResponse(w, "Account not found", http.StatusNotFound)
+			return s, false
+		}
+		s.Account = a.Number
+		if s.Account == "" {
+			s.Account = a.Name
+		}
+		asOf := q.To
+		if asOf == "" {
+			asOf = today().Format("2006-01-02")
+		}
+		s.Balance = accounts.BalanceOn(h.book().Ledgers()[a.ID], asOf)
+	}
+	return s, true
+}
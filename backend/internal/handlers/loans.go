@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/capgains"
+	"finance-tracker/internal/chits"
+	"finance-tracker/internal/loans"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/networth"
+)
+
+// ----- LOANS -----
+
+// GetLoans handles GET /api/loans
+func (h *Handler) GetLoans(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.store.GetLoans(), http.StatusOK)
+}
+
+// CreateLoan handles POST /api/loans
+func (h *Handler) CreateLoan(w http.ResponseWriter, r *http.Request) {
+	var l models.Loan
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	l.ID = uuid.New().String()
+	l.CreatedAt = time.Now().Format(time.RFC3339)
+	l.UpdatedAt = l.CreatedAt
+	normalizeLoan(&l)
+
+	// Validate loan
+	if err := l.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddLoan(l); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add loan: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveLoans(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save loan: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, l, http.StatusCreated)
+}
+
+// UpdateLoan handles PUT /api/loans/{id}
+// Prepayments and rate changes are kept from the stored loan when none are sent
+func (h *Handler) UpdateLoan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.Loan
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	original, found := h.findLoan(id)
+	if !found {
+		middleware.ErrorResponse(w, "Loan not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	if updates.Prepayments == nil {
+		updates.Prepayments = original.Prepayments
+	}
+	if updates.RateChanges == nil {
+		updates.RateChanges = original.RateChanges
+	}
+	normalizeLoan(&updates)
+
+	h.saveLoan(w, updates)
+}
+
+// DeleteLoan handles DELETE /api/loans/{id}
+// EMI expenses linked to the loan are kept
+func (h *Handler) DeleteLoan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteLoan(id); err != nil {
+		middleware.ErrorResponse(w, "Loan not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveLoans(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save loan: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Loan deleted successfully")
+}
+
+// GetLoanStatus handles GET /api/loans/{id}
+// Outstanding principal, interest paid, next EMI and unrecorded EMIs
+func (h *Handler) GetLoanStatus(w http.ResponseWriter, r *http.Request) {
+	l, found := h.findLoan(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Loan not found", http.StatusNotFound)
+		return
+	}
+	middleware.JSONResponse(w, loans.Compute(l, h.loanSchedule(l), today()), http.StatusOK)
+}
+
+// LoansStatus handles GET /api/loans/status
+func (h *Handler) LoansStatus(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.loanStatuses(today()), http.StatusOK)
+}
+
+// GetLoanSchedule handles GET /api/loans/{id}/schedule
+// The amortization schedule with the expense recorded for each EMI
+func (h *Handler) GetLoanSchedule(w http.ResponseWriter, r *http.Request) {
+	l, found := h.findLoan(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Loan not found", http.StatusNotFound)
+		return
+	}
+	middleware.JSONResponse(w, h.loanSchedule(l), http.StatusOK)
+}
+
+// AddLoanPrepayment handles POST /api/loans/{id}/prepayments
+func (h *Handler) AddLoanPrepayment(w http.ResponseWriter, r *http.Request) {
+	l, found := h.findLoan(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Loan not found", http.StatusNotFound)
+		return
+	}
+
+	var p models.LoanPrepayment
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	l.Prepayments = append(l.Prepayments, p)
+	normalizeLoan(&l)
+	h.saveLoan(w, l)
+}
+
+// AddLoanRateChange handles POST /api/loans/{id}/rate-changes
+func (h *Handler) AddLoanRateChange(w http.ResponseWriter, r *http.Request) {
+	l, found := h.findLoan(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Loan not found", http.StatusNotFound)
+		return
+	}
+
+	var c models.LoanRateChange
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	l.RateChanges = append(l.RateChanges, c)
+	normalizeLoan(&l)
+	h.saveLoan(w, l)
+}
+
+// LoanTaxReport handles GET /api/loans/tax?fy=2024-25
+// Interest and principal repaid in the year under Sec 24(b), 80C and 80E
+func (h *Handler) LoanTaxReport(w http.ResponseWriter, r *http.Request) {
+	fy, err := capgains.ParseFY(r.URL.Query().Get("fy"), time.Now())
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	middleware.JSONResponse(w, loans.Tax(h.store.GetLoans(), fy), http.StatusOK)
+}
+
+// NetWorth handles GET /api/networth
+// Investments and unwon chits less outstanding loans and won chits' dues
+func (h *Handler) NetWorth(w http.ResponseWriter, r *http.Request) {
+	now := today()
+	chitList := []chits.Status{}
+	for _, c := range h.store.GetChits() {
+		chitList = append(chitList, chits.Compute(c, now))
+	}
+	report := networth.Build(h.store.GetInvestments(), chitList, h.loanStatuses(now), now)
+	middleware.JSONResponse(w, report, http.StatusOK)
+}
+
+// saveLoan validates and stores an updated loan and writes it back
+func (h *Handler) saveLoan(w http.ResponseWriter, l models.Loan) {
+	l.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := l.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateLoan(l.ID, l); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update loan: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveLoans(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save loan: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, l, http.StatusOK)
+}
+
+// normalizeLoan fills defaults and keeps events in date order
+func normalizeLoan(l *models.Loan) {
+	if l.Purpose == "" {
+		l.Purpose = models.LoanOther
+	}
+	if l.Prepayments == nil {
+		l.Prepayments = []models.LoanPrepayment{}
+	}
+	if l.RateChanges == nil {
+		l.RateChanges = []models.LoanRateChange{}
+	}
+	for i := range l.Prepayments {
+		if l.Prepayments[i].Adjust == "" {
+			l.Prepayments[i].Adjust = models.AdjustTenure
+		}
+	}
+	for i := range l.RateChanges {
+		if l.RateChanges[i].Adjust == "" {
+			l.RateChanges[i].Adjust = models.AdjustTenure
+		}
+	}
+	sort.SliceStable(l.Prepayments, func(i, j int) bool { return l.Prepayments[i].Date < l.Prepayments[j].Date })
+	sort.SliceStable(l.RateChanges, func(i, j int) bool { return l.RateChanges[i].Date < l.RateChanges[j].Date })
+}
+
+// loanSchedule builds the schedule of l with its recorded EMIs linked
+func (h *Handler) loanSchedule(l models.Loan) loans.Schedule {
+	s := loans.Build(l)
+	loans.Link(&s, l.ID, h.store.GetExpenses())
+	return s
+}
+
+// loanStatuses computes the status of every loan, reading expenses once
+func (h *Handler) loanStatuses(asOf time.Time) []loans.Status {
+	expenses := h.store.GetExpenses()
+	list := []loans.Status{}
+	for _, l := range h.store.GetLoans() {
+		s := loans.Build(l)
+		loans.Link(&s, l.ID, expenses)
+		list = append(list, loans.Compute(l, s, asOf))
+	}
+	return list
+}
+
+func (h *Handler) findLoan(id string) (models.Loan, bool) {
+	for _, l := range h.store.GetLoans() {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return models.Loan{}, false
+}
+
+// linkEMI checks an expense's loan link, filling in the schedule row from
+// the expense date when only the loan is given
+func (h *Handler) linkEMI(exp *models.Expense) error {
+	if exp.LoanID == "" {
+		return nil
+	}
+	l, found := h.findLoan(exp.LoanID)
+	if !found {
+		return fmt.Errorf("loan %s not found", exp.LoanID)
+	}
+	s := loans.Build(l)
+	if exp.Instalment == 0 {
+		exp.Instalment = loans.Instalment(s, exp.Date)
+	}
+	if exp.Instalment < 1 || exp.Instalment > len(s.Rows) {
+		return fmt.Errorf("loan %s has no instalment %d", l.Name, exp.Instalment)
+	}
+	return nil
+}
+
+// LoansHandler routes loan requests
+func (h *Handler) LoansHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetLoans(w, r)
+	case "POST":
+		h.CreateLoan(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// LoanHandler routes single loan requests
+func (h *Handler) LoanHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetLoanStatus(w, r)
+	case "PUT":
+		h.UpdateLoan(w, r)
+	case "DELETE":
+		h.DeleteLoan(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
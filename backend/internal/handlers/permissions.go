@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/sharing"
+)
+
+// ----- PERMISSIONS -----
+
+// ownerOnly are the routes only owners may call, as "METHOD /path", with
+// what they do
+var ownerOnly = map[string]string{
+	"PUT /settings":      "change settings",
+	"POST /import":       "restore a backup",
+	"GET /users":         "manage users",
+	"POST /users":        "manage users",
+	"PUT /users/{id}":    "manage users",
+	"DELETE /users/{id}": "manage users",
+}
+
+// contributorRoutes are the routes contributors may call. The expense and
+// income handlers keep them to their own records.
+var contributorRoutes = map[string]bool{
+	"GET /expenses":         true,
+	"POST /expenses":        true,
+	"GET /expenses/{id}":    true,
+	"PUT /expenses/{id}":    true,
+	"DELETE /expenses/{id}": true,
+	"GET /incomes":          true,
+	"POST /incomes":         true,
+	"GET /incomes/{id}":     true,
+	"PUT /incomes/{id}":     true,
+	"DELETE /incomes/{id}":  true,
+	"GET /settings":         true,
+	"GET /tags":             true,
+	"GET /auth/me":          true,
+}
+
+// anyRole are the routes every logged-in user may call
+var anyRole = map[string]bool{
+	"POST /auth/logout":   true,
+	"POST /auth/password": true,
+	"GET /tokens":         true,
+	"POST /tokens":        true,
+	"DELETE /tokens/{id}": true,
+}
+
+// sessionOnly reports whether a route needs a login session. API tokens
+// cannot manage logins, users or tokens, which could widen their own reach.
+func sessionOnly(route string) bool {
+	_, path, _ := strings.Cut(route, " ")
+	return route != "GET /auth/me" &&
+		(strings.HasPrefix(path, "/auth/") || strings.HasPrefix(path, "/users") || strings.HasPrefix(path, "/tokens"))
+}
+
+// scopeFor is the API token scope a route needs. ScopeWrite covers every
+// change as well.
+func scopeFor(route string) string {
+	method, path, _ := strings.Cut(route, " ")
+	switch {
+	case method == "GET":
+		return models.ScopeRead
+	case strings.HasPrefix(path, "/expenses"):
+		return models.ScopeExpensesWrite
+	case strings.HasPrefix(path, "/incomes"):
+		return models.ScopeIncomesWrite
+	case strings.HasPrefix(path, "/import/"):
+		// Statements and OFX/QIF files; restoring a backup (POST /import)
+		// replaces all the data and needs ScopeWrite
+		return models.ScopeImport
+	}
+	return models.ScopeWrite
+}
+
+// allowScopes checks an API token's scopes cover a route
+func allowScopes(scopes []string, route string) error {
+	if sessionOnly(route) {
+		return fmt.Errorf("API tokens cannot manage logins, users or tokens")
+	}
+	need := scopeFor(route)
+	for _, scope := range scopes {
+		if scope == need || (scope == models.ScopeWrite && need != models.ScopeRead) {
+			return nil
+		}
+	}
+	return fmt.Errorf("API token lacks the %s scope", need)
+}
+
+// roleOf is a user's role; users from before roles are owners
+func roleOf(user models.User) string {
+	if user.Role == "" {
+		return models.RoleOwner
+	}
+	return user.Role
+}
+
+// allow checks a role may call a route
+func allow(role, route string) error {
+	if anyRole[route] || role == models.RoleOwner {
+		return nil
+	}
+	if what, found := ownerOnly[route]; found {
+		return fmt.Errorf("only owners can %s", what)
+	}
+	switch role {
+	case models.RoleEditor:
+		return nil
+	case models.RoleContributor:
+		if contributorRoutes[route] {
+			return nil
+		}
+		return fmt.Errorf("contributors can only add, see and change their own expenses and incomes")
+	default:
+		if strings.HasPrefix(route, "GET ") {
+			return nil
+		}
+		return fmt.Errorf("viewers can only read")
+	}
+}
+
+// Authorize middleware answers 403 to requests the logged-in user's role,
+// or the scopes of the API token used, do not allow. Requests without a
+// user are the public routes that Auth let through.
+func (h *Handler) Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.CurrentUser(r)
+		if !ok || r.Method == "OPTIONS" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		path := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if tpl, err := route.GetPathTemplate(); err == nil {
+				path = tpl
+			}
+		}
+		path = strings.TrimPrefix(path, "/v1/api")
+		route := r.Method + " " + path
+		if err := allow(roleOf(user), route); err != nil {
+			middleware.ErrorResponse(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+			return
+		}
+		if scopes, ok := middleware.TokenScopes(r); ok {
+			if err := allowScopes(scopes, route); err != nil {
+				middleware.ErrorResponse(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ownMember is the member a contributor is, whose expenses and incomes
+// alone they may see and change. ok is false for other roles.
+func ownMember(r *http.Request) (member string, ok bool) {
+	user, found := middleware.CurrentUser(r)
+	if !found || roleOf(user) != models.RoleContributor {
+		return "", false
+	}
+	return user.Member, true
+}
+
+// checkOwnExpense checks every member a contributor's expense names is
+// them: who added and paid it, whom it is shared among and whose its split
+// lines are. Otherwise it could show others owing them in the balances.
+func checkOwnExpense(exp models.Expense, member string) error {
+	if exp.AddedBy != member {
+		return fmt.Errorf("contributors can only add and change their own expenses")
+	}
+	if sharing.Payer(exp) != member {
+		return fmt.Errorf("contributors can only record expenses they paid themselves")
+	}
+	for _, share := range exp.SharedAmong {
+		if share.Member != member {
+			return fmt.Errorf("contributors cannot share expenses with other members")
+		}
+	}
+	for _, line := range exp.Splits {
+		if line.Member != "" && line.Member != member {
+			return fmt.Errorf("contributors cannot split expenses with other members")
+		}
+	}
+	return nil
+}
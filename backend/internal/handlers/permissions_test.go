@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"testing"
+
+	"finance-tracker/internal/models"
+)
+
+func TestAllow(t *testing.T) {
+	routes := []string{
+		"GET /expenses",
+		"GET /expenses/{id}",
+		"POST /expenses",
+		"PUT /expenses/{id}",
+		"DELETE /expenses/{id}",
+		"GET /incomes/{id}",
+		"PUT /incomes/{id}",
+		"GET /investments",
+		"POST /investments",
+		"GET /balances",
+		"POST /balances/settle",
+		"GET /settings",
+		"PUT /settings",
+		"POST /import",
+		"GET /export",
+		"GET /users",
+		"DELETE /users/{id}",
+		"GET /auth/me",
+		"POST /auth/logout",
+		"POST /auth/password",
+		"POST /tokens",
+	}
+	// Routes each role may call; the rest answer 403
+	tests := []struct {
+		role    string
+		allowed []string
+	}{
+		{
+			role:    models.RoleOwner,
+			allowed: routes,
+		},
+		{
+			role: models.RoleEditor,
+			allowed: []string{
+				"GET /expenses", "GET /expenses/{id}", "POST /expenses", "PUT /expenses/{id}", "DELETE /expenses/{id}",
+				"GET /incomes/{id}", "PUT /incomes/{id}", "GET /investments", "POST /investments",
+				"GET /balances", "POST /balances/settle", "GET /settings", "GET /export",
+				"GET /auth/me", "POST /auth/logout", "POST /auth/password", "POST /tokens",
+			},
+		},
+		{
+			role: models.RoleContributor,
+			allowed: []string{
+				"GET /expenses", "GET /expenses/{id}", "POST /expenses", "PUT /expenses/{id}", "DELETE /expenses/{id}",
+				"GET /incomes/{id}", "PUT /incomes/{id}", "GET /settings",
+				"GET /auth/me", "POST /auth/logout", "POST /auth/password", "POST /tokens",
+			},
+		},
+		{
+			role: models.RoleViewer,
+			allowed: []string{
+				"GET /expenses", "GET /expenses/{id}", "GET /incomes/{id}", "GET /investments",
+				"GET /balances", "GET /settings", "GET /export",
+				"GET /auth/me", "POST /auth/logout", "POST /auth/password", "POST /tokens",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		allowed := map[string]bool{}
+		for _, route := range tt.allowed {
+			allowed[route] = true
+		}
+		for _, route := range routes {
+			err := allow(tt.role, route)
+			if allowed[route] && err != nil {
+				t.Errorf("allow(%s, %q) = %v, want allowed", tt.role, route, err)
+			}
+			if !allowed[route] && err == nil {
+				t.Errorf("allow(%s, %q) allowed, want 403", tt.role, route)
+			}
+		}
+	}
+}
+
+func TestAllowScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		route  string
+		ok     bool
+	}{
+		{"read reads", []string{models.ScopeRead}, "GET /expenses", true},
+		{"read cannot write", []string{models.ScopeRead}, "POST /expenses", false},
+		{"write cannot read", []string{models.ScopeWrite}, "GET /expenses", false},
+		{"write covers expenses", []string{models.ScopeWrite}, "POST /expenses", true},
+		{"write covers import", []string{models.ScopeWrite}, "POST /import", true},
+		{"write covers other changes", []string{models.ScopeWrite}, "PUT /investments/{id}", true},
+		{"expenses:write adds expenses", []string{models.ScopeExpensesWrite}, "POST /expenses", true},
+		{"expenses:write deletes expenses", []string{models.ScopeExpensesWrite}, "DELETE /expenses/{id}", true},
+		{"expenses:write cannot add incomes", []string{models.ScopeExpensesWrite}, "POST /incomes", false},
+		{"expenses:write cannot read", []string{models.ScopeExpensesWrite}, "GET /expenses", false},
+		{"incomes:write adds incomes", []string{models.ScopeIncomesWrite}, "POST /incomes", true},
+		{"incomes:write cannot add expenses", []string{models.ScopeIncomesWrite}, "POST /expenses", false},
+		{"import cannot restore", []string{models.ScopeImport}, "POST /import", false},
+		{"import reads OFX", []string{models.ScopeImport}, "POST /import/ofx", true},
+		{"import reads QIF", []string{models.ScopeImport}, "POST /import/qif", true},
+		{"import commits statements", []string{models.ScopeImport}, "POST /import/statement/{id}/commit", true},
+		{"import reads statements in", []string{models.ScopeImport}, "POST /import/statement", true},
+		{"import cannot add expenses", []string{models.ScopeImport}, "POST /expenses", false},
+		{"scopes combine", []string{models.ScopeRead, models.ScopeExpensesWrite}, "GET /balances", true},
+		{"no scopes", nil, "GET /expenses", false},
+		{"reads own user", []string{models.ScopeRead}, "GET /auth/me", true},
+		{"cannot change password", []string{models.ScopeWrite}, "POST /auth/password", false},
+		{"cannot log out", []string{models.ScopeWrite}, "POST /auth/logout", false},
+		{"cannot list users", []string{models.ScopeRead}, "GET /users", false},
+		{"cannot manage users", []string{models.ScopeWrite}, "PUT /users/{id}", false},
+		{"cannot list tokens", []string{models.ScopeRead}, "GET /tokens", false},
+		{"cannot create tokens", []string{models.ScopeWrite}, "POST /tokens", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := allowScopes(tt.scopes, tt.route)
+			if tt.ok && err != nil {
+				t.Errorf("allowScopes(%v, %q) = %v, want allowed", tt.scopes, tt.route, err)
+			}
+			if !tt.ok && err == nil {
+				t.Errorf("allowScopes(%v, %q) allowed, want 403", tt.scopes, tt.route)
+			}
+		})
+	}
+}
+
+func TestCheckOwnExpense(t *testing.T) {
+	tests := []struct {
+		name string
+		exp  models.Expense
+		ok   bool
+	}{
+		{"own", models.Expense{AddedBy: "Anu"}, true},
+		{"paid by self", models.Expense{AddedBy: "Anu", PaidBy: "Anu"}, true},
+		{"own split lines", models.Expense{AddedBy: "Anu", Splits: []models.Split{{Member: "Anu"}, {}}}, true},
+		{"shared with self only", models.Expense{AddedBy: "Anu", SharedAmong: []models.Share{{Member: "Anu"}}}, true},
+		{"added for another", models.Expense{AddedBy: "Ravi"}, false},
+		{"paid by another", models.Expense{AddedBy: "Anu", PaidBy: "Ravi"}, false},
+		{"shared with another", models.Expense{AddedBy: "Anu", SharedAmong: []models.Share{{Member: "Anu"}, {Member: "Ravi"}}}, false},
+		{"split line for another", models.Expense{AddedBy: "Anu", Splits: []models.Split{{Member: "Ravi"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOwnExpense(tt.exp, "Anu")
+			if tt.ok && err != nil {
+				t.Errorf("checkOwnExpense() = %v, want nil", err)
+			}
+			if !tt.ok && err == nil {
+				t.Error("checkOwnExpense() = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/portfolio"
+)
+
+// ----- VALUATION HISTORY -----
+
+// InvestmentHistory handles GET /api/investments/{id}/history
+// Query: from, to (YYYY-MM-DD, default purchase date to today) and
+// interval (daily or monthly, default daily for up to a year)
+func (h *Handler) InvestmentHistory(w http.ResponseWriter, r *http.Request) {
+	inv, ok := h.findInvestment(mux.Vars(r)["id"])
+	if !ok {
+		middleware.ErrorResponse(w, "Investment not found", http.StatusNotFound)
+		return
+	}
+
+	h.writeHistory(w, r, []models.Investment{inv})
+}
+
+// PortfolioHistory handles GET /api/portfolio/history
+// Same query as InvestmentHistory, plus an optional type filter
+func (h *Handler) PortfolioHistory(w http.ResponseWriter, r *http.Request) {
+	investments := []models.Investment{}
+	typ := r.URL.Query().Get("type")
+	for _, inv := range h.store.GetInvestments() {
+		if typ == "" || inv.Type == typ {
+			investments = append(investments, inv)
+		}
+	}
+
+	h.writeHistory(w, r, investments)
+}
+
+func (h *Handler) writeHistory(w http.ResponseWriter, r *http.Request, investments []models.Investment) {
+	q := r.URL.Query()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	from, ok := portfolio.FirstDate(investments)
+	if !ok {
+		from = today
+	}
+	to := today
+	var err error
+	if s := q.Get("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			middleware.ErrorResponse(w, "from must be YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := q.Get("to"); s != "" {
+		if to, err = time.Parse("2006-01-02", s); err != nil {
+			middleware.ErrorResponse(w, "to must be YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	if from.After(to) {
+		middleware.ErrorResponse(w, "from date cannot be after to date", http.StatusBadRequest)
+		return
+	}
+
+	interval := q.Get("interval")
+	if interval == "" {
+		interval = portfolio.IntervalDaily
+		if to.Sub(from) > 366*24*time.Hour {
+			interval = portfolio.IntervalMonthly
+		}
+	}
+
+	prices := portfolio.Prices{}
+	for _, inv := range investments {
+		if inv.SchemeCode != "" {
+			if _, ok := prices[inv.SchemeCode]; !ok {
+				prices[inv.SchemeCode] = h.store.GetPrices(inv.SchemeCode)
+			}
+		}
+	}
+
+	series, err := portfolio.History(investments, prices, from, to, interval)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	middleware.JSONResponse(w, series, http.StatusOK)
+}
+
+// recordPrice stores the NAV implied by an investment's units and current
+// value, so manual and browser-side NAV updates feed the price history too
+func (h *Handler) recordPrice(inv models.Investment, source string) error {
+	if inv.SchemeCode == "" || inv.Units <= 0 || inv.Current <= 0 {
+		return nil
+	}
+	point := models.PricePoint{
+		Symbol: inv.SchemeCode,
+		Date:   time.Now().Format("2006-01-02"),
+		Price:  inv.Current / inv.Units,
+		Source: source,
+	}
+	if err := h.store.AddPrices([]models.PricePoint{point}); err != nil {
+		return fmt.Errorf("failed to record price: %w", err)
+	}
+	return h.store.SavePrices()
+}
+
+// ----- RETURNS -----
+
+// InvestmentReturns handles GET /api/investments/{id}/returns
+func (h *Handler) InvestmentReturns(w http.ResponseWriter, r *http.Request) {
+	inv, ok := h.findInvestment(mux.Vars(r)["id"])
+	if !ok {
+		middleware.ErrorResponse(w, "Investment not found", http.StatusNotFound)
+		return
+	}
+	middleware.JSONResponse(w, portfolio.Returns(inv, time.Now().UTC().Truncate(24*time.Hour)), http.StatusOK)
+}
+
+// PortfolioReturns handles GET /api/portfolio/returns
+// Returns for the whole portfolio, per investment type and per holding
+func (h *Handler) PortfolioReturns(w http.ResponseWriter, r *http.Request) {
+	report := portfolio.Report(h.store.GetInvestments(), time.Now().UTC().Truncate(24*time.Hour))
+	middleware.JSONResponse(w, report, http.StatusOK)
+}
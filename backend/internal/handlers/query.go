@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/storage"
+)
+
+// maxPageSize caps the limit query parameter
+const maxPageSize = 1000
+
+// parseListQuery reads the list endpoint query parameters:
+//
+//	from, to             inclusive date range (YYYY-MM-DD)
+//	category             category (investment type for investments)
+//	addedBy              member name (expenses and incomes)
+//	paymentMethod        payment method (expenses and incomes)
+//	accountId            account (expenses and incomes)
+//	minAmount, maxAmount amount range
+//	q                    free-text match on description, source or name
+//	tag                  tag, repeated for records carrying all of them
+//	sort, order          sort field and "asc" (default) or "desc"
+//	limit, offset        pagination; no limit returns every match
+func parseListQuery(r *http.Request) (storage.Query, error) {
+	v := r.URL.Query()
+	q := storage.Query{
+		From:          v.Get("from"),
+		To:            v.Get("to"),
+		Category:      v.Get("category"),
+		AddedBy:       v.Get("addedBy"),
+		PaymentMethod: v.Get("paymentMethod"),
+		AccountID:     v.Get("accountId"),
+		Search:        v.Get("q"),
+		Tags:          v["tag"],
+		SortBy:        v.Get("sort"),
+	}
+
+	for _, d := range []struct{ name, value string }{{"from", q.From}, {"to", q.To}} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d.value); err != nil {
+			return q, fmt.Errorf("%s must be a date in YYYY-MM-DD format", d.name)
+		}
+	}
+
+	var err error
+	if q.MinAmount, err = parseAmountParam(v.Get("minAmount"), "minAmount"); err != nil {
+		return q, err
+	}
+	if q.MaxAmount, err = parseAmountParam(v.Get("maxAmount"), "maxAmount"); err != nil {
+		return q, err
+	}
+
+	switch v.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.SortDesc = true
+	default:
+		return q, fmt.Errorf("order must be \"asc\" or \"desc\"")
+	}
+
+	if s := v.Get("limit"); s != "" {
+		if q.Limit, err = strconv.Atoi(s); err != nil || q.Limit < 1 {
+			return q, fmt.Errorf("limit must be a positive integer")
+		}
+		if q.Limit > maxPageSize {
+			q.Limit = maxPageSize
+		}
+	}
+	if s := v.Get("offset"); s != "" {
+		if q.Offset, err = strconv.Atoi(s); err != nil || q.Offset < 0 {
+			return q, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+
+	return q, q.Validate()
+}
+
+func parseAmountParam(s, name string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", name)
+	}
+	return &f, nil
+}
+
+// pageMeta builds the response metadata for a page of count items
+func pageMeta(q storage.Query, count, total int) middleware.PageMeta {
+	meta := middleware.PageMeta{Total: total, Count: count, Limit: q.Limit, Offset: q.Offset}
+	if next := q.Offset + count; count > 0 && next < total {
+		meta.NextOffset = &next
+	}
+	return meta
+}
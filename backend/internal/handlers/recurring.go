@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/recurring"
+)
+
+// ----- RECURRING TRANSACTIONS -----
+
+// GetRecurringRules handles GET /api/recurring
+func (h *Handler) GetRecurringRules(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.store.GetRecurringRules(), http.StatusOK)
+}
+
+// CreateRecurringRule handles POST /api/recurring
+func (h *Handler) CreateRecurringRule(w http.ResponseWriter, r *http.Request) {
+	var rule models.RecurringRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = time.Now().Format(time.RFC3339)
+	rule.UpdatedAt = rule.CreatedAt
+	rule.Occurrences = map[string]string{}
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+
+	// Validate rule and template
+	if err := rule.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddRecurringRule(rule); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add recurring rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveRecurringRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save recurring rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, rule, http.StatusCreated)
+}
+
+// UpdateRecurringRule handles PUT /api/recurring/{id}
+// Occurrence history is kept from the stored rule
+func (h *Handler) UpdateRecurringRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.RecurringRule
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var original models.RecurringRule
+	found := false
+	for _, rule := range h.store.GetRecurringRules() {
+		if rule.ID == id {
+			original = rule
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		middleware.ErrorResponse(w, "Recurring rule not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+	updates.Occurrences = original.Occurrences
+	if updates.Interval == 0 {
+		updates.Interval = 1
+	}
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateRecurringRule(id, updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update recurring rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveRecurringRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save recurring rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, updates, http.StatusOK)
+}
+
+// DeleteRecurringRule handles DELETE /api/recurring/{id}
+// Records already posted by the rule are kept
+func (h *Handler) DeleteRecurringRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteRecurringRule(id); err != nil {
+		middleware.ErrorResponse(w, "Recurring rule not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveRecurringRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save recurring rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Recurring rule deleted successfully")
+}
+
+// UpcomingOccurrences handles GET /api/recurring/upcoming?days=30
+// Lists due (awaiting confirmation) and scheduled occurrences
+func (h *Handler) UpcomingOccurrences(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if s := r.URL.Query().Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > 366 {
+			middleware.ErrorResponse(w, "days must be an integer between 0 and 366", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	middleware.JSONResponse(w, h.recurring.Upcoming(days), http.StatusOK)
+}
+
+// ConfirmOccurrence handles POST /api/recurring/{id}/occurrences/{date}/confirm
+func (h *Handler) ConfirmOccurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	occ, err := h.recurring.Confirm(vars["id"], vars["date"])
+	if err != nil {
+		recurringError(w, err)
+		return
+	}
+	middleware.JSONResponse(w, occ, http.StatusCreated)
+}
+
+// SkipOccurrence handles POST /api/recurring/{id}/occurrences/{date}/skip
+func (h *Handler) SkipOccurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.recurring.Skip(vars["id"], vars["date"]); err != nil {
+		recurringError(w, err)
+		return
+	}
+	middleware.SuccessMessage(w, "Occurrence skipped")
+}
+
+// RunRecurring handles POST /api/recurring/run
+// Posts due auto-post occurrences now instead of waiting for the scheduler
+func (h *Handler) RunRecurring(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.recurring.Run(), http.StatusOK)
+}
+
+// recurringError maps engine errors to HTTP statuses
+func recurringError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, recurring.ErrRuleNotFound):
+		middleware.ErrorResponse(w, "Recurring rule not found", http.StatusNotFound)
+	case errors.Is(err, recurring.ErrInvalidDate), errors.Is(err, recurring.ErrNotAnOccurrence):
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, recurring.ErrAlreadyHandled):
+		middleware.ErrorResponse(w, err.Error(), http.StatusConflict)
+	default:
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to post occurrence: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// RecurringRulesHandler routes recurring rule requests
+func (h *Handler) RecurringRulesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetRecurringRules(w, r)
+	case "POST":
+		h.CreateRecurringRule(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// RecurringRuleHandler routes single recurring rule requests
+func (h *Handler) RecurringRuleHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		h.UpdateRecurringRule(w, r)
+	case "DELETE":
+		h.DeleteRecurringRule(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"finance-tracker/internal/capgains"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/portfolio"
+	"finance-tracker/internal/reports"
+	"finance-tracker/internal/storage"
+)
+
+// ----- REPORTS -----
+
+// parseReportRange reads the from/to query parameters of a report request
+func parseReportRange(r *http.Request) (reports.Range, error) {
+	return reports.ParseRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"), time.Now())
+}
+
+// reportData loads the incomes and expenses a report over rg needs,
+// including its comparison periods. The request's tag parameters narrow
+// them to records carrying those tags.
+func (h *Handler) reportData(r *http.Request, rg reports.Range) ([]models.Income, []models.Expense, error) {
+	q := storage.Query{
+		From: rg.ComparisonStart().Format("2006-01-02"),
+		To:   rg.To.Format("2006-01-02"),
+		Tags: r.URL.Query()["tag"],
+	}
+	incomes, _, err := h.store.QueryIncomes(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	expenses, _, err := h.store.QueryExpenses(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return incomes, expenses, nil
+}
+
+// CashFlowReport handles GET /api/reports/cashflow
+// Per-month income, expense, net savings and savings rate with MoM/YoY deltas
+func (h *Handler) CashFlowReport(w http.ResponseWriter, r *http.Request) {
+	rg, err := parseReportRange(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	incomes, expenses, err := h.reportData(r, rg)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to load report data: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, reports.CashFlow(incomes, expenses, rg), http.StatusOK)
+}
+
+// BreakdownReport handles GET /api/reports/breakdown?groupBy=category|addedBy|paymentMethod|tag
+func (h *Handler) BreakdownReport(w http.ResponseWriter, r *http.Request) {
+	rg, err := parseReportRange(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groupBy := r.URL.Query().Get("groupBy")
+	if groupBy == "" {
+		groupBy = reports.GroupByCategory
+	}
+	if !reports.ValidGroupBy(groupBy) {
+		middleware.ErrorResponse(w, fmt.Sprintf("groupBy must be one of %s", strings.Join(reports.GroupByOptions, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	_, expenses, err := h.reportData(r, rg)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to load report data: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, reports.Breakdown(expenses, groupBy, rg), http.StatusOK)
+}
+
+// SummaryReport handles GET /api/reports/summary
+// Range totals, comparisons and per-category and per-member spending
+func (h *Handler) SummaryReport(w http.ResponseWriter, r *http.Request) {
+	rg, err := parseReportRange(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	incomes, expenses, err := h.reportData(r, rg)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to load report data: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, reports.Summary(incomes, expenses, rg), http.StatusOK)
+}
+
+// CapitalGainsReport handles GET /api/reports/capital-gains?fy=2024-25
+// STCG/LTCG for one April–March year; format=csv downloads the schedule
+func (h *Handler) CapitalGainsReport(w http.ResponseWriter, r *http.Request) {
+	fy, err := capgains.ParseFY(r.URL.Query().Get("fy"), time.Now())
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	investments := h.store.GetInvestments()
+	prices := portfolio.Prices{}
+	for _, inv := range investments {
+		if inv.SchemeCode != "" {
+			if _, ok := prices[inv.SchemeCode]; !ok {
+				prices[inv.SchemeCode] = h.store.GetPrices(inv.SchemeCode)
+			}
+		}
+	}
+	report := capgains.Build(investments, prices, fy)
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		middleware.JSONResponse(w, report, http.StatusOK)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=capital-gains-%s.csv", fy))
+		if err := capgains.WriteCSV(w, report); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to write CSV: %v", err), http.StatusInternalServerError)
+		}
+	default:
+		middleware.ErrorResponse(w, "format must be json or csv", http.StatusBadRequest)
+	}
+}
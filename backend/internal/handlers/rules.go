@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/rules"
+)
+
+// ----- CATEGORISATION RULES -----
+
+// ruleEngine prepares the stored rules to run
+func (h *Handler) ruleEngine() *rules.Engine {
+	return rules.New(h.store.GetRules())
+}
+
+// GetRules handles GET /api/rules
+// Rules in the order they run
+func (h *Handler) GetRules(w http.ResponseWriter, r *http.Request) {
+	list := h.store.GetRules()
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Priority != list[j].Priority {
+			return list[i].Priority < list[j].Priority
+		}
+		return list[i].CreatedAt < list[j].CreatedAt
+	})
+	middleware.JSONResponse(w, list, http.StatusOK)
+}
+
+// CreateRule handles POST /api/rules
+func (h *Handler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var rule models.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = time.Now().Format(time.RFC3339)
+	rule.UpdatedAt = rule.CreatedAt
+
+	// Validate rule
+	if err := rule.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkAccount(rule.SetAccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddRule(rule); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, rule, http.StatusCreated)
+}
+
+// UpdateRule handles PUT /api/rules/{id}
+func (h *Handler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.Rule
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var original models.Rule
+	found := false
+	for _, rule := range h.store.GetRules() {
+		if rule.ID == id {
+			original = rule
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		middleware.ErrorResponse(w, "Rule not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkAccount(updates.SetAccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateRule(id, updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, updates, http.StatusOK)
+}
+
+// DeleteRule handles DELETE /api/rules/{id}
+// Records the rule categorised keep their category
+func (h *Handler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteRule(id); err != nil {
+		middleware.ErrorResponse(w, "Rule not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Rule deleted successfully")
+}
+
+// ruleChanges works out what re-applying the rules would change in the
+// records matching the list filters (from, to, accountId, ...). `kind`
+// narrows to expenses or incomes and `ruleId` to one rule.
+func (h *Handler) ruleChanges(w http.ResponseWriter, r *http.Request) ([]rules.Change, bool) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	q.Limit, q.Offset = 0, 0
+
+	engine := h.ruleEngine()
+	if id := r.URL.Query().Get("ruleId"); id != "" {
+		var one []models.Rule
+		for _, rule := range h.store.GetRules() {
+			if rule.ID == id {
+				one = append(one, rule)
+			}
+		}
+		if len(one) == 0 {
+			middleware.ErrorResponse(w, "Rule not found", http.StatusNotFound)
+			return nil, false
+		}
+		engine = rules.New(one)
+	}
+
+	var expenses []models.Expense
+	var incomes []models.Income
+	kind := r.URL.Query().Get("kind")
+	switch kind {
+	case "", models.KindExpense, models.KindIncome:
+	default:
+		middleware.ErrorResponse(w, "kind must be expense or income", http.StatusBadRequest)
+		return nil, false
+	}
+	if kind != models.KindIncome {
+		if expenses, _, err = h.store.QueryExpenses(q); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to query expenses: %v", err), http.StatusInternalServerError)
+			return nil, false
+		}
+	}
+	if kind != models.KindExpense {
+		if incomes, _, err = h.store.QueryIncomes(q); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to query incomes: %v", err), http.StatusInternalServerError)
+			return nil, false
+		}
+	}
+	return engine.Changes(expenses, incomes), true
+}
+
+// PreviewRules handles GET /api/rules/reapply
+// Lists the recorded expenses and incomes the rules would change, with
+// their values before and after. Nothing is saved.
+func (h *Handler) PreviewRules(w http.ResponseWriter, r *http.Request) {
+	changes, ok := h.ruleChanges(w, r)
+	if !ok {
+		return
+	}
+	middleware.JSONResponse(w, changes, http.StatusOK)
+}
+
+// reapplyRequest picks the previewed changes to save; all when ids is empty
+type reapplyRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// ReapplyRules handles POST /api/rules/reapply
+// Takes the same filters as the preview and saves its changes, or only
+// those to the records listed in the optional body
+func (h *Handler) ReapplyRules(w http.ResponseWriter, r *http.Request) {
+	var req reapplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	changes, ok := h.ruleChanges(w, r)
+	if !ok {
+		return
+	}
+	if len(req.IDs) > 0 {
+		picked := map[string]bool{}
+		for _, id := range req.IDs {
+			picked[id] = true
+		}
+		var kept []rules.Change
+		for _, c := range changes {
+			if picked[c.ID] {
+				kept = append(kept, c)
+			}
+		}
+		changes = kept
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	byKind := map[string]map[string]rules.Fields{models.KindExpense: {}, models.KindIncome: {}}
+	for _, c := range changes {
+		byKind[c.Kind][c.ID] = c.After
+	}
+	if len(byKind[models.KindExpense]) > 0 {
+		for _, exp := range h.store.GetExpenses() {
+			after, found := byKind[models.KindExpense][exp.ID]
+			if !found {
+				continue
+			}
+			exp.Category, exp.Tags, exp.AddedBy, exp.AccountID = after.Category, after.Tags, after.AddedBy, after.AccountID
+			exp.UpdatedAt = now
+			if err := h.store.UpdateExpense(exp.ID, exp); err != nil {
+				middleware.ErrorResponse(w, fmt.Sprintf("Failed to update expense: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := h.store.SaveExpenses(); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to save expenses: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if len(byKind[models.KindIncome]) > 0 {
+		for _, inc := range h.store.GetIncomes() {
+			after, found := byKind[models.KindIncome][inc.ID]
+			if !found {
+				continue
+			}
+			inc.Category, inc.Tags, inc.AddedBy, inc.AccountID = after.Category, after.Tags, after.AddedBy, after.AccountID
+			inc.UpdatedAt = now
+			if err := h.store.UpdateIncome(inc.ID, inc); err != nil {
+				middleware.ErrorResponse(w, fmt.Sprintf("Failed to update income: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := h.store.SaveIncomes(); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to save incomes: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if changes == nil {
+		changes = []rules.Change{}
+	}
+	middleware.JSONResponse(w, changes, http.StatusOK)
+}
+
+// RulesHandler routes categorisation rule requests
+func (h *Handler) RulesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetRules(w, r)
+	case "POST":
+		h.CreateRule(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// RuleHandler routes single categorisation rule requests
+func (h *Handler) RuleHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		h.UpdateRule(w, r)
+	case "DELETE":
+		h.DeleteRule(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ReapplyRulesHandler routes re-apply requests: GET previews, POST saves
+func (h *Handler) ReapplyRulesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.PreviewRules(w, r)
+	case "POST":
+		h.ReapplyRules(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/sharing"
+	"finance-tracker/internal/storage"
+	"finance-tracker/internal/tags"
+)
+
+// ----- SHARED EXPENSES -----
+
+// checkMembers checks that each name is one of the members in settings
+func (h *Handler) checkMembers(names ...string) error {
+	members := map[string]bool{}
+	for _, m := range h.store.GetSettings().Members {
+		members[m] = true
+	}
+	for _, name := range names {
+		if !members[name] {
+			return fmt.Errorf("%s is not a member", name)
+		}
+	}
+	return nil
+}
+
+// checkSharing checks that whoever paid or shares an expense is a member,
+// and shares equally when no mode is given
+func (h *Handler) checkSharing(exp *models.Expense) error {
+	if exp.PaidBy == "" && len(exp.SharedAmong) == 0 {
+		return nil
+	}
+	if len(exp.SharedAmong) > 0 && exp.ShareMode == "" {
+		exp.ShareMode = models.ShareEqual
+	}
+	names := []string{sharing.Payer(*exp)}
+	for _, share := range exp.SharedAmong {
+		names = append(names, share.Member)
+	}
+	return h.checkMembers(names...)
+}
+
+// balanceData loads the shared expenses and settlements up to asOf (all
+// when empty) carrying every tag of want
+func (h *Handler) balanceData(asOf string, want []string) ([]models.Expense, []models.Settlement, error) {
+	expenses, _, err := h.store.QueryExpenses(storage.Query{To: asOf, Tags: want})
+	if err != nil {
+		return nil, nil, err
+	}
+	var settlements []models.Settlement
+	for _, st := range h.store.GetSettlements() {
+		if (asOf == "" || st.Date <= asOf) && tags.HasAll(st.Tags, want) {
+			settlements = append(settlements, st)
+		}
+	}
+	return expenses, settlements, nil
+}
+
+// parseBalanceQuery reads the asOf date and tags of a balances request
+func parseBalanceQuery(r *http.Request) (string, []string, error) {
+	asOf := r.URL.Query().Get("asOf")
+	if asOf != "" {
+		if _, err := time.Parse("2006-01-02", asOf); err != nil {
+			return "", nil, fmt.Errorf("asOf must be a date in YYYY-MM-DD format")
+		}
+	}
+	return asOf, r.URL.Query()["tag"], nil
+}
+
+// balancesReport is where members stand and how they can square up
+type balancesReport struct {
+	Balances []sharing.Balance  `json:"balances"`
+	Plan     []sharing.Transfer `json:"plan"`
+}
+
+// GetBalances handles GET /api/balances?asOf=YYYY-MM-DD&tag=...
+// What each member paid for shared expenses against their share, and the
+// fewest payments that settle up. tag narrows to e.g. one trip.
+func (h *Handler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	asOf, want, err := parseBalanceQuery(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	expenses, settlements, err := h.balanceData(asOf, want)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to query expenses: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	balances := sharing.Balances(h.store.GetSettings().Members, expenses, settlements)
+	middleware.JSONResponse(w, balancesReport{balances, sharing.Plan(balances)}, http.StatusOK)
+}
+
+// settleRequest describes the settlements recorded for a plan
+type settleRequest struct {
+	Date string `json:"date"` // Default: asOf, or today
+	Note string `json:"note"`
+}
+
+// SettleBalances handles POST /api/balances/settle
+// Records the plan GetBalances gives for the same query as settlements,
+// which brings every balance to zero. They carry the query's tags.
+func (h *Handler) SettleBalances(w http.ResponseWriter, r *http.Request) {
+	var req settleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	asOf, want, err := parseBalanceQuery(r)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Date == "" {
+		req.Date = asOf
+	}
+	if req.Date == "" {
+		req.Date = today().Format("2006-01-02")
+	}
+	expenses, settlements, err := h.balanceData(asOf, want)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to query expenses: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	recorded := []models.Settlement{}
+	for _, t := range sharing.Plan(sharing.Balances(nil, expenses, settlements)) {
+		st := models.Settlement{
+			ID:        uuid.New().String(),
+			From:      t.From,
+			To:        t.To,
+			Amount:    t.Amount,
+			Date:      req.Date,
+			Note:      req.Note,
+			Tags:      want,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := st.Validate(); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+			return
+		}
+		recorded = append(recorded, st)
+	}
+	for _, st := range recorded {
+		if err := h.store.AddSettlement(st); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to add settlement: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := h.store.SaveSettlements(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save settlements: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, recorded, http.StatusCreated)
+}
+
+// ----- SETTLEMENTS -----
+
+// GetSettlements handles GET /api/settlements
+func (h *Handler) GetSettlements(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.store.GetSettlements(), http.StatusOK)
+}
+
+// CreateSettlement handles POST /api/settlements
+func (h *Handler) CreateSettlement(w http.ResponseWriter, r *http.Request) {
+	var st models.Settlement
+	if err := json.NewDecoder(r.Body).Decode(&st); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	st.ID = uuid.New().String()
+	st.CreatedAt = time.Now().Format(time.RFC3339)
+	st.UpdatedAt = st.CreatedAt
+
+	// Validate settlement
+	if err := st.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkMembers(st.From, st.To); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddSettlement(st); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add settlement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveSettlements(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save settlement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, st, http.StatusCreated)
+}
+
+// UpdateSettlement handles PUT /api/settlements/{id}
+func (h *Handler) UpdateSettlement(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.Settlement
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var original models.Settlement
+	found := false
+	for _, st := range h.store.GetSettlements() {
+		if st.ID == id {
+			original = st
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		middleware.ErrorResponse(w, "Settlement not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkMembers(updates.From, updates.To); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateSettlement(id, updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update settlement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveSettlements(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save settlement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, updates, http.StatusOK)
+}
+
+// DeleteSettlement handles DELETE /api/settlements/{id}
+func (h *Handler) DeleteSettlement(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteSettlement(id); err != nil {
+		middleware.ErrorResponse(w, "Settlement not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveSettlements(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save settlement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Settlement deleted successfully")
+}
+
+// SettlementsHandler routes settlement requests
+func (h *Handler) SettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetSettlements(w, r)
+	case "POST":
+		h.CreateSettlement(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// SettlementHandler routes single settlement requests
+func (h *Handler) SettlementHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		h.UpdateSettlement(w, r)
+	case "DELETE":
+		h.DeleteSettlement(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
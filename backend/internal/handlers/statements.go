@@ -0,0 +1,543 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/duplicates"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/statements"
+)
+
+// maxUploadSize caps uploaded statement, OFX and QIF files
+const maxUploadSize = 10 << 20
+
+// ----- STATEMENT IMPORT -----
+
+// GetStatementProfiles handles GET /api/import/statement/profiles
+// Saved profiles followed by the built-in bank presets
+func (h *Handler) GetStatementProfiles(w http.ResponseWriter, r *http.Request) {
+	list := append([]models.StatementProfile{}, h.store.GetStatementProfiles()...)
+	list = append(list, statements.Presets...)
+	middleware.JSONResponse(w, list, http.StatusOK)
+}
+
+// CreateStatementProfile handles POST /api/import/statement/profiles
+func (h *Handler) CreateStatementProfile(w http.ResponseWriter, r *http.Request) {
+	var p models.StatementProfile
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.ID = uuid.New().String()
+	p.CreatedAt = time.Now().Format(time.RFC3339)
+	p.UpdatedAt = p.CreatedAt
+
+	// Validate profile
+	if err := p.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkAccount(p.AccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddStatementProfile(p); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveStatementProfiles(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, p, http.StatusCreated)
+}
+
+// UpdateStatementProfile handles PUT /api/import/statement/profiles/{id}
+func (h *Handler) UpdateStatementProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var updates models.StatementProfile
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	original, found := h.findStatementProfile(id)
+	if !found {
+		middleware.ErrorResponse(w, "Profile not found", http.StatusNotFound)
+		return
+	}
+
+	updates.ID = id
+	updates.CreatedAt = original.CreatedAt
+	updates.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	// Validate before updating
+	if err := updates.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkAccount(updates.AccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateStatementProfile(id, updates); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveStatementProfiles(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, updates, http.StatusOK)
+}
+
+// DeleteStatementProfile handles DELETE /api/import/statement/profiles/{id}
+func (h *Handler) DeleteStatementProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteStatementProfile(id); err != nil {
+		middleware.ErrorResponse(w, "Profile not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveStatementProfiles(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Profile deleted successfully")
+}
+
+// GetStatementImports handles GET /api/import/statement
+func (h *Handler) GetStatementImports(w http.ResponseWriter, r *http.Request) {
+	middleware.JSONResponse(w, h.store.GetStatementImports(), http.StatusOK)
+}
+
+// UploadStatement handles POST /api/import/statement
+// A multipart form with the statement `file` and optional `profileId`,
+// `accountId` and `addedBy`. The statement is parsed into rows for review;
+// nothing is recorded until the import is committed.
+func (h *Handler) UploadStatement(w http.ResponseWriter, r *http.Request) {
+	data, fileName, ok := readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	// The delimiter only matters once a profile is chosen, so read with the
+	// chosen profile's, or as plain CSV when detecting
+	profileID := r.FormValue("profileId")
+	var profile models.StatementProfile
+	if profileID != "" {
+		var found bool
+		if profile, found = h.findStatementProfile(profileID); !found {
+			if profile, found = statements.Preset(profileID); !found {
+				middleware.ErrorResponse(w, "Profile not found", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	table, err := statements.Read(fileName, data, profile.Delimiter)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if profileID == "" {
+		var found bool
+		candidates := append(h.store.GetStatementProfiles(), statements.Presets...)
+		if profile, found = statements.Detect(table, candidates); !found {
+			middleware.ErrorResponse(w, "No profile matches the statement's columns; choose or create one", http.StatusBadRequest)
+			return
+		}
+	}
+	rows, err := statements.Parse(table, profile)
+	if err != nil {
+		middleware.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	si := models.StatementImport{
+		ID:        uuid.New().String(),
+		FileName:  fileName,
+		ProfileID: profile.ID,
+		AccountID: r.FormValue("accountId"),
+		AddedBy:   r.FormValue("addedBy"),
+		Status:    models.ImportPending,
+		Rows:      rows,
+	}
+	if si.AccountID == "" {
+		si.AccountID = profile.AccountID
+	}
+	if err := h.checkAccount(si.AccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if a, found := h.findAccount(si.AccountID); found && si.AddedBy == "" {
+		si.AddedBy = a.Member
+	}
+	si.CreatedAt = time.Now().Format(time.RFC3339)
+	si.UpdatedAt = si.CreatedAt
+	engine := h.ruleEngine()
+	for i := range si.Rows {
+		if si.Rows[i].Error == "" {
+			engine.Row(&si.Rows[i], si.AddedBy, si.AccountID)
+		}
+	}
+	h.markDuplicates(si.Rows)
+
+	// Validate import
+	if err := si.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddStatementImport(si); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add import: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveStatementImports(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save import: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, si, http.StatusCreated)
+}
+
+// GetStatementImport handles GET /api/import/statement/{id}
+func (h *Handler) GetStatementImport(w http.ResponseWriter, r *http.Request) {
+	si, found := h.findStatementImport(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Import not found", http.StatusNotFound)
+		return
+	}
+	middleware.JSONResponse(w, si, http.StatusOK)
+}
+
+// rowEdit changes one row of a pending import; fields left out are kept
+type rowEdit struct {
+	Line          int     `json:"line"`
+	Kind          *string `json:"kind"`
+	Description   *string `json:"description"`
+	Category      *string `json:"category"`
+	PaymentMethod *string `json:"paymentMethod"`
+	Skip          *bool   `json:"skip"`
+}
+
+// ReviewStatementImport handles PUT /api/import/statement/{id}
+// Body: {"accountId": "...", "addedBy": "...", "rows": [{"line": 12, "category": "Food", "skip": false}]}
+func (h *Handler) ReviewStatementImport(w http.ResponseWriter, r *http.Request) {
+	si, found := h.findStatementImport(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Import not found", http.StatusNotFound)
+		return
+	}
+	if si.Status != models.ImportPending {
+		middleware.ErrorResponse(w, "Import is already committed", http.StatusBadRequest)
+		return
+	}
+
+	var review struct {
+		AccountID *string   `json:"accountId"`
+		AddedBy   *string   `json:"addedBy"`
+		Rows      []rowEdit `json:"rows"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.AccountID != nil {
+		si.AccountID = *review.AccountID
+	}
+	if review.AddedBy != nil {
+		si.AddedBy = *review.AddedBy
+	}
+	for _, edit := range review.Rows {
+		i := rowIndex(si.Rows, edit.Line)
+		if i < 0 {
+			middleware.ErrorResponse(w, fmt.Sprintf("Import has no line %d", edit.Line), http.StatusBadRequest)
+			return
+		}
+		row := &si.Rows[i]
+		if edit.Kind != nil {
+			row.Kind = *edit.Kind
+		}
+		if edit.Description != nil {
+			row.Description = *edit.Description
+		}
+		if edit.Category != nil {
+			row.Category = *edit.Category
+		}
+		if edit.PaymentMethod != nil {
+			row.PaymentMethod = *edit.PaymentMethod
+		}
+		if edit.Skip != nil {
+			row.Skip = *edit.Skip || row.Error != ""
+		}
+	}
+
+	si.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := si.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := h.checkAccount(si.AccountID); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	h.saveStatementImport(w, si)
+}
+
+// CommitStatementImport handles POST /api/import/statement/{id}/commit
+// Records every row not skipped as an expense or income. Nothing is
+// recorded if any of them is invalid.
+func (h *Handler) CommitStatementImport(w http.ResponseWriter, r *http.Request) {
+	si, found := h.findStatementImport(mux.Vars(r)["id"])
+	if !found {
+		middleware.ErrorResponse(w, "Import not found", http.StatusNotFound)
+		return
+	}
+	if si.Status != models.ImportPending {
+		middleware.ErrorResponse(w, "Import is already committed", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	var expenses []models.Expense
+	var incomes []models.Income
+	for i := range si.Rows {
+		row := &si.Rows[i]
+		if row.Skip || row.Error != "" {
+			continue
+		}
+		row.RecordID = uuid.New().String()
+		addedBy, accountID := si.AddedBy, si.AccountID
+		if row.AddedBy != "" {
+			addedBy = row.AddedBy
+		}
+		if row.AccountID != "" {
+			accountID = row.AccountID
+		}
+		var err error
+		if row.Kind == models.KindExpense {
+			exp := models.Expense{
+				ID: row.RecordID, Desc: row.Description, Amount: row.Amount, Category: row.Category,
+				Date: row.Date, AddedBy: addedBy, PaymentMethod: row.PaymentMethod, AccountID: accountID, Tags: row.Tags,
+				CreatedAt: now, UpdatedAt: now,
+			}
+			err = exp.Validate()
+			expenses = append(expenses, exp)
+		} else {
+			inc := models.Income{
+				ID: row.RecordID, Source: row.Description, Amount: row.Amount, Category: row.Category,
+				Date: row.Date, AddedBy: addedBy, PaymentMethod: row.PaymentMethod, AccountID: accountID, Tags: row.Tags,
+				CreatedAt: now, UpdatedAt: now,
+			}
+			err = inc.Validate()
+			incomes = append(incomes, inc)
+		}
+		if err == nil {
+			err = h.checkAccount(accountID)
+		}
+		if err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Validation error: line %d: %v", row.Line, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, exp := range expenses {
+		if err := h.store.AddExpense(exp); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to add expense: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, inc := range incomes {
+		if err := h.store.AddIncome(inc); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to add income: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := h.store.SaveExpenses(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save expenses: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.SaveIncomes(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save incomes: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	si.Status = models.ImportCommitted
+	si.CommittedAt = now
+	si.UpdatedAt = now
+	h.saveStatementImport(w, si)
+}
+
+// DeleteStatementImport handles DELETE /api/import/statement/{id}
+// Discards a pending import; records of a committed one are kept
+func (h *Handler) DeleteStatementImport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.store.DeleteStatementImport(id); err != nil {
+		middleware.ErrorResponse(w, "Import not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveStatementImports(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save import: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Import deleted successfully")
+}
+
+// readUpload reads the multipart `file` of an upload, writing the error
+// response when there is none
+func readUpload(w http.ResponseWriter, r *http.Request) ([]byte, string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		middleware.ErrorResponse(w, "Invalid upload: "+err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		middleware.ErrorResponse(w, "A file is required", http.StatusBadRequest)
+		return nil, "", false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		middleware.ErrorResponse(w, "Failed to read upload: "+err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+	return data, header.Filename, true
+}
+
+// saveStatementImport stores an updated import and writes it back
+func (h *Handler) saveStatementImport(w http.ResponseWriter, si models.StatementImport) {
+	if err := h.store.UpdateStatementImport(si.ID, si); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update import: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveStatementImports(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save import: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, si, http.StatusOK)
+}
+
+// markDuplicates flags, and skips, rows likely to be an expense or income
+// already recorded
+func (h *Handler) markDuplicates(rows []models.StatementRow) {
+	recorded := duplicates.Records(h.store.GetExpenses(), h.store.GetIncomes())
+	for i, row := range rows {
+		if row.Error != "" {
+			continue
+		}
+		candidate := duplicates.Record{
+			Kind: row.Kind, Date: row.Date, Amount: row.Amount, Description: row.Description,
+			PaymentMethod: row.PaymentMethod,
+		}
+		if matches := duplicates.Matches(candidate, recorded, duplicates.DefaultThreshold); len(matches) > 0 {
+			rows[i].Duplicate = true
+			rows[i].DuplicateOf = matches[0].Record.ID
+			rows[i].Skip = true
+		}
+	}
+}
+
+func rowIndex(rows []models.StatementRow, line int) int {
+	for i, row := range rows {
+		if row.Line == line {
+			return i
+		}
+	}
+	return -1
+}
+
+func (h *Handler) findStatementProfile(id string) (models.StatementProfile, bool) {
+	for _, p := range h.store.GetStatementProfiles() {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return models.StatementProfile{}, false
+}
+
+func (h *Handler) findStatementImport(id string) (models.StatementImport, bool) {
+	for _, si := range h.store.GetStatementImports() {
+		if si.ID == id {
+			return si, true
+		}
+	}
+	return models.StatementImport{}, false
+}
+
+// StatementProfilesHandler routes statement profile requests
+func (h *Handler) StatementProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetStatementProfiles(w, r)
+	case "POST":
+		h.CreateStatementProfile(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// StatementProfileHandler routes single statement profile requests
+func (h *Handler) StatementProfileHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		h.UpdateStatementProfile(w, r)
+	case "DELETE":
+		h.DeleteStatementProfile(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// StatementImportsHandler routes statement import requests
+func (h *Handler) StatementImportsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetStatementImports(w, r)
+	case "POST":
+		h.UploadStatement(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// StatementImportHandler routes single statement import requests
+func (h *Handler) StatementImportHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetStatementImport(w, r)
+	case "PUT":
+		h.ReviewStatementImport(w, r)
+	case "DELETE":
+		h.DeleteStatementImport(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/tags"
+)
+
+// ----- TAGS -----
+
+// tagUsage is a tag and the records carrying it
+type tagUsage struct {
+	Tag         string `json:"tag"`
+	Registered  bool   `json:"registered"` // In the settings registry
+	Expenses    int    `json:"expenses"`
+	Incomes     int    `json:"incomes"`
+	Investments int    `json:"investments"`
+}
+
+// GetTags handles GET /api/tags
+// Registered tags and those found on records, with how often each is used
+func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
+	usage := map[string]*tagUsage{}
+	use := func(tag string) *tagUsage {
+		key := strings.ToLower(tag)
+		u, ok := usage[key]
+		if !ok {
+			u = &tagUsage{Tag: tag}
+			usage[key] = u
+		}
+		return u
+	}
+	for _, tag := range h.store.GetSettings().Tags {
+		use(tag).Registered = true
+	}
+	for _, exp := range h.store.GetExpenses() {
+		for _, tag := range exp.Tags {
+			use(tag).Expenses++
+		}
+	}
+	for _, inc := range h.store.GetIncomes() {
+		for _, tag := range inc.Tags {
+			use(tag).Incomes++
+		}
+	}
+	for _, inv := range h.store.GetInvestments() {
+		for _, tag := range inv.Tags {
+			use(tag).Investments++
+		}
+	}
+
+	list := make([]tagUsage, 0, len(usage))
+	for _, u := range usage {
+		list = append(list, *u)
+	}
+	sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i].Tag) < strings.ToLower(list[j].Tag) })
+	middleware.JSONResponse(w, list, http.StatusOK)
+}
+
+// renameRequest names the tag to rename and its new name
+type renameRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// mergeTagsRequest names the tags folded into one
+type mergeTagsRequest struct {
+	From []string `json:"from"`
+	To   string   `json:"to"`
+}
+
+// tagChange reports how many records a rename or merge changed
+type tagChange struct {
+	Tag         string `json:"tag"`
+	Expenses    int    `json:"expenses"`
+	Incomes     int    `json:"incomes"`
+	Investments int    `json:"investments"`
+	Rules       int    `json:"rules"`
+	Settlements int    `json:"settlements"`
+}
+
+// RenameTag handles POST /api/tags/rename
+// Renaming to a tag already in use merges the two
+func (h *Handler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	var req renameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.From) == "" {
+		middleware.ErrorResponse(w, "from is required", http.StatusBadRequest)
+		return
+	}
+	h.replaceTags(w, []string{req.From}, req.To)
+}
+
+// MergeTags handles POST /api/tags/merge
+func (h *Handler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	var req mergeTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.From) == 0 {
+		middleware.ErrorResponse(w, "from must list the tags to merge", http.StatusBadRequest)
+		return
+	}
+	h.replaceTags(w, req.From, req.To)
+}
+
+// replaceTags swaps the tags of from for to on every expense, income,
+// investment, rule and settlement, and in the registry
+func (h *Handler) replaceTags(w http.ResponseWriter, from []string, to string) {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		middleware.ErrorResponse(w, "to is required", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	change := tagChange{Tag: to}
+	var ok bool
+	for _, exp := range h.store.GetExpenses() {
+		if exp.Tags, ok = tags.Replace(exp.Tags, from, to); !ok {
+			continue
+		}
+		exp.UpdatedAt = now
+		if err := h.store.UpdateExpense(exp.ID, exp); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to update expense: %v", err), http.StatusInternalServerError)
+			return
+		}
+		change.Expenses++
+	}
+	for _, inc := range h.store.GetIncomes() {
+		if inc.Tags, ok = tags.Replace(inc.Tags, from, to); !ok {
+			continue
+		}
+		inc.UpdatedAt = now
+		if err := h.store.UpdateIncome(inc.ID, inc); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to update income: %v", err), http.StatusInternalServerError)
+			return
+		}
+		change.Incomes++
+	}
+	for _, inv := range h.store.GetInvestments() {
+		if inv.Tags, ok = tags.Replace(inv.Tags, from, to); !ok {
+			continue
+		}
+		inv.UpdatedAt = now
+		if err := h.store.UpdateInvestment(inv.ID, inv); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to update investment: %v", err), http.StatusInternalServerError)
+			return
+		}
+		change.Investments++
+	}
+	for _, rule := range h.store.GetRules() {
+		if rule.AddTags, ok = tags.Replace(rule.AddTags, from, to); !ok {
+			continue
+		}
+		rule.UpdatedAt = now
+		if err := h.store.UpdateRule(rule.ID, rule); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to update rule: %v", err), http.StatusInternalServerError)
+			return
+		}
+		change.Rules++
+	}
+	for _, st := range h.store.GetSettlements() {
+		if st.Tags, ok = tags.Replace(st.Tags, from, to); !ok {
+			continue
+		}
+		st.UpdatedAt = now
+		if err := h.store.UpdateSettlement(st.ID, st); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to update settlement: %v", err), http.StatusInternalServerError)
+			return
+		}
+		change.Settlements++
+	}
+
+	settings := h.store.GetSettings()
+	if settings.Tags, ok = tags.Replace(settings.Tags, from, to); ok {
+		if err := h.store.UpdateSettings(settings); err != nil {
+			middleware.ErrorResponse(w, fmt.Sprintf("Failed to update settings: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := h.store.SaveExpenses(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save expenses: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.SaveIncomes(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save incomes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.SaveInvestments(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save investments: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.SaveRules(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save rules: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.SaveSettlements(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save settlements: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.SaveSettings(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, change, http.StatusOK)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/auth"
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+)
+
+// ----- API TOKENS -----
+
+// tokenRequest is the body of a new API token
+type tokenRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expiresAt"` // Optional: RFC 3339, or YYYY-MM-DD for the end of that day (UTC)
+}
+
+// newTokenResponse is a new API token with the token itself, shown only
+// this once
+type newTokenResponse struct {
+	models.APIToken
+	Token string `json:"token"`
+}
+
+// publicToken is an API token as sent to clients, without its hash
+func publicToken(tok models.APIToken) models.APIToken {
+	tok.TokenHash = ""
+	return tok
+}
+
+// parseExpiry reads a token expiry as an RFC 3339 time
+func parseExpiry(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	expires, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		day, dayErr := time.Parse("2006-01-02", s)
+		if dayErr != nil {
+			return "", fmt.Errorf("expiresAt must be an RFC 3339 time or a date in YYYY-MM-DD format")
+		}
+		expires = day.AddDate(0, 0, 1)
+	}
+	if !expires.After(time.Now()) {
+		return "", fmt.Errorf("expiresAt must be in the future")
+	}
+	return expires.UTC().Format(time.RFC3339), nil
+}
+
+// GetTokens handles GET /api/tokens
+// The user's own API tokens; owners see everyone's
+func (h *Handler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	user, _ := middleware.CurrentUser(r)
+	tokens := []models.APIToken{}
+	for _, tok := range h.store.GetAPITokens() {
+		if tok.UserID == user.ID || roleOf(user) == models.RoleOwner {
+			tokens = append(tokens, publicToken(tok))
+		}
+	}
+	middleware.JSONResponse(w, tokens, http.StatusOK)
+}
+
+// CreateToken handles POST /api/tokens
+// The token acts as the user who created it, within its scopes
+func (h *Handler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	expiresAt, err := parseExpiry(req.ExpiresAt)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := middleware.CurrentUser(r)
+	tok, token, err := auth.NewAPIToken(user, strings.TrimSpace(req.Name), req.Scopes, expiresAt)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to create token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Validate token
+	if err := tok.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.AddAPIToken(tok); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to add token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveAPITokens(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, newTokenResponse{publicToken(tok), token}, http.StatusCreated)
+}
+
+// RevokeToken handles DELETE /api/tokens/{id}
+// Users revoke their own tokens; owners can revoke anyone's
+func (h *Handler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	user, _ := middleware.CurrentUser(r)
+	for _, tok := range h.store.GetAPITokens() {
+		if tok.ID == id && tok.UserID != user.ID && roleOf(user) != models.RoleOwner {
+			middleware.ErrorResponse(w, "Forbidden: only owners can revoke other users' tokens", http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := h.store.DeleteAPIToken(id); err != nil {
+		middleware.ErrorResponse(w, "Token not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.store.SaveAPITokens(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.SuccessMessage(w, "Token revoked successfully")
+}
+
+// TokensHandler routes API token requests
+func (h *Handler) TokensHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetTokens(w, r)
+	case "POST":
+		h.CreateToken(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"finance-tracker/internal/middleware"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/portfolio"
+)
+
+// ----- INVESTMENT TRANSACTIONS -----
+
+// GetInvestmentTransactions handles GET /api/investments/{id}/transactions
+func (h *Handler) GetInvestmentTransactions(w http.ResponseWriter, r *http.Request) {
+	inv, ok := h.findInvestment(mux.Vars(r)["id"])
+	if !ok {
+		middleware.ErrorResponse(w, "Investment not found", http.StatusNotFound)
+		return
+	}
+	middleware.JSONResponse(w, inv.Transactions, http.StatusOK)
+}
+
+// GetInvestmentLots handles GET /api/investments/{id}/lots
+// Returns the open FIFO lots and the lot-by-lot breakdown of past sales
+func (h *Handler) GetInvestmentLots(w http.ResponseWriter, r *http.Request) {
+	inv, ok := h.findInvestment(mux.Vars(r)["id"])
+	if !ok {
+		middleware.ErrorResponse(w, "Investment not found", http.StatusNotFound)
+		return
+	}
+	holding, err := portfolio.Build(inv.Transactions)
+	if err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Invalid transactions: %v", err), http.StatusInternalServerError)
+		return
+	}
+	middleware.JSONResponse(w, holding, http.StatusOK)
+}
+
+// AddInvestmentTransaction handles POST /api/investments/{id}/transactions
+func (h *Handler) AddInvestmentTransaction(w http.ResponseWriter, r *http.Request) {
+	var txn models.InvestmentTransaction
+	if err := json.NewDecoder(r.Body).Decode(&txn); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	txn.ID = uuid.New().String()
+
+	h.changeTransactions(w, mux.Vars(r)["id"], func(txns []models.InvestmentTransaction) ([]models.InvestmentTransaction, bool) {
+		return append(txns, txn), true
+	}, http.StatusCreated)
+}
+
+// UpdateInvestmentTransaction handles PUT /api/investments/{id}/transactions/{txnId}
+func (h *Handler) UpdateInvestmentTransaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var txn models.InvestmentTransaction
+	if err := json.NewDecoder(r.Body).Decode(&txn); err != nil {
+		middleware.ErrorResponse(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	txn.ID = vars["txnId"]
+
+	h.changeTransactions(w, vars["id"], func(txns []models.InvestmentTransaction) ([]models.InvestmentTransaction, bool) {
+		for i := range txns {
+			if txns[i].ID == txn.ID {
+				txns[i] = txn
+				return txns, true
+			}
+		}
+		return txns, false
+	}, http.StatusOK)
+}
+
+// DeleteInvestmentTransaction handles DELETE /api/investments/{id}/transactions/{txnId}
+func (h *Handler) DeleteInvestmentTransaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.changeTransactions(w, vars["id"], func(txns []models.InvestmentTransaction) ([]models.InvestmentTransaction, bool) {
+		for i := range txns {
+			if txns[i].ID == vars["txnId"] {
+				return append(txns[:i], txns[i+1:]...), true
+			}
+		}
+		return txns, false
+	}, http.StatusOK)
+}
+
+// changeTransactions applies edit to a copy of the holding's transactions,
+// re-derives the holding, revalues it and saves it. The updated investment
+// is returned.
+func (h *Handler) changeTransactions(w http.ResponseWriter, id string,
+	edit func([]models.InvestmentTransaction) ([]models.InvestmentTransaction, bool), status int) {
+	original, ok := h.findInvestment(id)
+	if !ok {
+		middleware.ErrorResponse(w, "Investment not found", http.StatusNotFound)
+		return
+	}
+
+	inv := original
+	txns, found := edit(append([]models.InvestmentTransaction(nil), original.Transactions...))
+	if !found {
+		middleware.ErrorResponse(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	if len(txns) == 0 {
+		middleware.ErrorResponse(w, "A holding needs at least one transaction; delete the investment instead", http.StatusBadRequest)
+		return
+	}
+	inv.Transactions = txns
+	inv.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := h.deriveHolding(&inv); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := inv.Validate(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+	var prices []models.PricePoint
+	if inv.SchemeCode != "" {
+		prices = h.store.GetPrices(inv.SchemeCode)
+	}
+	portfolio.Revalue(&inv, original, prices)
+
+	if err := h.store.UpdateInvestment(id, inv); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to update investment: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.SaveInvestments(); err != nil {
+		middleware.ErrorResponse(w, fmt.Sprintf("Failed to save investment: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	middleware.JSONResponse(w, inv, status)
+}
+
+// deriveHolding gives new transactions an ID, checks their accounts and
+// recomputes the holding's units, cost basis and realised gain from its
+// transactions
+func (h *Handler) deriveHolding(inv *models.Investment) error {
+	// Still flat: the date did not read as one for its opening purchase
+	if inv.NeedsTransactions() {
+		return fmt.Errorf("investment date must be YYYY-MM-DD")
+	}
+	for i := range inv.Transactions {
+		if inv.Transactions[i].ID == "" {
+			inv.Transactions[i].ID = uuid.New().String()
+		}
+		if err := inv.Transactions[i].Validate(); err != nil {
+			return fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		if err := h.checkAccount(inv.Transactions[i].AccountID); err != nil {
+			return fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+	}
+	_, err := portfolio.Apply(inv)
+	return err
+}
+
+// applyFlatEdit handles updates sent without a transaction list, as older
+// clients do. For a single-purchase holding the flat Invested/Units/Date are
+// taken as an edit of that purchase; holdings with more history keep their
+// transactions, which are changed through /transactions instead.
+func applyFlatEdit(updates *models.Investment, original models.Investment) {
+	if len(updates.Transactions) > 0 {
+		return
+	}
+	if len(original.Transactions) > 1 {
+		updates.Transactions = original.Transactions
+		return
+	}
+	updates.EnsureTransactions()
+	if len(original.Transactions) == 1 && len(updates.Transactions) == 1 {
+		updates.Transactions[0].ID = original.Transactions[0].ID
+	}
+}
+
+// findInvestment returns the investment with id
+func (h *Handler) findInvestment(id string) (models.Investment, bool) {
+	for _, inv := range h.store.GetInvestments() {
+		if inv.ID == id {
+			return inv, true
+		}
+	}
+	return models.Investment{}, false
+}
+
+// InvestmentTransactionsHandler routes investment transaction requests
+func (h *Handler) InvestmentTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetInvestmentTransactions(w, r)
+	case "POST":
+		h.AddInvestmentTransaction(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// InvestmentTransactionHandler routes single investment transaction requests
+func (h *Handler) InvestmentTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		h.UpdateInvestmentTransaction(w, r)
+	case "DELETE":
+		h.DeleteInvestmentTransaction(w, r)
+	default:
+		middleware.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
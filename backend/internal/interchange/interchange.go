@@ -0,0 +1,110 @@
+// Package interchange reads and writes the OFX (and Quicken's QFX) and QIF
+// files banks and other finance tools exchange, as expenses and incomes.
+package interchange
+
+import (
+	"sort"
+	"strings"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/statements"
+)
+
+const dateLayout = "2006-01-02"
+
+// Statement is the content of one file: an account's expenses and incomes.
+// When reading, records have no ID, member or timestamps yet.
+type Statement struct {
+	Account  string  // account number (OFX) or name (QIF)
+	Currency string  // ISO 4217 code, OFX only
+	From, To string  // period covered (YYYY-MM-DD); derived from the records when empty
+	Balance  float64 // closing balance, OFX only
+	Expenses []models.Expense
+	Incomes  []models.Income
+}
+
+// entry is one transaction, money out negative
+type entry struct {
+	date     string
+	amount   float64
+	fitID    string
+	desc     string
+	category string
+	method   string
+}
+
+// entries merges s's records by date, incomes first on a day. Records
+// not imported from OFX are identified by their own ID.
+func (s Statement) entries() []entry {
+	var out []entry
+	for _, inc := range s.Incomes {
+		out = append(out, entry{calc.DateOnly(inc.Date), calc.Round2(inc.Amount), fitID(inc.FITID, inc.ID), inc.Source, inc.Category, inc.PaymentMethod})
+	}
+	for _, exp := range s.Expenses {
+		out = append(out, entry{calc.DateOnly(exp.Date), -calc.Round2(exp.Amount), fitID(exp.FITID, exp.ID), exp.Desc, exp.Category, exp.PaymentMethod})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].date < out[j].date })
+	return out
+}
+
+// period is the range the statement covers; entries are in date order
+func (s Statement) period(entries []entry) (string, string) {
+	from, to := s.From, s.To
+	if len(entries) > 0 {
+		if from == "" {
+			from = entries[0].date
+		}
+		if to == "" {
+			to = entries[len(entries)-1].date
+		}
+	}
+	return from, to
+}
+
+// add appends a transaction read from a file as an expense or income;
+// zero amounts are left out
+func (s *Statement) add(e entry) {
+	if e.method == "" {
+		e.method = statements.PaymentMethod(e.desc)
+	}
+	switch {
+	case e.amount < 0:
+		if e.category == "" {
+			e.category = statements.DefaultExpenseCategory
+		}
+		s.Expenses = append(s.Expenses, models.Expense{
+			Desc: e.desc, Amount: calc.Round2(-e.amount), Category: e.category,
+			Date: e.date, PaymentMethod: e.method, FITID: e.fitID,
+		})
+	case e.amount > 0:
+		if e.category == "" {
+			e.category = statements.DefaultIncomeCategory
+		}
+		s.Incomes = append(s.Incomes, models.Income{
+			Source: e.desc, Amount: calc.Round2(e.amount), Category: e.category,
+			Date: e.date, PaymentMethod: e.method, FITID: e.fitID,
+		})
+	}
+}
+
+// describe joins a payee and memo into one description, dropping the
+// payee when the memo already starts with it
+func describe(payee, memo string) string {
+	payee = strings.Join(strings.Fields(payee), " ")
+	memo = strings.Join(strings.Fields(memo), " ")
+	switch {
+	case payee == "", strings.HasPrefix(memo, payee):
+		return memo
+	case memo == "":
+		return payee
+	}
+	return payee + " - " + memo
+}
+
+func fitID(fitID, id string) string {
+	if fitID != "" {
+		return fitID
+	}
+	return id
+}
@@ -0,0 +1,189 @@
+package interchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"finance-tracker/internal/models"
+)
+
+// Run `go test ./internal/interchange -update` to rewrite the golden files
+// after an intended change, and review the diff.
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// golden compares got with testdata/name
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func asJSON(t *testing.T, s Statement) []byte {
+	t.Helper()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(data, '\n')
+}
+
+// sample is what the exporters are given: records as stored, one of them
+// imported from OFX earlier
+func sample() Statement {
+	return Statement{
+		Account: "50100012345678",
+		From:    "2024-09-01",
+		To:      "2024-09-30",
+		Balance: 268749.5,
+		Incomes: []models.Income{
+			{ID: "inc-1", Source: "ACME CORP - SALARY SEP", Amount: 150000, Category: "Salary", Date: "2024-09-02", AddedBy: "Ravi", PaymentMethod: "Bank Transfer", FITID: "N2459876"},
+		},
+		Expenses: []models.Expense{
+			{ID: "exp-1", Desc: "UPI-SWIGGY-swiggy@icici-Food order", Amount: 1250.5, Category: "Food", Date: "2024-09-01", AddedBy: "Ravi", PaymentMethod: "UPI"},
+			{ID: "exp-2", Desc: "Dinner at Barbeque Nation & Co", Amount: 899, Category: "Food", Date: "2024-09-02T20:15:00Z", AddedBy: "Anu", PaymentMethod: "Card"},
+			{ID: "exp-3", Desc: "ATM WDL-MG ROAD", Amount: 5000, Category: "Cash", Date: "2024-09-03", AddedBy: "Ravi", PaymentMethod: "Cash"},
+		},
+	}
+}
+
+func TestReadOFX(t *testing.T) {
+	for _, name := range []string{"bank-sgml.ofx", "card-xml.qfx"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			s, err := ReadOFX(f)
+			if err != nil {
+				t.Fatalf("ReadOFX: %v", err)
+			}
+			golden(t, name+".json", asJSON(t, s))
+		})
+	}
+}
+
+func TestReadQIF(t *testing.T) {
+	tests := []struct {
+		name, dateFormat string
+	}{
+		{"quicken-us.qif", "m/d/yy"},
+		{"gnucash.qif", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			s, err := ReadQIF(f, tt.dateFormat)
+			if err != nil {
+				t.Fatalf("ReadQIF: %v", err)
+			}
+			golden(t, tt.name+".json", asJSON(t, s))
+		})
+	}
+}
+
+func TestWriteOFX(t *testing.T) {
+	var b bytes.Buffer
+	generated := time.Date(2024, 10, 1, 9, 30, 0, 0, time.UTC)
+	if err := WriteOFX(&b, sample(), generated); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "export.ofx", b.Bytes())
+}
+
+func TestWriteQIF(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteQIF(&b, sample(), ""); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "export.qif", b.Bytes())
+}
+
+// key is what survives a round trip through a file
+type key struct {
+	Date, Desc string
+	Amount     float64
+}
+
+func keys(s Statement) []key {
+	var out []key
+	for _, e := range s.entries() {
+		out = append(out, key{e.date, e.desc, e.amount})
+	}
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := sample()
+
+	var b bytes.Buffer
+	if err := WriteOFX(&b, in, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadOFX(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(s), keys(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("OFX round trip:\n got %v\nwant %v", got, want)
+	}
+	// Records keep their bank's ID, others are identified by their own
+	fitIDs := map[string]bool{}
+	for _, e := range s.entries() {
+		fitIDs[e.fitID] = true
+	}
+	for _, id := range []string{"N2459876", "exp-1", "exp-2", "exp-3"} {
+		if !fitIDs[id] {
+			t.Errorf("OFX round trip lost FITID %s", id)
+		}
+	}
+	if s.Account != in.Account || s.Balance != in.Balance {
+		t.Errorf("OFX round trip: account %q balance %v, want %q %v", s.Account, s.Balance, in.Account, in.Balance)
+	}
+
+	b.Reset()
+	if err := WriteQIF(&b, in, "yyyy-mm-dd"); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = ReadQIF(&b, "yyyy-mm-dd"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(s), keys(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("QIF round trip:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	if _, err := ReadOFX(bytes.NewBufferString("Date,Amount\n01/09/2024,100\n")); err == nil {
+		t.Error("ReadOFX accepted a CSV file")
+	}
+	bad := "<OFX><STMTTRN><TRNTYPE>DEBIT<DTPOSTED>2024<TRNAMT>-1<FITID>1</STMTTRN></OFX>"
+	if _, err := ReadOFX(bytes.NewBufferString(bad)); err == nil {
+		t.Error("ReadOFX accepted a transaction without a valid date")
+	}
+	if _, err := ReadQIF(bytes.NewBufferString("!Type:Bank\nD31/02/2024\nT-10\n^\n"), ""); err == nil {
+		t.Error("ReadQIF accepted 31 February")
+	}
+}
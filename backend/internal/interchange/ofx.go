@@ -0,0 +1,255 @@
+package interchange
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ofxNameLength is the longest NAME the OFX specification allows; longer
+// descriptions are written whole to MEMO as well
+const ofxNameLength = 32
+
+// ofxMethods maps OFX transaction types to payment methods. Other types
+// (DEBIT, CREDIT, PAYMENT, ...) are guessed from the description.
+var ofxMethods = map[string]string{
+	"ATM":         "Cash",
+	"CASH":        "Cash",
+	"POS":         "Card",
+	"XFER":        "Bank Transfer",
+	"DIRECTDEP":   "Bank Transfer",
+	"DIRECTDEBIT": "Bank Transfer",
+	"CHECK":       "Cheque",
+}
+
+// ofxTypes is the transaction type written for a payment method
+var ofxTypes = map[string]string{
+	"Cash":          "ATM",
+	"Card":          "POS",
+	"Bank Transfer": "XFER",
+	"Cheque":        "CHECK",
+}
+
+// ofxTransaction collects the fields of one STMTTRN aggregate
+type ofxTransaction struct {
+	trnType, posted, amount, fitID, name, memo string
+}
+
+// ReadOFX reads the bank and credit card transactions of an OFX or QFX
+// file, either SGML (OFX 1.x, where elements need not be closed) or XML
+// (OFX 2.x)
+func ReadOFX(r io.Reader) (Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Statement{}, err
+	}
+	start := bytes.Index(bytes.ToUpper(data), []byte("<OFX>"))
+	if start < 0 {
+		return Statement{}, errors.New("not an OFX file: no <OFX> element")
+	}
+	doc := string(data[start:])
+
+	var s Statement
+	var txn *ofxTransaction
+	var inLedger bool
+	for len(doc) > 0 {
+		open := strings.IndexByte(doc, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(doc[open:], '>')
+		if end < 0 {
+			return s, errors.New("invalid OFX: unterminated tag")
+		}
+		tag := strings.ToUpper(strings.TrimSpace(doc[open+1 : open+end]))
+		doc = doc[open+end+1:]
+		value := doc
+		if next := strings.IndexByte(doc, '<'); next >= 0 {
+			value = doc[:next]
+		}
+		value = ofxUnescape(strings.TrimSpace(value))
+
+		switch tag {
+		case "STMTTRN":
+			txn = &ofxTransaction{}
+			continue
+		case "/STMTTRN":
+			if txn != nil {
+				if err := s.addOFX(*txn); err != nil {
+					return s, err
+				}
+			}
+			txn = nil
+			continue
+		case "LEDGERBAL":
+			inLedger = true
+		case "/LEDGERBAL":
+			inLedger = false
+		}
+		if value == "" || strings.HasPrefix(tag, "/") {
+			continue
+		}
+
+		if txn != nil {
+			switch tag {
+			case "TRNTYPE":
+				txn.trnType = strings.ToUpper(value)
+			case "DTPOSTED":
+				txn.posted = value
+			case "TRNAMT":
+				txn.amount = value
+			case "FITID":
+				txn.fitID = value
+			case "NAME":
+				txn.name = value
+			case "MEMO":
+				txn.memo = value
+			}
+			continue
+		}
+		switch tag {
+		case "CURDEF":
+			if s.Currency == "" {
+				s.Currency = value
+			}
+		case "ACCTID":
+			if s.Account == "" {
+				s.Account = value
+			}
+		case "DTSTART":
+			if d, err := ofxDate(value); err == nil && (s.From == "" || d < s.From) {
+				s.From = d
+			}
+		case "DTEND":
+			if d, err := ofxDate(value); err == nil && d > s.To {
+				s.To = d
+			}
+		case "BALAMT":
+			if inLedger {
+				s.Balance, _ = ofxAmount(value)
+			}
+		}
+	}
+	return s, nil
+}
+
+// addOFX adds a transaction read from a STMTTRN aggregate
+func (s *Statement) addOFX(t ofxTransaction) error {
+	date, err := ofxDate(t.posted)
+	if err != nil {
+		return fmt.Errorf("transaction %s: %w", t.fitID, err)
+	}
+	amount, err := ofxAmount(t.amount)
+	if err != nil {
+		return fmt.Errorf("transaction %s: %w", t.fitID, err)
+	}
+	s.add(entry{
+		date:   date,
+		amount: amount,
+		fitID:  t.fitID,
+		desc:   describe(t.name, t.memo),
+		method: ofxMethods[t.trnType],
+	})
+	return nil
+}
+
+// ofxDate reads the date of an OFX datetime such as 20240903,
+// 20240903101500 or 20240903101500.000[+5.30:IST]
+func ofxDate(s string) (string, error) {
+	if len(s) >= 8 {
+		if t, err := time.Parse("20060102", s[:8]); err == nil {
+			return t.Format(dateLayout), nil
+		}
+	}
+	return "", fmt.Errorf("%q is not an OFX date", s)
+}
+
+func ofxAmount(s string) (float64, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "+")
+	if !strings.Contains(s, ".") {
+		// Some servers use a decimal comma
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an OFX amount", s)
+	}
+	return v, nil
+}
+
+var ofxUnescape = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ", "&amp;", "&").Replace
+
+var ofxEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+// WriteOFX writes s as an OFX 2.1.1 bank statement. generated is the
+// server time the file reports.
+func WriteOFX(w io.Writer, s Statement, generated time.Time) error {
+	entries := s.entries()
+	from, to := s.period(entries)
+	currency := s.Currency
+	if currency == "" {
+		currency = "INR"
+	}
+	account := s.Account
+	if account == "" {
+		account = "0"
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	b.WriteString(`<?OFX OFXHEADER="200" VERSION="211" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	b.WriteString("<OFX>\n")
+	b.WriteString("  <SIGNONMSGSRSV1>\n    <SONRS>\n")
+	b.WriteString("      <STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	fmt.Fprintf(&b, "      <DTSERVER>%s</DTSERVER>\n", generated.UTC().Format("20060102150405"))
+	b.WriteString("      <LANGUAGE>ENG</LANGUAGE>\n")
+	b.WriteString("    </SONRS>\n  </SIGNONMSGSRSV1>\n")
+	b.WriteString("  <BANKMSGSRSV1>\n    <STMTTRNRS>\n")
+	b.WriteString("      <TRNUID>0</TRNUID>\n")
+	b.WriteString("      <STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	b.WriteString("      <STMTRS>\n")
+	fmt.Fprintf(&b, "        <CURDEF>%s</CURDEF>\n", ofxEscape(currency))
+	fmt.Fprintf(&b, "        <BANKACCTFROM><BANKID>0</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>SAVINGS</ACCTTYPE></BANKACCTFROM>\n", ofxEscape(account))
+	b.WriteString("        <BANKTRANLIST>\n")
+	fmt.Fprintf(&b, "          <DTSTART>%s</DTSTART>\n", ofxFormatDate(from))
+	fmt.Fprintf(&b, "          <DTEND>%s</DTEND>\n", ofxFormatDate(to))
+	for _, e := range entries {
+		trnType := ofxTypes[e.method]
+		if trnType == "" {
+			trnType = "CREDIT"
+			if e.amount < 0 {
+				trnType = "DEBIT"
+			}
+		}
+		b.WriteString("          <STMTTRN>\n")
+		fmt.Fprintf(&b, "            <TRNTYPE>%s</TRNTYPE>\n", trnType)
+		fmt.Fprintf(&b, "            <DTPOSTED>%s</DTPOSTED>\n", ofxFormatDate(e.date))
+		fmt.Fprintf(&b, "            <TRNAMT>%.2f</TRNAMT>\n", e.amount)
+		fmt.Fprintf(&b, "            <FITID>%s</FITID>\n", ofxEscape(e.fitID))
+		name := e.desc
+		if utf8.RuneCountInString(name) > ofxNameLength {
+			name = string([]rune(name)[:ofxNameLength])
+		}
+		fmt.Fprintf(&b, "            <NAME>%s</NAME>\n", ofxEscape(name))
+		if name != e.desc {
+			fmt.Fprintf(&b, "            <MEMO>%s</MEMO>\n", ofxEscape(e.desc))
+		}
+		b.WriteString("          </STMTTRN>\n")
+	}
+	b.WriteString("        </BANKTRANLIST>\n")
+	fmt.Fprintf(&b, "        <LEDGERBAL><BALAMT>%.2f</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n", s.Balance, ofxFormatDate(to))
+	b.WriteString("      </STMTRS>\n    </STMTTRNRS>\n  </BANKMSGSRSV1>\n")
+	b.WriteString("</OFX>\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func ofxFormatDate(date string) string {
+	return strings.ReplaceAll(date, "-", "")
+}
@@ -0,0 +1,165 @@
+package interchange
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"finance-tracker/internal/statements"
+)
+
+// qifDateFormat is the date format written when none is given
+const qifDateFormat = "dd/mm/yyyy"
+
+// qifTransfer is the category of QIF transactions that move money to
+// another account ("[Savings]")
+const qifTransfer = "Transfer"
+
+// ReadQIF reads the bank, cash and credit card transactions of a QIF file.
+// QIF dates carry no format, so dateFormat (as in statement profiles, e.g.
+// "mm/dd/yyyy" for US Quicken files) says how to read them; empty reads
+// them day first. Investment sections and category lists are skipped.
+func ReadQIF(r io.Reader, dateFormat string) (Statement, error) {
+	var s Statement
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	section := ""
+	fields := map[byte]string{}
+	lineNo, recordLine := 0, 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimRight(sc.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] == '!' {
+			section = qifSection(line)
+			fields = map[byte]string{}
+			continue
+		}
+		if line[0] == '^' {
+			switch section {
+			case "transactions":
+				if err := s.addQIF(fields, dateFormat); err != nil {
+					return s, fmt.Errorf("line %d: %w", recordLine, err)
+				}
+			case "account":
+				if s.Account == "" {
+					s.Account = fields['N']
+				}
+			}
+			fields = map[byte]string{}
+			continue
+		}
+		if len(fields) == 0 {
+			recordLine = lineNo
+		}
+		code := line[0]
+		if _, seen := fields[code]; !seen {
+			// Split lines (S, E, $) repeat; the record's total is enough
+			fields[code] = strings.TrimSpace(line[1:])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return s, err
+	}
+	if section == "transactions" && fields['D'] != "" {
+		// Files cut short may not end their last record
+		if err := s.addQIF(fields, dateFormat); err != nil {
+			return s, fmt.Errorf("line %d: %w", recordLine, err)
+		}
+	}
+	return s, nil
+}
+
+// qifSection classifies a header line: "transactions" for the non-investment
+// account types, "account" for account blocks, "" for anything skipped
+func qifSection(header string) string {
+	h := strings.ToLower(strings.TrimSpace(header))
+	switch {
+	case h == "!account":
+		return "account"
+	case h == "!type:bank", h == "!type:cash", h == "!type:ccard", h == "!type:oth a", h == "!type:oth l":
+		return "transactions"
+	}
+	return ""
+}
+
+// addQIF adds a transaction record
+func (s *Statement) addQIF(fields map[byte]string, dateFormat string) error {
+	// Quicken writes 1/ 5'24 for 1/5/2024
+	raw := strings.NewReplacer("'", "/", " ", "").Replace(fields['D'])
+	date, err := statements.ParseDate(raw, dateFormat)
+	if err != nil {
+		return err
+	}
+	total := fields['T']
+	if total == "" {
+		total = fields['U']
+	}
+	amount, ok, err := statements.ParseAmount(total)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("transaction on %s has no amount", fields['D'])
+	}
+
+	category := fields['L']
+	if i := strings.IndexByte(category, '/'); i >= 0 {
+		// Category/Class
+		category = category[:i]
+	}
+	if strings.HasPrefix(category, "[") {
+		category = qifTransfer
+	}
+	s.add(entry{
+		date:     date.Format(dateLayout),
+		amount:   amount,
+		desc:     describe(fields['P'], fields['M']),
+		category: category,
+	})
+	return nil
+}
+
+// WriteQIF writes s as a QIF bank account with dates in dateFormat (as in
+// statement profiles; default dd/mm/yyyy)
+func WriteQIF(w io.Writer, s Statement, dateFormat string) error {
+	if dateFormat == "" {
+		dateFormat = qifDateFormat
+	}
+	layout := statements.Layout(dateFormat)
+
+	var b bytes.Buffer
+	if s.Account != "" {
+		fmt.Fprintf(&b, "!Account\nN%s\nTBank\n^\n", qifText(s.Account))
+	}
+	b.WriteString("!Type:Bank\n")
+	for _, e := range s.entries() {
+		date, err := statements.ParseDate(e.date, "yyyy-mm-dd")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "D%s\n", date.Format(layout))
+		fmt.Fprintf(&b, "T%.2f\n", e.amount)
+		fmt.Fprintf(&b, "P%s\n", qifText(e.desc))
+		if e.category != "" {
+			fmt.Fprintf(&b, "L%s\n", qifText(e.category))
+		}
+		b.WriteString("^\n")
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// qifText keeps a value on its line
+func qifText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,207 @@
+package loans
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/capgains"
+	"finance-tracker/internal/models"
+)
+
+// Deduction limits per taxpayer
+const (
+	Limit24B = 200000 // Interest on a self-occupied home
+	Limit80C = 150000 // Shared with PPF, ELSS, insurance and the like
+)
+
+// Link marks the rows of s paid by the EMI expenses recorded against loanID
+func Link(s *Schedule, loanID string, expenses []models.Expense) {
+	for _, exp := range expenses {
+		if exp.LoanID != loanID || exp.Instalment < 1 || exp.Instalment > len(s.Rows) {
+			continue
+		}
+		s.Rows[exp.Instalment-1].ExpenseID = exp.ID
+	}
+}
+
+// Status is the state of a loan on a date
+type Status struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Lender    string  `json:"lender"`
+	Member    string  `json:"member"`
+	Purpose   string  `json:"purpose"`
+	Principal float64 `json:"principal"`
+	Rate      float64 `json:"rate"` // Rate in force on the date
+	EMI       float64 `json:"emi"`  // Current EMI
+
+	Outstanding     float64 `json:"outstanding"` // Principal still owed
+	PrincipalRepaid float64 `json:"principalRepaid"`
+	InterestPaid    float64 `json:"interestPaid"`
+	Prepaid         float64 `json:"prepaid"`
+
+	InstalmentsDue       int    `json:"instalmentsDue"`       // EMIs dated on or before the date
+	Unrecorded           int    `json:"unrecorded"`           // Of those, EMIs with no linked expense
+	RemainingInstalments int    `json:"remainingInstalments"` // EMIs after the date
+	NextEMI              *Row   `json:"nextEmi"`
+	EndDate              string `json:"endDate"`
+
+	TotalInterest float64 `json:"totalInterest"` // Over the whole schedule
+}
+
+// Compute derives the status of loan on asOf from its linked schedule
+func Compute(loan models.Loan, s Schedule, asOf time.Time) Status {
+	day := asOf.Format(dateLayout)
+	st := Status{
+		ID:            loan.ID,
+		Name:          loan.Name,
+		Lender:        loan.Lender,
+		Member:        loan.Member,
+		Purpose:       loan.Purpose,
+		Principal:     loan.Principal,
+		Rate:          loan.Rate,
+		EMI:           s.EMI,
+		EndDate:       s.EndDate,
+		TotalInterest: s.TotalInterest,
+	}
+
+	for i, row := range s.Rows {
+		if row.Date > day {
+			if st.NextEMI == nil {
+				st.NextEMI = &s.Rows[i]
+				st.EMI = row.EMI
+			}
+			st.RemainingInstalments++
+			continue
+		}
+		st.InstalmentsDue++
+		st.Rate = row.Rate
+		st.PrincipalRepaid += row.Principal
+		st.InterestPaid += row.Interest
+		if row.ExpenseID == "" {
+			st.Unrecorded++
+		}
+	}
+	for _, p := range loan.Prepayments {
+		if p.Date <= day {
+			st.Prepaid += p.Amount
+		}
+	}
+	st.Prepaid = calc.Round2(math.Min(st.Prepaid, loan.Principal-st.PrincipalRepaid))
+	st.PrincipalRepaid = calc.Round2(st.PrincipalRepaid)
+	st.InterestPaid = calc.Round2(st.InterestPaid)
+	st.Outstanding = calc.Round2(math.Max(0, loan.Principal-st.PrincipalRepaid-st.Prepaid))
+	return st
+}
+
+// Deduction is the interest and principal repaid on one loan in a financial
+// year, with the sections they count under before per-taxpayer limits
+type Deduction struct {
+	LoanID     string  `json:"loanId"`
+	Name       string  `json:"name"`
+	Member     string  `json:"member"`
+	Purpose    string  `json:"purpose"`
+	Interest   float64 `json:"interest"`
+	Principal  float64 `json:"principal"` // EMI principal plus prepayments made in the year
+	Section24B float64 `json:"section24b"`
+	Section80C float64 `json:"section80c"`
+	Section80E float64 `json:"section80e"`
+}
+
+// MemberTotals are one taxpayer's deductions with the limits applied
+type MemberTotals struct {
+	Member     string  `json:"member"`
+	Section24B float64 `json:"section24b"`
+	Section80C float64 `json:"section80c"`
+	Section80E float64 `json:"section80e"`
+}
+
+// TaxReport lists loan deductions for a financial year
+type TaxReport struct {
+	FinancialYear string         `json:"financialYear"`
+	Loans         []Deduction    `json:"loans"`
+	Members       []MemberTotals `json:"members"`
+	Notes         []string       `json:"notes"`
+}
+
+// Tax builds the deduction report for fy. Home loan interest counts under
+// Sec 24(b), capped at ₹2 lakh per taxpayer for a self-occupied home, and
+// home loan principal under Sec 80C, capped at ₹1.5 lakh together with other
+// 80C investments. Education loan interest counts under Sec 80E without a
+// limit. Figures follow the EMI due dates, which is when lenders certify
+// them.
+func Tax(list []models.Loan, fy capgains.FinancialYear) TaxReport {
+	from, to := fy.From().Format(dateLayout), fy.To().Format(dateLayout)
+	report := TaxReport{
+		FinancialYear: fy.String(),
+		Loans:         []Deduction{},
+		Members:       []MemberTotals{},
+		Notes: []string{
+			"The 80C limit is shared with other 80C investments such as PPF, ELSS and life insurance",
+			"Interest on a let-out home is deductible in full, but the loss set off against other income is capped at ₹2 lakh",
+			"Sec 24(b) and 80C deductions are not available under the new tax regime for a self-occupied home",
+		},
+	}
+
+	// Self-occupied interest is capped per taxpayer; let-out interest is not
+	type totals struct {
+		selfOccupied, letOut, principal, education float64
+	}
+	members := map[string]*totals{}
+	for _, loan := range list {
+		if loan.Purpose != models.LoanHome && loan.Purpose != models.LoanEducation {
+			continue
+		}
+		d := Deduction{LoanID: loan.ID, Name: loan.Name, Member: loan.Member, Purpose: loan.Purpose}
+		for _, row := range Build(loan).Rows {
+			if row.Date >= from && row.Date <= to {
+				d.Interest += row.Interest
+				d.Principal += row.Principal
+			}
+		}
+		for _, p := range loan.Prepayments {
+			if p.Date >= from && p.Date <= to {
+				d.Principal += p.Amount
+			}
+		}
+		d.Interest = calc.Round2(d.Interest)
+		d.Principal = calc.Round2(d.Principal)
+		if d.Interest == 0 && d.Principal == 0 {
+			continue
+		}
+
+		t := members[loan.Member]
+		if t == nil {
+			t = &totals{}
+			members[loan.Member] = t
+		}
+		switch loan.Purpose {
+		case models.LoanHome:
+			d.Section24B = d.Interest
+			d.Section80C = d.Principal
+			if loan.SelfOccupied {
+				t.selfOccupied += d.Interest
+			} else {
+				t.letOut += d.Interest
+			}
+			t.principal += d.Principal
+		case models.LoanEducation:
+			d.Section80E = d.Interest
+			t.education += d.Interest
+		}
+		report.Loans = append(report.Loans, d)
+	}
+
+	for member, t := range members {
+		report.Members = append(report.Members, MemberTotals{
+			Member:     member,
+			Section24B: calc.Round2(math.Min(t.selfOccupied, Limit24B) + t.letOut),
+			Section80C: calc.Round2(math.Min(t.principal, Limit80C)),
+			Section80E: calc.Round2(t.education),
+		})
+	}
+	sort.Slice(report.Members, func(i, j int) bool { return report.Members[i].Member < report.Members[j].Member })
+	return report
+}
@@ -0,0 +1,191 @@
+// Package loans builds amortization schedules for EMI loans, replaying
+// prepayments and rate changes, and derives the outstanding principal and
+// the interest/principal split used for tax deductions.
+package loans
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// maxRows bounds a schedule whose EMI barely covers the interest
+const maxRows = 1200
+
+// Row is one EMI of the schedule
+type Row struct {
+	N          int     `json:"n"` // Instalment number, from 1
+	Date       string  `json:"date"`
+	Rate       float64 `json:"rate"` // Annual rate applied, percent
+	Opening    float64 `json:"opening"`
+	EMI        float64 `json:"emi"`
+	Interest   float64 `json:"interest"`
+	Principal  float64 `json:"principal"`
+	Prepayment float64 `json:"prepayment"` // Part-payments made after this EMI
+	Closing    float64 `json:"closing"`
+	ExpenseID  string  `json:"expenseId,omitempty"` // Recorded EMI payment
+}
+
+// Schedule is the full repayment plan of a loan
+type Schedule struct {
+	EMI           float64 `json:"emi"` // Current EMI
+	Rows          []Row   `json:"rows"`
+	TotalInterest float64 `json:"totalInterest"`
+	TotalPrepaid  float64 `json:"totalPrepaid"`
+	EndDate       string  `json:"endDate"`
+}
+
+// EMI is the equal monthly instalment repaying principal over months at an
+// annual rate (percent)
+func EMI(principal, rate float64, months int) float64 {
+	if months <= 0 {
+		return principal
+	}
+	r := rate / 1200
+	if r == 0 {
+		return calc.Round2(principal / float64(months))
+	}
+	f := math.Pow(1+r, float64(months))
+	return calc.Round2(principal * r * f / (f - 1))
+}
+
+// monthsLeft is how many EMIs of emi repay balance at rate
+func monthsLeft(balance, rate, emi float64) int {
+	r := rate / 1200
+	if r == 0 {
+		return int(math.Ceil(balance/emi - 1e-9))
+	}
+	if emi <= balance*r {
+		return maxRows
+	}
+	n := -math.Log(1-balance*r/emi) / math.Log(1+r)
+	return int(math.Ceil(n - 1e-9))
+}
+
+// DueDate is the date of EMI n (from 1), on the start date's day of month or
+// the last day of shorter months
+func DueDate(loan models.Loan, n int) time.Time {
+	start, _ := time.Parse(dateLayout, loan.StartDate)
+	return calc.AddMonths(start, n-1)
+}
+
+// Build computes the schedule of loan. Rate changes apply from the first EMI
+// due on or after their date; prepayments are applied after the last EMI on
+// or before their date (or before the first EMI). Either keeps the EMI and
+// shortens the tenure unless Adjust is "emi", which keeps the current end
+// date and recomputes the EMI. A prepayment covering the balance closes the
+// loan.
+func Build(loan models.Loan) Schedule {
+	rates := append([]models.LoanRateChange(nil), loan.RateChanges...)
+	sort.SliceStable(rates, func(i, j int) bool { return rates[i].Date < rates[j].Date })
+	prepayments := append([]models.LoanPrepayment(nil), loan.Prepayments...)
+	sort.SliceStable(prepayments, func(i, j int) bool { return prepayments[i].Date < prepayments[j].Date })
+
+	s := Schedule{Rows: []Row{}}
+	balance := loan.Principal
+	rate := loan.Rate
+	emi := loan.EMI
+	if emi == 0 {
+		emi = EMI(balance, rate, loan.Tenure)
+	}
+
+	ri, pi := 0, 0
+	for n := 1; balance > 0.005 && n <= maxRows; n++ {
+		date := DueDate(loan, n).Format(dateLayout)
+
+		// Part-payments made before the first EMI reduce the amount amortized
+		for n == 1 && pi < len(prepayments) && prepayments[pi].Date < date {
+			amount := math.Min(prepayments[pi].Amount, balance)
+			balance, emi = prepay(balance, rate, emi, amount, prepayments[pi].Adjust)
+			s.TotalPrepaid += amount
+			pi++
+		}
+		if balance <= 0.005 {
+			break
+		}
+
+		for ri < len(rates) && rates[ri].Date <= date {
+			if rates[ri].Adjust == models.AdjustEMI {
+				emi = EMI(balance, rates[ri].Rate, monthsLeft(balance, rate, emi))
+			}
+			rate = rates[ri].Rate
+			ri++
+		}
+		// An EMI that no longer covers the interest would never repay the
+		// loan; lenders raise it to keep the original end date
+		if emi <= calc.Round2(balance*rate/1200) {
+			emi = EMI(balance, rate, max(loan.Tenure-n+1, 1))
+		}
+
+		row := Row{N: n, Date: date, Rate: rate, Opening: calc.Round2(balance), EMI: emi}
+		row.Interest = calc.Round2(balance * rate / 1200)
+		row.Principal = calc.Round2(emi - row.Interest)
+		// The last EMI also clears what paise rounding left over
+		if row.Principal >= balance-emi/100 || n == maxRows {
+			row.Principal = calc.Round2(balance)
+			row.EMI = calc.Round2(row.Principal + row.Interest)
+		}
+		balance = calc.Round2(balance - row.Principal)
+
+		next := DueDate(loan, n+1).Format(dateLayout)
+		for pi < len(prepayments) && prepayments[pi].Date < next && balance > 0 {
+			amount := math.Min(prepayments[pi].Amount, balance)
+			balance, emi = prepay(balance, rate, emi, amount, prepayments[pi].Adjust)
+			row.Prepayment = calc.Round2(row.Prepayment + amount)
+			s.TotalPrepaid += amount
+			pi++
+		}
+		row.Closing = balance
+		s.TotalInterest += row.Interest
+		s.Rows = append(s.Rows, row)
+	}
+
+	s.EMI = emi
+	s.TotalInterest = calc.Round2(s.TotalInterest)
+	s.TotalPrepaid = calc.Round2(s.TotalPrepaid)
+	if len(s.Rows) > 0 {
+		s.EndDate = s.Rows[len(s.Rows)-1].Date
+	}
+	return s
+}
+
+// prepay reduces balance by amount and returns the new balance and EMI
+func prepay(balance, rate, emi, amount float64, adjust string) (float64, float64) {
+	left := monthsLeft(balance, rate, emi)
+	balance = calc.Round2(math.Max(0, balance-amount))
+	if adjust == models.AdjustEMI && balance > 0 {
+		emi = EMI(balance, rate, left)
+	}
+	return balance, emi
+}
+
+// Instalment is the number of the EMI falling in the same month as date, or
+// the nearest one before or after the schedule
+func Instalment(s Schedule, date string) int {
+	if len(s.Rows) == 0 || len(date) < len("2006-01") {
+		return 0
+	}
+	month := date[:len("2006-01")]
+	for _, row := range s.Rows {
+		if row.Date[:len("2006-01")] == month {
+			return row.N
+		}
+	}
+	if date < s.Rows[0].Date {
+		return 1
+	}
+	if date > s.Rows[len(s.Rows)-1].Date {
+		return len(s.Rows)
+	}
+	for _, row := range s.Rows {
+		if row.Date >= date {
+			return row.N
+		}
+	}
+	return 0
+}
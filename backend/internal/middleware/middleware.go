@@ -1,20 +1,86 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"strings"
+
+	"finance-tracker/internal/models"
+)
+
+// CORS middleware lets browsers on the given origins (e.g.
+// "http://192.168.1.100:5173") call the API, sending the session cookie
+// along. Other origins get no CORS headers, so browsers block their requests.
+func CORS(origins ...string) func(http.Handler) http.Handler {
+	allowed := map[string]bool{}
+	for _, origin := range origins {
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			if origin := r.Header.Get("Origin"); allowed[origin] {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			}
+
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Auth middleware rejects requests authenticate does not accept with 401,
+// apart from CORS preflight requests and the public paths (e.g. login).
+// The user, and the scopes of the API token used if any, are passed on in
+// the request context; see CurrentUser and TokenScopes.
+func Auth(authenticate func(*http.Request) (models.User, []string, error), public ...string) func(http.Handler) http.Handler {
+	open := map[string]bool{}
+	for _, path := range public {
+		open[path] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "OPTIONS" || open[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			user, scopes, err := authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="finance-tracker"`)
+				ErrorResponse(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+				return
+			}
+			ctx := context.WithValue(r.Context(), userKey{}, user)
+			if scopes != nil {
+				ctx = context.WithValue(ctx, scopesKey{}, scopes)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// userKey and scopesKey key the authenticated user and their API token's
+// scopes in a request context
+type (
+	userKey   struct{}
+	scopesKey struct{}
 )
 
-// CORS middleware adds CORS headers to responses (Mux-compatible)
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
+// CurrentUser is the user Auth authenticated for the request
+func CurrentUser(r *http.Request) (models.User, bool) {
+	user, ok := r.Context().Value(userKey{}).(models.User)
+	return user, ok
+}
+
+// TokenScopes are the scopes of the API token the request was made with.
+// ok is false for requests made with a login session.
+func TokenScopes(r *http.Request) (scopes []string, ok bool) {
+	scopes, ok = r.Context().Value(scopesKey{}).([]string)
+	return scopes, ok
 }
@@ -11,6 +11,16 @@ type APIResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
 	Message string      `json:"message,omitempty"`
+	Meta    *PageMeta   `json:"meta,omitempty"`
+}
+
+// PageMeta describes the page returned by a paginated list endpoint
+type PageMeta struct {
+	Total      int  `json:"total"`                // Matches across all pages
+	Count      int  `json:"count"`                // Items in this page
+	Limit      int  `json:"limit"`                // Requested page size (0 = all)
+	Offset     int  `json:"offset"`               // Items skipped
+	NextOffset *int `json:"nextOffset,omitempty"` // Offset of the next page, if any
 }
 
 // JSONResponse sends a successful JSON response
@@ -24,6 +34,18 @@ func JSONResponse(w http.ResponseWriter, data interface{}, status int) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// PagedResponse sends a successful JSON response with pagination metadata
+func PagedResponse(w http.ResponseWriter, data interface{}, meta PageMeta) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	resp := APIResponse{
+		Success: true,
+		Data:    data,
+		Meta:    &meta,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
 // ErrorResponse sends an error JSON response
 func ErrorResponse(w http.ResponseWriter, message string, status int) {
 	w.Header().Set("Content-Type", "application/json")
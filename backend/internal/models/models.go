@@ -1,43 +1,115 @@
 package models
 
-// Investment represents one investment entry
+// Investment represents one holding. Units, Invested, TotalInvested and
+// RealizedGain are derived from Transactions (see package portfolio); Date is
+// the first transaction date.
 type Investment struct {
-	ID         string  `json:"id"`         // Unique identifier
-	Name       string  `json:"name"`       // e.g., "HDFC Flexi Cap"
-	Type       string  `json:"type"`       // e.g., "Mutual Fund"
-	Invested   float64 `json:"invested"`   // Amount invested
-	Current    float64 `json:"current"`    // Current value
-	Date       string  `json:"date"`       // Purchase date
-	SchemeCode string  `json:"schemeCode"` // MF API scheme code for NAV updates
-	Units      float64 `json:"units"`      // Number of units purchased
-	CreatedAt  string  `json:"createdAt"`  // When record was created
-	UpdatedAt  string  `json:"updatedAt"`  // When record was last updated
+	ID            string                  `json:"id"`            // Unique identifier
+	Name          string                  `json:"name"`          // e.g., "HDFC Flexi Cap"
+	Type          string                  `json:"type"`          // e.g., "Mutual Fund"
+	Invested      float64                 `json:"invested"`      // Cost basis of the units still held
+	Current       float64                 `json:"current"`       // Current value
+	Date          string                  `json:"date"`          // Purchase date
+	SchemeCode    string                  `json:"schemeCode"`    // MF API scheme code for NAV updates
+	Units         float64                 `json:"units"`         // Number of units held
+	TotalInvested float64                 `json:"totalInvested"` // Cash paid in over the holding's life
+	RealizedGain  float64                 `json:"realizedGain"`  // Gain booked on units sold
+	Transactions  []InvestmentTransaction `json:"transactions"`
+
+	// Capital gains: AssetClass defaults from Type; GrandfatheredFMV is the
+	// per-unit market value on 31 Jan 2018 for equity bought before then
+	AssetClass       string  `json:"assetClass,omitempty"`
+	GrandfatheredFMV float64 `json:"grandfatheredFmv,omitempty"`
+
+	// Fixed and recurring deposits: terms that keep Current accrued
+	Deposit *Deposit `json:"deposit,omitempty"`
+
+	Tags []string `json:"tags,omitempty"` // e.g., "retirement", "tax-saving"
+
+	CreatedAt string `json:"createdAt"` // When record was created
+	UpdatedAt string `json:"updatedAt"` // When record was last updated
+}
+
+// Deposit holds the terms of a fixed (FD) or recurring (RD) deposit. An FD's
+// principal is the holding's purchase; an RD's monthly instalments are added
+// as SIP transactions as they fall due.
+type Deposit struct {
+	Kind         string  `json:"kind"`         // "fd" or "rd"
+	Rate         float64 `json:"rate"`         // Annual interest rate, percent
+	Compounding  string  `json:"compounding"`  // "monthly", "quarterly" (default), "half_yearly" or "yearly"
+	Tenure       int     `json:"tenure"`       // Months
+	Payout       string  `json:"payout"`       // "cumulative" (default) or a payout frequency
+	MaturityDate string  `json:"maturityDate"` // Defaults to the start date plus the tenure
+	TDSRate      float64 `json:"tdsRate"`      // Tax deducted at source on interest, percent
+	Instalment   float64 `json:"instalment"`   // RD: monthly deposit
+	PostIncome   bool    `json:"postIncome"`   // Post interest as income and close the holding on maturity
+	Member       string  `json:"member"`       // Added by, for posted income
+}
+
+// InvestmentTransaction is one buy, sell or corporate action on a holding.
+// Holdings not priced per unit (FDs, chits) may leave Units at 0 throughout,
+// in which case the amount itself is matched FIFO.
+type InvestmentTransaction struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"` // One of the Txn* constants
+	Date      string  `json:"date"` // YYYY-MM-DD
+	Units     float64 `json:"units"`
+	Amount    float64 `json:"amount"`             // Cash paid (buys) or received (sells)
+	Ratio     float64 `json:"ratio,omitempty"`    // Split: new units per old unit
+	CostSold  float64 `json:"costSold,omitempty"` // Sales without units: cost of the part sold; 0 sells it all
+	Note      string  `json:"note,omitempty"`
+	AccountID string  `json:"accountId,omitempty"` // Account paid from or into
 }
 
 // Income represents one income entry
 type Income struct {
-	ID            string  `json:"id"`
-	Source        string  `json:"source"`        // e.g., "Salary", "Rent", "Freelance"
-	Amount        float64 `json:"amount"`        // How much received
-	Category      string  `json:"category"`      // e.g., "Salary", "Business", "Rental"
-	Date          string  `json:"date"`          // When received
-	AddedBy       string  `json:"addedBy"`       // Who added this
-	PaymentMethod string  `json:"paymentMethod"` // e.g., "Online", "Cash", "UPI"
-	CreatedAt     string  `json:"createdAt"`
-	UpdatedAt     string  `json:"updatedAt"`
+	ID            string   `json:"id"`
+	Source        string   `json:"source"`              // e.g., "Salary", "Rent", "Freelance"
+	Amount        float64  `json:"amount"`              // How much received
+	Category      string   `json:"category"`            // e.g., "Salary", "Business", "Rental"
+	Date          string   `json:"date"`                // When received
+	AddedBy       string   `json:"addedBy"`             // Who added this
+	PaymentMethod string   `json:"paymentMethod"`       // e.g., "Online", "Cash", "UPI"
+	AccountID     string   `json:"accountId,omitempty"` // Account credited
+	FITID         string   `json:"fitId,omitempty"`     // Bank's transaction ID, for records imported from OFX
+	Tags          []string `json:"tags,omitempty"`      // e.g., "Diwali", "tax-deductible"
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
 }
 
 // Expense represents one expense entry
 type Expense struct {
-	ID            string  `json:"id"`
-	Desc          string  `json:"desc"`          // Description
-	Amount        float64 `json:"amount"`        // How much spent
-	Category      string  `json:"category"`      // e.g., "Food", "Transport"
-	Date          string  `json:"date"`          // When spent
-	AddedBy       string  `json:"addedBy"`       // Who added this (for family sharing)
-	PaymentMethod string  `json:"paymentMethod"` // e.g., "Online", "Cash", "UPI"
-	CreatedAt     string  `json:"createdAt"`
-	UpdatedAt     string  `json:"updatedAt"`
+	ID            string   `json:"id"`
+	Desc          string   `json:"desc"`                 // Description
+	Amount        float64  `json:"amount"`               // How much spent
+	Category      string   `json:"category"`             // e.g., "Food", "Transport"
+	Date          string   `json:"date"`                 // When spent
+	AddedBy       string   `json:"addedBy"`              // Who added this (for family sharing)
+	PaymentMethod string   `json:"paymentMethod"`        // e.g., "Online", "Cash", "UPI"
+	LoanID        string   `json:"loanId,omitempty"`     // EMI payments: the loan repaid
+	Instalment    int      `json:"instalment,omitempty"` // EMI payments: schedule row number
+	AccountID     string   `json:"accountId,omitempty"`  // Account debited
+	FITID         string   `json:"fitId,omitempty"`      // Bank's transaction ID, for records imported from OFX
+	Tags          []string `json:"tags,omitempty"`       // e.g., "Goa trip 2026", "reimbursable"
+	Splits        []Split  `json:"splits,omitempty"`     // Lines of a bill covering several categories or members
+
+	// Shared expenses: who paid and whose the cost is. Without SharedAmong
+	// the expense is the payer's own.
+	PaidBy      string  `json:"paidBy,omitempty"`    // Member who paid (default: AddedBy)
+	ShareMode   string  `json:"shareMode,omitempty"` // "equal", "percent" or "exact"
+	SharedAmong []Share `json:"sharedAmong,omitempty"`
+
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// Split is one line of a split expense. Lines add up to the expense's
+// amount; an empty category or member is the expense's own.
+type Split struct {
+	Amount   float64 `json:"amount"`
+	Category string  `json:"category"`
+	Member   string  `json:"member"`
+	Note     string  `json:"note,omitempty"` // e.g., "Birthday gift for Anu"
 }
 
 // Settings stores app configuration
@@ -47,14 +119,368 @@ type Settings struct {
 	IncomeCategories []string `json:"incomeCategories"` // Income categories
 	PaymentMethods   []string `json:"paymentMethods"`   // Payment methods
 	Members          []string `json:"members"`          // Family members
+	Tags             []string `json:"tags"`             // Tags offered when labelling records
+}
+
+// Budget is a spending limit for one category or a group of categories
+type Budget struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`           // e.g., "Food", "Eating out & groceries"
+	Categories     []string `json:"categories"`     // Expense categories counted against the limit
+	Period         string   `json:"period"`         // "weekly", "monthly" or "yearly"
+	Limit          float64  `json:"limit"`          // Spending limit per period
+	Rollover       bool     `json:"rollover"`       // Carry unspent (or overspent) amounts into later periods
+	Member         string   `json:"member"`         // Optional: only count this member's expenses
+	AlertThreshold float64  `json:"alertThreshold"` // Percent of limit that counts as "near" (default 80)
+	StartDate      string   `json:"startDate"`      // Optional: first day rollover is counted from
+	CreatedAt      string   `json:"createdAt"`
+	UpdatedAt      string   `json:"updatedAt"`
+}
+
+// PricePoint is the NAV or price of one scheme or symbol on one day
+type PricePoint struct {
+	Symbol string  `json:"symbol"` // Scheme code or ticker
+	Date   string  `json:"date"`   // YYYY-MM-DD
+	Price  float64 `json:"price"`
+	Source string  `json:"source"` // Provider name, or "manual"
+}
+
+// RecurringRule posts (or suggests) the same income, expense or investment
+// on a schedule, e.g. salary, rent, EMIs and SIPs
+type RecurringRule struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`      // e.g., "Home loan EMI"
+	Kind        string            `json:"kind"`      // "income", "expense" or "investment"
+	Frequency   string            `json:"frequency"` // "daily", "weekly", "monthly" or "yearly"
+	Interval    int               `json:"interval"`  // Every N periods (default 1)
+	StartDate   string            `json:"startDate"` // First occurrence; also fixes the day of month
+	EndDate     string            `json:"endDate"`   // Optional: no occurrences after this date
+	Count       int               `json:"count"`     // Optional: stop after this many occurrences
+	AutoPost    bool              `json:"autoPost"`  // Post when due; otherwise wait for confirmation
+	Paused      bool              `json:"paused"`    // Temporarily stop generating occurrences
+	Income      *Income           `json:"income,omitempty"`
+	Expense     *Expense          `json:"expense,omitempty"`
+	Investment  *Investment       `json:"investment,omitempty"`
+	Occurrences map[string]string `json:"occurrences"` // Handled dates: "posted" or "skipped"
+	CreatedAt   string            `json:"createdAt"`
+	UpdatedAt   string            `json:"updatedAt"`
+}
+
+// Chit is one membership in a chit fund group. Every month each member pays
+// the subscription; the member who bids the largest discount takes the pot,
+// and the discount less the foreman's commission is shared out as a dividend
+// that reduces everyone's instalment.
+type Chit struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`          // Group name, e.g., "Shriram 5L/50"
+	Foreman       string        `json:"foreman"`       // Company or person running the group
+	ChitValue     float64       `json:"chitValue"`     // Pot size (subscription x members)
+	Tenure        int           `json:"tenure"`        // Months, which is also the number of members
+	Subscription  float64       `json:"subscription"`  // Full monthly instalment before dividend
+	CommissionPct float64       `json:"commissionPct"` // Foreman's commission, percent of chit value
+	StartDate     string        `json:"startDate"`     // First instalment; later ones fall monthly after it
+	Auctions      []ChitAuction `json:"auctions"`      // One record per month paid
+	WonMonth      int           `json:"wonMonth"`      // Month we took the pot (0 = not yet)
+	PrizeAmount   float64       `json:"prizeAmount"`   // Amount received when we won
+	PrizeDate     string        `json:"prizeDate"`     // Optional: defaults to the won month's date
+	AddedBy       string        `json:"addedBy"`
+	Notes         string        `json:"notes"`
+	CreatedAt     string        `json:"createdAt"`
+	UpdatedAt     string        `json:"updatedAt"`
+}
+
+// ChitAuction records one month of a chit: the winning bid, our share of the
+// dividend and what we actually paid
+type ChitAuction struct {
+	Month     int     `json:"month"`     // 1..Tenure
+	Date      string  `json:"date"`      // Optional: defaults to the scheduled date
+	BidAmount float64 `json:"bidAmount"` // Discount given up by that month's winner
+	Dividend  float64 `json:"dividend"`  // Our share of the discount after commission
+	NetPaid   float64 `json:"netPaid"`   // Instalment paid (subscription - dividend)
+	Note      string  `json:"note,omitempty"`
+}
+
+// Loan is money borrowed and repaid in equal monthly instalments (EMIs). The
+// amortization schedule is derived from these terms (see package loans).
+type Loan struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`         // e.g., "Home loan"
+	Lender       string           `json:"lender"`       // e.g., "SBI"
+	Member       string           `json:"member"`       // Borrower, for tax purposes
+	Purpose      string           `json:"purpose"`      // One of the Loan* constants
+	SelfOccupied bool             `json:"selfOccupied"` // Home loans: caps the 24(b) interest deduction
+	Principal    float64          `json:"principal"`    // Amount borrowed
+	Rate         float64          `json:"rate"`         // Annual interest rate, percent
+	Tenure       int              `json:"tenure"`       // Months
+	StartDate    string           `json:"startDate"`    // First EMI; later ones fall monthly after it
+	EMI          float64          `json:"emi"`          // Optional: the lender's EMI if it differs from the computed one
+	Prepayments  []LoanPrepayment `json:"prepayments"`
+	RateChanges  []LoanRateChange `json:"rateChanges"`
+	CreatedAt    string           `json:"createdAt"`
+	UpdatedAt    string           `json:"updatedAt"`
+}
+
+// LoanPrepayment is a part-payment of principal outside the EMIs
+type LoanPrepayment struct {
+	Date   string  `json:"date"`
+	Amount float64 `json:"amount"`
+	Adjust string  `json:"adjust"` // "tenure" (keep the EMI, default) or "emi"
+	Note   string  `json:"note,omitempty"`
+}
+
+// LoanRateChange is a new interest rate on a floating rate loan
+type LoanRateChange struct {
+	Date   string  `json:"date"`   // Applies to EMIs due on or after this date
+	Rate   float64 `json:"rate"`   // New annual rate, percent
+	Adjust string  `json:"adjust"` // "tenure" (keep the EMI, default) or "emi"
+}
+
+// Account is where money is held or owed: a bank account, cash, a credit
+// card, a wallet or a UPI handle. Balances are derived from the expenses,
+// incomes, investment transactions and transfers that reference it (see
+// package accounts).
+type Account struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`                      // e.g., "HDFC Savings"
+	Kind            string  `json:"kind"`                      // One of the Account* constants
+	Institution     string  `json:"institution,omitempty"`     // Bank or issuer
+	Number          string  `json:"number,omitempty"`          // Last digits, for recognition
+	Member          string  `json:"member,omitempty"`          // Holder
+	OpeningBalance  float64 `json:"openingBalance"`            // Balance on the opening date; negative for a card's dues
+	OpeningDate     string  `json:"openingDate"`               // YYYY-MM-DD
+	LinkedAccountID string  `json:"linkedAccountId,omitempty"` // UPI: the bank account it moves money in
+	CreditLimit     float64 `json:"creditLimit,omitempty"`     // Credit cards
+	StatementDay    int     `json:"statementDay,omitempty"`    // Credit cards: day of the month statements are generated
+	DueDays         int     `json:"dueDays,omitempty"`         // Credit cards: days from statement to payment due
+	Closed          bool    `json:"closed"`
+	Notes           string  `json:"notes,omitempty"`
+	CreatedAt       string  `json:"createdAt"`
+	UpdatedAt       string  `json:"updatedAt"`
+}
+
+// Transfer moves money between two accounts, e.g. a cash withdrawal or a
+// credit card bill payment
+type Transfer struct {
+	ID            string  `json:"id"`
+	FromAccountID string  `json:"fromAccountId"`
+	ToAccountID   string  `json:"toAccountId"`
+	Amount        float64 `json:"amount"`
+	Date          string  `json:"date"` // YYYY-MM-DD
+	Note          string  `json:"note,omitempty"`
+	AddedBy       string  `json:"addedBy,omitempty"`
+	CreatedAt     string  `json:"createdAt"`
+	UpdatedAt     string  `json:"updatedAt"`
+}
+
+// StatementProfile maps the columns of a bank statement export onto
+// transactions. Column names are matched ignoring case, spaces and
+// punctuation. A statement has either separate debit and credit columns or
+// one amount column, signed or with a Dr/Cr type column beside it.
+type StatementProfile struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"` // e.g., "HDFC savings"
+	DateColumn        string `json:"dateColumn"`
+	DateFormat        string `json:"dateFormat,omitempty"` // e.g., "dd/mm/yy"; common formats are tried when empty
+	DescriptionColumn string `json:"descriptionColumn"`
+	DebitColumn       string `json:"debitColumn,omitempty"`
+	CreditColumn      string `json:"creditColumn,omitempty"`
+	AmountColumn      string `json:"amountColumn,omitempty"`
+	TypeColumn        string `json:"typeColumn,omitempty"` // With AmountColumn: "Dr"/"Cr"
+	ReferenceColumn   string `json:"referenceColumn,omitempty"`
+	BalanceColumn     string `json:"balanceColumn,omitempty"`
+	Delimiter         string `json:"delimiter,omitempty"` // CSV only; defaults to ","
+	AccountID         string `json:"accountId,omitempty"` // Default account for imports
+	CreatedAt         string `json:"createdAt"`
+	UpdatedAt         string `json:"updatedAt"`
+}
+
+// StatementImport is a parsed statement awaiting review. Nothing is recorded
+// until it is committed, when each row that is not skipped becomes an
+// expense (debits) or an income (credits).
+type StatementImport struct {
+	ID          string         `json:"id"`
+	FileName    string         `json:"fileName"`
+	ProfileID   string         `json:"profileId"` // Saved profile or built-in preset
+	AccountID   string         `json:"accountId,omitempty"`
+	AddedBy     string         `json:"addedBy"`
+	Status      string         `json:"status"` // "pending" or "committed"
+	Rows        []StatementRow `json:"rows"`
+	CreatedAt   string         `json:"createdAt"`
+	UpdatedAt   string         `json:"updatedAt"`
+	CommittedAt string         `json:"committedAt,omitempty"`
+}
+
+// StatementRow is one statement line and the record it will become
+type StatementRow struct {
+	Line          int      `json:"line"` // Line (CSV) or row (XLSX) in the file
+	Date          string   `json:"date"`
+	Description   string   `json:"description"`
+	Reference     string   `json:"reference,omitempty"`
+	Amount        float64  `json:"amount"`
+	Kind          string   `json:"kind"` // "expense" (debit) or "income" (credit)
+	Category      string   `json:"category"`
+	PaymentMethod string   `json:"paymentMethod"`
+	Balance       *float64 `json:"balance,omitempty"`
+	Skip          bool     `json:"skip"`
+	Duplicate     bool     `json:"duplicate"`             // Likely an expense or income already recorded,
+	DuplicateOf   string   `json:"duplicateOf,omitempty"` // this one
+	Error         string   `json:"error,omitempty"`       // Lines that could not be read are always skipped
+	RecordID      string   `json:"recordId,omitempty"`    // The expense or income committed
+
+	// Set by rules; the import's member and account apply when empty
+	Tags      []string `json:"tags,omitempty"`
+	AddedBy   string   `json:"addedBy,omitempty"`
+	AccountID string   `json:"accountId,omitempty"`
+}
+
+// Rule fills in fields of new expenses and incomes. Every condition set
+// must match; rules run in priority order (lowest first) and a field set by
+// one rule is not changed by a later one. Tags add up.
+type Rule struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	Kind     string `json:"kind,omitempty"` // "expense", "income", or empty for both
+	Disabled bool   `json:"disabled,omitempty"`
+	Stop     bool   `json:"stop,omitempty"` // No later rule runs after this one matches
+
+	// Conditions
+	DescriptionContains string   `json:"descriptionContains,omitempty"` // Case-insensitive
+	DescriptionRegex    string   `json:"descriptionRegex,omitempty"`
+	MinAmount           *float64 `json:"minAmount,omitempty"`
+	MaxAmount           *float64 `json:"maxAmount,omitempty"`
+	PaymentMethod       string   `json:"paymentMethod,omitempty"`
+	Member              string   `json:"member,omitempty"` // Added by
+
+	// Actions
+	SetCategory  string   `json:"setCategory,omitempty"`
+	AddTags      []string `json:"addTags,omitempty"`
+	SetMember    string   `json:"setMember,omitempty"`
+	SetAccountID string   `json:"setAccountId,omitempty"`
+
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// Merge records duplicate expenses or incomes folded into one. The merged
+// records are kept as they were before they were deleted.
+type Merge struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`   // "expense" or "income"
+	KeptID    string    `json:"keptId"` // The record the others were merged into
+	Expenses  []Expense `json:"expenses,omitempty"`
+	Incomes   []Income  `json:"incomes,omitempty"`
+	MergedBy  string    `json:"mergedBy,omitempty"`
+	CreatedAt string    `json:"createdAt"`
+	UpdatedAt string    `json:"updatedAt"`
+}
+
+// Share modes of a shared expense
+const (
+	ShareEqual   = "equal"
+	SharePercent = "percent"
+	ShareExact   = "exact"
+)
+
+// Share is one member's part of a shared expense: nothing for equal
+// shares, a percent or an exact amount
+type Share struct {
+	Member  string  `json:"member"`
+	Percent float64 `json:"percent,omitempty"`
+	Amount  float64 `json:"amount,omitempty"`
+}
+
+// Settlement records money one member paid another to square up shared
+// expenses
+type Settlement struct {
+	ID        string   `json:"id"`
+	From      string   `json:"from"` // Member who paid
+	To        string   `json:"to"`   // Member paid
+	Amount    float64  `json:"amount"`
+	Date      string   `json:"date"` // YYYY-MM-DD
+	Note      string   `json:"note,omitempty"`
+	Tags      []string `json:"tags,omitempty"` // e.g. the trip settled
+	CreatedAt string   `json:"createdAt"`
+	UpdatedAt string   `json:"updatedAt"`
+}
+
+// User roles
+const (
+	RoleOwner       = "owner"       // Everything, including users, settings and restoring backups
+	RoleEditor      = "editor"      // Reads and changes every record
+	RoleContributor = "contributor" // Adds, sees and changes their own expenses and incomes only
+	RoleViewer      = "viewer"      // Reads everything, changes nothing
+)
+
+// User is a login for one family member. The password is stored only as
+// a bcrypt hash, which is never sent back to clients.
+type User struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	Member       string `json:"member"` // One of Settings.Members
+	Role         string `json:"role"`   // Empty for users from before roles, who are owners
+	PasswordHash string `json:"passwordHash,omitempty"`
+	Disabled     bool   `json:"disabled"` // Cannot log in
+	CreatedAt    string `json:"createdAt"`
+	UpdatedAt    string `json:"updatedAt"`
+}
+
+// Session is a logged-in user. Only the SHA-256 hash of its token is kept,
+// so a copy of the data cannot be used to log in.
+type Session struct {
+	ID        string `json:"id"`
+	UserID    string `json:"userId"`
+	TokenHash string `json:"tokenHash"`
+	ExpiresAt string `json:"expiresAt"` // RFC 3339
+	CreatedAt string `json:"createdAt"`
+}
+
+// API token scopes
+const (
+	ScopeRead          = "read"           // Every GET the user's role allows
+	ScopeWrite         = "write"          // Every change the user's role allows
+	ScopeExpensesWrite = "expenses:write" // Add, change and delete expenses
+	ScopeIncomesWrite  = "incomes:write"  // Add, change and delete incomes
+	ScopeImport        = "import"         // Import statements and OFX/QIF files
+)
+
+// TokenScopes lists the accepted API token scopes
+var TokenScopes = []string{ScopeRead, ScopeWrite, ScopeExpensesWrite, ScopeIncomesWrite, ScopeImport}
+
+// APIToken lets a script act as its user within its scopes, sent as
+// "Authorization: Bearer <token>". Only the SHA-256 hash of the token is
+// kept; it is shown once, when created.
+type APIToken struct {
+	ID         string   `json:"id"`
+	UserID     string   `json:"userId"`
+	Name       string   `json:"name"`   // e.g. "Expense sheet sync"
+	Prefix     string   `json:"prefix"` // Start of the token, to tell tokens apart
+	TokenHash  string   `json:"tokenHash,omitempty"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"` // RFC 3339; empty never expires
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+	LastUsedIP string   `json:"lastUsedIp,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
 }
 
 // ExportData is the format for backup/restore
 type ExportData struct {
-	Version     string       `json:"version"`
-	ExportedAt  string       `json:"exportedAt"`
-	Investments []Investment `json:"investments"`
-	Incomes     []Income     `json:"incomes"`
-	Expenses    []Expense    `json:"expenses"`
-	Settings    Settings     `json:"settings"`
+	Version     string          `json:"version"`
+	ExportedAt  string          `json:"exportedAt"`
+	Investments []Investment    `json:"investments"`
+	Incomes     []Income        `json:"incomes"`
+	Expenses    []Expense       `json:"expenses"`
+	Settings    Settings        `json:"settings"`
+	Budgets     []Budget        `json:"budgets,omitempty"`
+	Recurring   []RecurringRule `json:"recurring,omitempty"`
+	Prices      []PricePoint    `json:"prices,omitempty"`
+	Chits       []Chit          `json:"chits,omitempty"`
+	Loans       []Loan          `json:"loans,omitempty"`
+	Accounts    []Account       `json:"accounts,omitempty"`
+	Transfers   []Transfer      `json:"transfers,omitempty"`
+
+	StatementProfiles []StatementProfile `json:"statementProfiles,omitempty"`
+	Merges            []Merge            `json:"merges,omitempty"`
+	Rules             []Rule             `json:"rules,omitempty"`
+	Settlements       []Settlement       `json:"settlements,omitempty"`
 }
@@ -1,6 +1,18 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"finance-tracker/internal/calc"
+)
+
+// schemeCodePattern matches an AMFI scheme code, or none
+var schemeCodePattern = regexp.MustCompile(`^[0-9]*$`)
 
 // Validate checks if an Investment is valid
 func (inv *Investment) Validate() error {
@@ -10,18 +22,229 @@ func (inv *Investment) Validate() error {
 	if inv.Type == "" {
 		return errors.New("investment type is required")
 	}
-	if inv.Invested <= 0 {
+	if len(inv.Transactions) == 0 && inv.Invested <= 0 {
 		return errors.New("invested amount must be greater than 0")
 	}
+	if inv.Invested < 0 {
+		return errors.New("invested amount cannot be negative")
+	}
 	if inv.Current < 0 {
 		return errors.New("current value cannot be negative")
 	}
 	if inv.Date == "" {
 		return errors.New("investment date is required")
 	}
+	if !schemeCodePattern.MatchString(inv.SchemeCode) {
+		return fmt.Errorf("scheme code %q must be the AMFI code's digits", inv.SchemeCode)
+	}
+	switch inv.AssetClass {
+	case "", AssetEquityFund, AssetDebtFund, AssetStock, AssetGold, AssetOther, AssetNone:
+	default:
+		return fmt.Errorf("unknown asset class %q", inv.AssetClass)
+	}
+	if inv.GrandfatheredFMV < 0 {
+		return errors.New("grandfathered FMV cannot be negative")
+	}
+	if inv.Deposit != nil {
+		if err := inv.Deposit.Validate(); err != nil {
+			return fmt.Errorf("deposit: %w", err)
+		}
+	}
+	for i := range inv.Transactions {
+		if err := inv.Transactions[i].Validate(); err != nil {
+			return fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+	}
+	return validateTags(inv.Tags)
+}
+
+// Deposit kinds
+const (
+	DepositFD = "fd"
+	DepositRD = "rd"
+)
+
+// Compounding and payout frequencies
+const (
+	FrequencyQuarterly  = "quarterly"
+	FrequencyHalfYearly = "half_yearly"
+	PayoutCumulative    = "cumulative"
+)
+
+// Validate checks if a Deposit is valid
+func (d *Deposit) Validate() error {
+	switch d.Kind {
+	case DepositFD, DepositRD:
+	default:
+		return errors.New("kind must be fd or rd")
+	}
+	if d.Rate <= 0 || d.Rate > 100 {
+		return errors.New("rate must be between 0 and 100 percent")
+	}
+	switch d.Compounding {
+	case "", FrequencyMonthly, FrequencyQuarterly, FrequencyHalfYearly, FrequencyYearly:
+	default:
+		return errors.New("compounding must be monthly, quarterly, half_yearly or yearly")
+	}
+	if d.Tenure <= 0 || d.Tenure > 600 {
+		return errors.New("tenure must be between 1 and 600 months")
+	}
+	switch d.Payout {
+	case "", PayoutCumulative:
+	case FrequencyMonthly, FrequencyQuarterly, FrequencyHalfYearly, FrequencyYearly:
+		if d.Kind == DepositRD {
+			return errors.New("recurring deposits are cumulative")
+		}
+	default:
+		return errors.New("payout must be cumulative, monthly, quarterly, half_yearly or yearly")
+	}
+	if d.MaturityDate != "" {
+		if _, err := time.Parse("2006-01-02", d.MaturityDate); err != nil {
+			return errors.New("maturity date must be in YYYY-MM-DD format")
+		}
+	}
+	if d.TDSRate < 0 || d.TDSRate > 100 {
+		return errors.New("TDS rate must be between 0 and 100 percent")
+	}
+	if d.Kind == DepositRD && d.Instalment <= 0 {
+		return errors.New("recurring deposit instalment must be greater than 0")
+	}
+	if d.PostIncome && d.Member == "" {
+		return errors.New("member is required to post interest as income")
+	}
+	return nil
+}
+
+// Asset classes for capital gains
+const (
+	AssetEquityFund = "equity_fund" // Equity-oriented mutual funds
+	AssetDebtFund   = "debt_fund"
+	AssetStock      = "stock" // Listed shares
+	AssetGold       = "gold"
+	AssetOther      = "other"
+	AssetNone       = "none" // Not a capital asset (deposits, PPF, chits)
+)
+
+// Investment transaction types
+const (
+	TxnBuy              = "buy"
+	TxnSIP              = "sip"
+	TxnSell             = "sell"
+	TxnRedeem           = "redeem"
+	TxnSwitchIn         = "switch_in"
+	TxnSwitchOut        = "switch_out"
+	TxnDividendReinvest = "dividend_reinvest"
+	TxnBonus            = "bonus"
+	TxnSplit            = "split"
+)
+
+// IsAcquisition reports whether the transaction adds a lot
+func (t *InvestmentTransaction) IsAcquisition() bool {
+	switch t.Type {
+	case TxnBuy, TxnSIP, TxnSwitchIn, TxnDividendReinvest, TxnBonus:
+		return true
+	}
+	return false
+}
+
+// IsDisposal reports whether the transaction sells units
+func (t *InvestmentTransaction) IsDisposal() bool {
+	switch t.Type {
+	case TxnSell, TxnRedeem, TxnSwitchOut:
+		return true
+	}
+	return false
+}
+
+// Validate checks if an InvestmentTransaction is valid
+func (t *InvestmentTransaction) Validate() error {
+	if _, err := time.Parse("2006-01-02", t.Date); err != nil {
+		return errors.New("transaction date must be YYYY-MM-DD")
+	}
+	if t.Units < 0 || t.Amount < 0 || t.CostSold < 0 {
+		return errors.New("units, amount and cost sold cannot be negative")
+	}
+	switch {
+	case t.Type == TxnSplit:
+		if t.Ratio <= 0 {
+			return errors.New("split ratio must be greater than 0")
+		}
+	case t.Type == TxnBonus:
+		if t.Units <= 0 {
+			return errors.New("bonus units must be greater than 0")
+		}
+	case t.IsAcquisition(), t.IsDisposal():
+		if t.Amount <= 0 && t.Units <= 0 {
+			return errors.New("transaction needs units or an amount")
+		}
+		if t.IsAcquisition() && t.Amount <= 0 {
+			return errors.New("purchase amount must be greater than 0")
+		}
+	default:
+		return fmt.Errorf("unknown transaction type %q", t.Type)
+	}
 	return nil
 }
 
+// OpeningTransaction converts the flat Invested/Units/Date of a holding
+// created before transactions existed into a single buy. ok is false when
+// the holding's date cannot be read (see openingDate).
+func (inv *Investment) OpeningTransaction() (txn InvestmentTransaction, ok bool) {
+	date, ok := openingDate(inv.Date)
+	if !ok {
+		return InvestmentTransaction{}, false
+	}
+	return InvestmentTransaction{
+		ID:     "opening",
+		Type:   TxnBuy,
+		Date:   date,
+		Units:  inv.Units,
+		Amount: inv.Invested,
+		Note:   "Opening balance",
+	}, true
+}
+
+// EnsureTransactions gives a flat holding its opening transaction. It
+// reports whether anything changed. A holding whose date cannot be read is
+// left flat, as it was before transactions existed.
+func (inv *Investment) EnsureTransactions() bool {
+	if len(inv.Transactions) > 0 || inv.Invested <= 0 {
+		return false
+	}
+	opening, ok := inv.OpeningTransaction()
+	if !ok {
+		return false
+	}
+	inv.Transactions = []InvestmentTransaction{opening}
+	inv.TotalInvested = inv.Invested
+	return true
+}
+
+// NeedsTransactions reports whether a holding is still flat: it has an
+// amount invested but no transactions
+func (inv *Investment) NeedsTransactions() bool {
+	return len(inv.Transactions) == 0 && inv.Invested > 0
+}
+
+// legacyDateLayouts are the layouts holding dates were entered in before
+// dates were checked, day first as written in India (01/04/2023 is 1 April)
+var legacyDateLayouts = []string{"2/1/2006", "2-1-2006", "2.1.2006", "2006/1/2"}
+
+// openingDate reads a holding's date as YYYY-MM-DD, whether it is already
+// one (perhaps with a time after it) or in one of legacyDateLayouts
+func openingDate(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if _, err := time.Parse("2006-01-02", calc.DateOnly(s)); err == nil {
+		return calc.DateOnly(s), true
+	}
+	for _, layout := range legacyDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), true
+		}
+	}
+	return "", false
+}
+
 // Validate checks if an Expense is valid
 func (exp *Expense) Validate() error {
 	if exp.Desc == "" {
@@ -39,9 +262,101 @@ func (exp *Expense) Validate() error {
 	if exp.AddedBy == "" {
 		return errors.New("added by (member name) is required")
 	}
+	if exp.Instalment < 0 {
+		return errors.New("instalment cannot be negative")
+	}
+	if exp.Instalment > 0 && exp.LoanID == "" {
+		return errors.New("instalment needs a loan")
+	}
+	if len(exp.Splits) > 0 {
+		var sum float64
+		for i, line := range exp.Splits {
+			if line.Amount <= 0 {
+				return fmt.Errorf("split %d: amount must be greater than 0", i+1)
+			}
+			sum += line.Amount
+		}
+		if math.Abs(sum-exp.Amount) >= 0.005 {
+			return fmt.Errorf("splits add up to %.2f, not the expense amount %.2f", sum, exp.Amount)
+		}
+	}
+	if err := exp.validateSharing(); err != nil {
+		return err
+	}
+	return validateTags(exp.Tags)
+}
+
+// validateSharing checks the shares of a shared expense add up: percents
+// to 100, exact amounts to the expense's amount
+func (exp *Expense) validateSharing() error {
+	if len(exp.SharedAmong) == 0 {
+		if exp.ShareMode != "" {
+			return errors.New("share mode needs the members sharing the expense")
+		}
+		return nil
+	}
+	seen := map[string]bool{}
+	var sum float64
+	for _, share := range exp.SharedAmong {
+		if share.Member == "" {
+			return errors.New("every share needs a member")
+		}
+		if seen[share.Member] {
+			return fmt.Errorf("%s shares the expense twice", share.Member)
+		}
+		seen[share.Member] = true
+		switch exp.ShareMode {
+		case SharePercent:
+			if share.Percent <= 0 {
+				return fmt.Errorf("%s's share must be above 0%%", share.Member)
+			}
+			sum += share.Percent
+		case ShareExact:
+			if share.Amount <= 0 {
+				return fmt.Errorf("%s's share must be greater than 0", share.Member)
+			}
+			sum += share.Amount
+		}
+	}
+	switch exp.ShareMode {
+	case "", ShareEqual:
+	case SharePercent:
+		if math.Abs(sum-100) >= 0.005 {
+			return fmt.Errorf("shares add up to %.2f%%, not 100%%", sum)
+		}
+	case ShareExact:
+		if math.Abs(sum-exp.Amount) >= 0.005 {
+			return fmt.Errorf("shares add up to %.2f, not the expense amount %.2f", sum, exp.Amount)
+		}
+	default:
+		return errors.New("share mode must be equal, percent or exact")
+	}
 	return nil
 }
 
+// Lines is the expense as one record per split line, each with the line's
+// amount, category and member; an expense that is not split is its only
+// line. Reports and budgets count these.
+func (exp Expense) Lines() []Expense {
+	if len(exp.Splits) == 0 {
+		return []Expense{exp}
+	}
+	lines := make([]Expense, 0, len(exp.Splits))
+	for _, split := range exp.Splits {
+		line := exp
+		line.Splits = nil
+		line.Amount = split.Amount
+		if split.Category != "" {
+			line.Category = split.Category
+		}
+		if split.Member != "" {
+			line.AddedBy = split.Member
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 // Validate checks if an Income is valid
 func (inc *Income) Validate() error {
 	if inc.Source == "" {
@@ -59,5 +374,504 @@ func (inc *Income) Validate() error {
 	if inc.AddedBy == "" {
 		return errors.New("added by (member name) is required")
 	}
+	return validateTags(inc.Tags)
+}
+
+// validateTags rejects blank tags and tags given twice, ignoring case
+func validateTags(tags []string) error {
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New("tags cannot be blank")
+		}
+		key := strings.ToLower(tag)
+		if seen[key] {
+			return fmt.Errorf("tag %q is given twice", tag)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Budget periods
+const (
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+	PeriodYearly  = "yearly"
+)
+
+// Validate checks if a Budget is valid
+func (b *Budget) Validate() error {
+	if len(b.Categories) == 0 {
+		return errors.New("budget needs at least one category")
+	}
+	for _, c := range b.Categories {
+		if c == "" {
+			return errors.New("budget categories cannot be empty")
+		}
+	}
+	switch b.Period {
+	case PeriodWeekly, PeriodMonthly, PeriodYearly:
+	default:
+		return errors.New("budget period must be weekly, monthly or yearly")
+	}
+	if b.Limit <= 0 {
+		return errors.New("budget limit must be greater than 0")
+	}
+	if b.AlertThreshold < 0 || b.AlertThreshold > 100 {
+		return errors.New("alert threshold must be between 0 and 100 percent")
+	}
+	return nil
+}
+
+// Recurring rule kinds
+const (
+	KindIncome     = "income"
+	KindExpense    = "expense"
+	KindInvestment = "investment"
+)
+
+// Recurring rule frequencies
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// Validate checks if a RecurringRule is valid, including its template
+func (rule *RecurringRule) Validate() error {
+	if rule.Name == "" {
+		return errors.New("rule name is required")
+	}
+	switch rule.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+	default:
+		return errors.New("frequency must be daily, weekly, monthly or yearly")
+	}
+	if rule.Interval < 0 || rule.Count < 0 {
+		return errors.New("interval and count cannot be negative")
+	}
+	start, err := time.Parse("2006-01-02", rule.StartDate)
+	if err != nil {
+		return errors.New("start date must be in YYYY-MM-DD format")
+	}
+	if rule.EndDate != "" {
+		end, err := time.Parse("2006-01-02", rule.EndDate)
+		if err != nil {
+			return errors.New("end date must be in YYYY-MM-DD format")
+		}
+		if end.Before(start) {
+			return errors.New("end date cannot be before start date")
+		}
+	}
+
+	// The template must be valid once an occurrence date is filled in
+	switch rule.Kind {
+	case KindIncome:
+		if rule.Income == nil {
+			return errors.New("income template is required")
+		}
+		inc := *rule.Income
+		inc.Date = rule.StartDate
+		return inc.Validate()
+	case KindExpense:
+		if rule.Expense == nil {
+			return errors.New("expense template is required")
+		}
+		exp := *rule.Expense
+		exp.Date = rule.StartDate
+		return exp.Validate()
+	case KindInvestment:
+		if rule.Investment == nil {
+			return errors.New("investment template is required")
+		}
+		inv := *rule.Investment
+		inv.Date = rule.StartDate
+		return inv.Validate()
+	default:
+		return errors.New("kind must be income, expense or investment")
+	}
+}
+
+// Validate checks if a PricePoint is valid
+func (p *PricePoint) Validate() error {
+	if p.Symbol == "" {
+		return errors.New("price symbol is required")
+	}
+	if _, err := time.Parse("2006-01-02", p.Date); err != nil {
+		return errors.New("price date must be YYYY-MM-DD")
+	}
+	if p.Price <= 0 {
+		return errors.New("price must be greater than 0")
+	}
+	return nil
+}
+
+// Validate checks if a Chit is valid
+func (c *Chit) Validate() error {
+	if c.Name == "" {
+		return errors.New("chit group name is required")
+	}
+	if c.ChitValue <= 0 || c.Subscription <= 0 {
+		return errors.New("chit value and subscription must be greater than 0")
+	}
+	if c.Tenure <= 0 {
+		return errors.New("tenure must be at least 1 month")
+	}
+	if c.CommissionPct < 0 || c.CommissionPct > 100 {
+		return errors.New("commission must be between 0 and 100 percent")
+	}
+	if _, err := time.Parse("2006-01-02", c.StartDate); err != nil {
+		return errors.New("start date must be in YYYY-MM-DD format")
+	}
+	if c.WonMonth < 0 || c.WonMonth > c.Tenure {
+		return fmt.Errorf("won month must be between 1 and %d", c.Tenure)
+	}
+	if c.PrizeAmount < 0 || c.PrizeAmount > c.ChitValue {
+		return errors.New("prize amount must be between 0 and the chit value")
+	}
+	if c.WonMonth == 0 && c.PrizeAmount > 0 {
+		return errors.New("prize amount needs the month the chit was won")
+	}
+	if c.PrizeDate != "" {
+		if _, err := time.Parse("2006-01-02", c.PrizeDate); err != nil {
+			return errors.New("prize date must be in YYYY-MM-DD format")
+		}
+	}
+	seen := make(map[int]bool, len(c.Auctions))
+	for _, a := range c.Auctions {
+		if a.Month < 1 || a.Month > c.Tenure {
+			return fmt.Errorf("auction month %d is outside the %d month tenure", a.Month, c.Tenure)
+		}
+		if seen[a.Month] {
+			return fmt.Errorf("month %d is recorded twice", a.Month)
+		}
+		seen[a.Month] = true
+		if a.Date != "" {
+			if _, err := time.Parse("2006-01-02", a.Date); err != nil {
+				return fmt.Errorf("month %d: date must be in YYYY-MM-DD format", a.Month)
+			}
+		}
+		if a.BidAmount < 0 || a.Dividend < 0 || a.NetPaid < 0 {
+			return fmt.Errorf("month %d: amounts cannot be negative", a.Month)
+		}
+		if a.BidAmount > c.ChitValue {
+			return fmt.Errorf("month %d: bid cannot exceed the chit value", a.Month)
+		}
+	}
+	return nil
+}
+
+// Loan purposes
+const (
+	LoanHome      = "home"
+	LoanEducation = "education"
+	LoanVehicle   = "vehicle"
+	LoanPersonal  = "personal"
+	LoanOther     = "other"
+)
+
+// How the schedule absorbs a prepayment or rate change
+const (
+	AdjustTenure = "tenure"
+	AdjustEMI    = "emi"
+)
+
+// Validate checks if a Loan is valid
+func (l *Loan) Validate() error {
+	if l.Name == "" {
+		return errors.New("loan name is required")
+	}
+	switch l.Purpose {
+	case LoanHome, LoanEducation, LoanVehicle, LoanPersonal, LoanOther:
+	default:
+		return errors.New("purpose must be home, education, vehicle, personal or other")
+	}
+	if l.Principal <= 0 {
+		return errors.New("principal must be greater than 0")
+	}
+	if l.Rate < 0 || l.Rate > 100 {
+		return errors.New("rate must be between 0 and 100 percent")
+	}
+	if l.Tenure <= 0 || l.Tenure > 600 {
+		return errors.New("tenure must be between 1 and 600 months")
+	}
+	if l.EMI < 0 {
+		return errors.New("EMI cannot be negative")
+	}
+	if _, err := time.Parse("2006-01-02", l.StartDate); err != nil {
+		return errors.New("start date must be in YYYY-MM-DD format")
+	}
+	for i, p := range l.Prepayments {
+		if _, err := time.Parse("2006-01-02", p.Date); err != nil {
+			return fmt.Errorf("prepayment %d: date must be in YYYY-MM-DD format", i+1)
+		}
+		if p.Amount <= 0 {
+			return fmt.Errorf("prepayment %d: amount must be greater than 0", i+1)
+		}
+		if err := validAdjust(p.Adjust); err != nil {
+			return fmt.Errorf("prepayment %d: %w", i+1, err)
+		}
+	}
+	for i, c := range l.RateChanges {
+		if _, err := time.Parse("2006-01-02", c.Date); err != nil {
+			return fmt.Errorf("rate change %d: date must be in YYYY-MM-DD format", i+1)
+		}
+		if c.Rate < 0 || c.Rate > 100 {
+			return fmt.Errorf("rate change %d: rate must be between 0 and 100 percent", i+1)
+		}
+		if err := validAdjust(c.Adjust); err != nil {
+			return fmt.Errorf("rate change %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validAdjust(adjust string) error {
+	switch adjust {
+	case "", AdjustTenure, AdjustEMI:
+		return nil
+	}
+	return errors.New("adjust must be tenure or emi")
+}
+
+// Account kinds
+const (
+	AccountBank       = "bank"
+	AccountCash       = "cash"
+	AccountCreditCard = "credit_card"
+	AccountWallet     = "wallet"
+	AccountUPI        = "upi"
+)
+
+// Validate checks if an Account is valid
+func (a *Account) Validate() error {
+	if a.Name == "" {
+		return errors.New("account name is required")
+	}
+	switch a.Kind {
+	case AccountBank, AccountCash, AccountCreditCard, AccountWallet, AccountUPI:
+	default:
+		return errors.New("kind must be bank, cash, credit_card, wallet or upi")
+	}
+	if a.OpeningDate != "" {
+		if _, err := time.Parse("2006-01-02", a.OpeningDate); err != nil {
+			return errors.New("opening date must be in YYYY-MM-DD format")
+		}
+	}
+	if a.LinkedAccountID != "" && a.Kind != AccountUPI {
+		return errors.New("only UPI accounts link to another account")
+	}
+	if a.LinkedAccountID != "" && a.LinkedAccountID == a.ID {
+		return errors.New("an account cannot link to itself")
+	}
+	if a.Kind != AccountCreditCard {
+		if a.CreditLimit != 0 || a.StatementDay != 0 || a.DueDays != 0 {
+			return errors.New("credit limit, statement day and due days apply to credit cards only")
+		}
+		return nil
+	}
+	if a.CreditLimit < 0 {
+		return errors.New("credit limit cannot be negative")
+	}
+	if a.StatementDay < 1 || a.StatementDay > 31 {
+		return errors.New("statement day must be between 1 and 31")
+	}
+	if a.DueDays < 0 || a.DueDays > 60 {
+		return errors.New("due days must be between 0 and 60")
+	}
+	return nil
+}
+
+// Validate checks if a Transfer is valid
+func (t *Transfer) Validate() error {
+	if t.FromAccountID == "" || t.ToAccountID == "" {
+		return errors.New("from and to accounts are required")
+	}
+	if t.FromAccountID == t.ToAccountID {
+		return errors.New("cannot transfer to the same account")
+	}
+	if t.Amount <= 0 {
+		return errors.New("transfer amount must be greater than 0")
+	}
+	if _, err := time.Parse("2006-01-02", t.Date); err != nil {
+		return errors.New("transfer date must be in YYYY-MM-DD format")
+	}
+	return nil
+}
+
+// Statement import states
+const (
+	ImportPending   = "pending"
+	ImportCommitted = "committed"
+)
+
+// Validate checks if a StatementProfile is valid
+func (p *StatementProfile) Validate() error {
+	if p.Name == "" {
+		return errors.New("profile name is required")
+	}
+	if p.DateColumn == "" || p.DescriptionColumn == "" {
+		return errors.New("date and description columns are required")
+	}
+	switch {
+	case p.AmountColumn != "":
+		if p.DebitColumn != "" || p.CreditColumn != "" {
+			return errors.New("use either an amount column or debit and credit columns")
+		}
+	case p.DebitColumn == "" || p.CreditColumn == "":
+		return errors.New("debit and credit columns, or an amount column, are required")
+	}
+	if p.TypeColumn != "" && p.AmountColumn == "" {
+		return errors.New("a type column needs an amount column")
+	}
+	if len([]rune(p.Delimiter)) > 1 {
+		return errors.New("delimiter must be a single character")
+	}
+	return nil
+}
+
+// Validate checks if a StatementImport is valid
+func (si *StatementImport) Validate() error {
+	if si.AddedBy == "" {
+		return errors.New("added by (member name) is required")
+	}
+	switch si.Status {
+	case ImportPending, ImportCommitted:
+	default:
+		return errors.New("status must be pending or committed")
+	}
+	for _, row := range si.Rows {
+		if row.Kind != KindExpense && row.Kind != KindIncome {
+			return fmt.Errorf("line %d: kind must be expense or income", row.Line)
+		}
+	}
+	return nil
+}
+
+// Validate checks if a Merge is valid
+func (m *Merge) Validate() error {
+	if m.KeptID == "" {
+		return errors.New("kept record is required")
+	}
+	switch m.Kind {
+	case KindExpense:
+		if len(m.Expenses) == 0 || len(m.Incomes) > 0 {
+			return errors.New("an expense merge must hold the merged expenses")
+		}
+	case KindIncome:
+		if len(m.Incomes) == 0 || len(m.Expenses) > 0 {
+			return errors.New("an income merge must hold the merged incomes")
+		}
+	default:
+		return errors.New("kind must be expense or income")
+	}
+	return nil
+}
+
+// Validate checks if a Rule is valid
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return errors.New("rule name is required")
+	}
+	switch r.Kind {
+	case "", KindExpense, KindIncome:
+	default:
+		return errors.New("kind must be expense, income or empty for both")
+	}
+	if r.DescriptionRegex != "" {
+		if _, err := regexp.Compile(r.DescriptionRegex); err != nil {
+			return fmt.Errorf("invalid description regex: %v", err)
+		}
+	}
+	if r.MinAmount != nil && r.MaxAmount != nil && *r.MinAmount > *r.MaxAmount {
+		return errors.New("minimum amount cannot be above the maximum")
+	}
+	if r.DescriptionContains == "" && r.DescriptionRegex == "" && r.MinAmount == nil &&
+		r.MaxAmount == nil && r.PaymentMethod == "" && r.Member == "" {
+		return errors.New("rule needs at least one condition")
+	}
+	if r.SetCategory == "" && len(r.AddTags) == 0 && r.SetMember == "" && r.SetAccountID == "" {
+		return errors.New("rule needs at least one action")
+	}
+	return validateTags(r.AddTags)
+}
+
+// Validate checks if a Settlement is valid
+func (st *Settlement) Validate() error {
+	if st.From == "" || st.To == "" {
+		return errors.New("from and to members are required")
+	}
+	if st.From == st.To {
+		return errors.New("a member cannot settle with themselves")
+	}
+	if st.Amount <= 0 {
+		return errors.New("settlement amount must be greater than 0")
+	}
+	if _, err := time.Parse("2006-01-02", st.Date); err != nil {
+		return errors.New("settlement date must be in YYYY-MM-DD format")
+	}
+	return validateTags(st.Tags)
+}
+
+// Validate checks if a User is valid
+func (u *User) Validate() error {
+	if u.Username == "" {
+		return errors.New("username is required")
+	}
+	if strings.ContainsAny(u.Username, " \t\r\n") {
+		return errors.New("username cannot contain spaces")
+	}
+	if u.Member == "" {
+		return errors.New("member is required")
+	}
+	switch u.Role {
+	case "", RoleOwner, RoleEditor, RoleContributor, RoleViewer:
+	default:
+		return errors.New("role must be owner, editor, contributor or viewer")
+	}
+	if u.PasswordHash == "" {
+		return errors.New("password is required")
+	}
+	return nil
+}
+
+// Validate checks if a Session is valid
+func (s *Session) Validate() error {
+	if s.UserID == "" || s.TokenHash == "" {
+		return errors.New("session needs a user and a token")
+	}
+	if _, err := time.Parse(time.RFC3339, s.ExpiresAt); err != nil {
+		return errors.New("session expiry must be an RFC 3339 time")
+	}
+	return nil
+}
+
+// Validate checks if an APIToken is valid
+func (t *APIToken) Validate() error {
+	if t.UserID == "" || t.TokenHash == "" {
+		return errors.New("token needs a user and a hash")
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("token name is required")
+	}
+	if len(t.Scopes) == 0 {
+		return errors.New("token needs at least one scope")
+	}
+	for _, scope := range t.Scopes {
+		known := false
+		for _, s := range TokenScopes {
+			known = known || scope == s
+		}
+		if !known {
+			return fmt.Errorf("unknown scope %q (expected one of %s)", scope, strings.Join(TokenScopes, ", "))
+		}
+	}
+	if t.ExpiresAt != "" {
+		if _, err := time.Parse(time.RFC3339, t.ExpiresAt); err != nil {
+			return errors.New("token expiry must be an RFC 3339 time")
+		}
+	}
 	return nil
 }
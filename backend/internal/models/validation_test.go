@@ -0,0 +1,23 @@
+package models
+
+import "testing"
+
+func TestInvestmentSchemeCode(t *testing.T) {
+	tests := []struct {
+		code    string
+		wantErr bool
+	}{
+		{"", false},
+		{"118955", false},
+		{"118955/latest", true},
+		{"../admin", true},
+		{" 118955", true},
+		{"INF179K01UT0", true},
+	}
+	for _, tt := range tests {
+		inv := Investment{Name: "HDFC Flexi Cap", Type: "Mutual Fund", Invested: 1000, Date: "2024-04-01", SchemeCode: tt.code}
+		if err := inv.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with scheme code %q error = %v, wantErr %v", tt.code, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package navprovider
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAMFIURL is where AMFI publishes the daily NAV file
+const DefaultAMFIURL = "https://www.amfiindia.com/spages/NAVAll.txt"
+
+// AMFI reads the AMFI NAVAll.txt file. Source is either an http(s) URL or a
+// local file path, e.g. a copy downloaded by cron.
+type AMFI struct {
+	Source string
+	Client *http.Client
+}
+
+// NewAMFI creates an AMFI provider; an empty source uses DefaultAMFIURL
+func NewAMFI(source string) *AMFI {
+	if source == "" {
+		source = DefaultAMFIURL
+	}
+	return &AMFI{
+		Source: source,
+		Client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Name implements Provider
+func (a *AMFI) Name() string {
+	return ProviderAMFI
+}
+
+// Latest implements Provider. The file covers every scheme, so it is read
+// once per call and filtered to codes.
+func (a *AMFI) Latest(ctx context.Context, codes []string) (map[string]Quote, error) {
+	rc, err := a.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	all, err := ParseAMFI(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[string]Quote, len(codes))
+	for _, code := range codes {
+		if q, ok := all[code]; ok {
+			quotes[code] = q
+		}
+	}
+	return quotes, nil
+}
+
+func (a *AMFI) open(ctx context.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(a.Source, "http://") && !strings.HasPrefix(a.Source, "https://") {
+		f, err := os.Open(a.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open NAV file: %w", err)
+		}
+		return f, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download NAV file: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download NAV file: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// ParseAMFI parses the NAVAll.txt format: semicolon-separated rows of
+//
+//	Scheme Code;ISIN Div Payout/ISIN Growth;ISIN Div Reinvestment;Scheme Name;Net Asset Value;Date
+//
+// interleaved with blank lines and scheme-category / fund-house headings,
+// which have no semicolons. Rows without a usable NAV ("N.A.") are skipped.
+func ParseAMFI(r io.Reader) (map[string]Quote, error) {
+	quotes := make(map[string]Quote)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !strings.Contains(line, ";") {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("NAV file line %d: expected 6 fields, got %d", lineNo, len(fields))
+		}
+		code := strings.TrimSpace(fields[0])
+		if _, err := strconv.Atoi(code); err != nil {
+			continue // column header
+		}
+
+		nav, err := strconv.ParseFloat(strings.TrimSpace(fields[4]), 64)
+		if err != nil || nav <= 0 {
+			continue
+		}
+		date, err := time.Parse("02-Jan-2006", strings.TrimSpace(fields[5]))
+		if err != nil {
+			return nil, fmt.Errorf("NAV file line %d: invalid date %q", lineNo, fields[5])
+		}
+
+		quotes[code] = Quote{
+			SchemeCode: code,
+			SchemeName: strings.TrimSpace(fields[3]),
+			NAV:        nav,
+			Date:       date.Format("2006-01-02"),
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read NAV file: %w", err)
+	}
+	return quotes, nil
+}
@@ -0,0 +1,135 @@
+package navprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMFAPIURL is the public mfapi.in endpoint
+const DefaultMFAPIURL = "https://api.mfapi.in"
+
+// MFAPI is a client for the api.mfapi.in JSON format. BaseURL can point at
+// any server speaking the same format, such as a local stand-in.
+type MFAPI struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewMFAPI creates an mfapi.in client; an empty baseURL uses DefaultMFAPIURL
+func NewMFAPI(baseURL string) *MFAPI {
+	if baseURL == "" {
+		baseURL = DefaultMFAPIURL
+	}
+	return &MFAPI{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name implements Provider
+func (m *MFAPI) Name() string {
+	return ProviderMFAPI
+}
+
+// mfapiResponse is the body of GET /mf/{code} and GET /mf/{code}/latest
+type mfapiResponse struct {
+	Meta struct {
+		SchemeCode json.Number `json:"scheme_code"`
+		SchemeName string      `json:"scheme_name"`
+	} `json:"meta"`
+	Data []struct {
+		Date string `json:"date"` // DD-MM-YYYY
+		NAV  string `json:"nav"`
+	} `json:"data"`
+	Status string `json:"status"`
+}
+
+// Latest implements Provider with one request per scheme code. The API
+// answers unknown codes with an empty data list, which are skipped.
+func (m *MFAPI) Latest(ctx context.Context, codes []string) (map[string]Quote, error) {
+	quotes := make(map[string]Quote, len(codes))
+	for _, code := range codes {
+		quote, ok, err := m.latest(ctx, code)
+		if err != nil {
+			return quotes, err
+		}
+		if ok {
+			quotes[code] = quote
+		}
+	}
+	return quotes, nil
+}
+
+func (m *MFAPI) latest(ctx context.Context, code string) (Quote, bool, error) {
+	quotes, err := m.fetch(ctx, code, "/latest")
+	if err != nil || len(quotes) == 0 {
+		return Quote{}, false, err
+	}
+	return quotes[0], true, nil
+}
+
+// History implements HistoryProvider. The API returns every published NAV,
+// newest first; the result is oldest first.
+func (m *MFAPI) History(ctx context.Context, code string) ([]Quote, error) {
+	quotes, err := m.fetch(ctx, code, "")
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(quotes)-1; i < j; i, j = i+1, j-1 {
+		quotes[i], quotes[j] = quotes[j], quotes[i]
+	}
+	return quotes, nil
+}
+
+// fetch requests /mf/{code}{suffix}. An unknown code yields no quotes.
+func (m *MFAPI) fetch(ctx context.Context, code, suffix string) ([]Quote, error) {
+	endpoint := fmt.Sprintf("%s/mf/%s%s", m.BaseURL, url.PathEscape(code), suffix)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch NAV for scheme %s: %w", code, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch NAV for scheme %s: %s", code, resp.Status)
+	}
+
+	var body mfapiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid NAV response for scheme %s: %w", code, err)
+	}
+
+	// Rows without a usable NAV (suspended schemes publish zeros) are skipped
+	quotes := make([]Quote, 0, len(body.Data))
+	for _, row := range body.Data {
+		nav, err := strconv.ParseFloat(strings.TrimSpace(row.NAV), 64)
+		if err != nil || nav <= 0 {
+			continue
+		}
+		date, err := time.Parse("02-01-2006", row.Date)
+		if err != nil {
+			continue
+		}
+		quotes = append(quotes, Quote{
+			SchemeCode: code,
+			SchemeName: body.Meta.SchemeName,
+			NAV:        nav,
+			Date:       date.Format("2006-01-02"),
+		})
+	}
+	return quotes, nil
+}
@@ -0,0 +1,117 @@
+package navprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAMFI(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "NAVAll.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := ParseAMFI(f)
+	if err != nil {
+		t.Fatalf("ParseAMFI() error = %v", err)
+	}
+	// Headings, blank lines and schemes without a NAV are skipped
+	want := map[string]Quote{
+		"118955": {SchemeCode: "118955", SchemeName: "HDFC Flexi Cap Fund - Growth Option - Direct Plan", NAV: 1876.452, Date: "2026-10-15"},
+		"101762": {SchemeCode: "101762", SchemeName: "HDFC Flexi Cap Fund - IDCW Option", NAV: 72.131, Date: "2026-10-15"},
+		"120716": {SchemeCode: "120716", SchemeName: "UTI Nifty 50 Index Fund - Growth Option- Direct", NAV: 168.9012, Date: "2026-10-14"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAMFI() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseAMFIErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"too few fields", "118955;INF179K01UT0;HDFC Flexi Cap Fund;1876.4520\n"},
+		{"bad date", "118955;INF179K01UT0;-;HDFC Flexi Cap Fund;1876.4520;2026-10-15\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAMFI(strings.NewReader(tt.file)); err == nil {
+				t.Error("ParseAMFI() error = nil, want an error")
+			}
+		})
+	}
+}
+
+// mfapiServer answers like api.mfapi.in for scheme 118955, with 404 for
+// 100000 and a server error for 999999. Other codes get an empty data list.
+func mfapiServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/mf/118955/latest":
+			w.Write([]byte(`{"meta":{"scheme_code":118955,"scheme_name":"HDFC Flexi Cap Fund - Direct Growth"},
+				"data":[{"date":"15-10-2026","nav":"1876.45200"}],"status":"SUCCESS"}`))
+		case "/mf/118955":
+			w.Write([]byte(`{"meta":{"scheme_code":118955,"scheme_name":"HDFC Flexi Cap Fund - Direct Growth"},
+				"data":[{"date":"15-10-2026","nav":"1876.45200"},{"date":"14-10-2026","nav":"0.00000"},
+				{"date":"13-10-2026","nav":"1870.10000"},{"date":"10-10-2026","nav":"1865.00000"}],"status":"SUCCESS"}`))
+		case "/mf/100000/latest":
+			http.NotFound(w, r)
+		case "/mf/999999/latest":
+			http.Error(w, "upstream down", http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"meta":{},"data":[],"status":"SUCCESS"}`))
+		}
+	}))
+}
+
+func TestMFAPILatest(t *testing.T) {
+	srv := mfapiServer(t)
+	defer srv.Close()
+
+	m := NewMFAPI(srv.URL + "/")
+	got, err := m.Latest(context.Background(), []string{"118955", "100000", "123456", "118955?"})
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	// Unknown codes, whether 404 or an empty list, are left out. A code is
+	// escaped, so "118955?" does not become scheme 118955's history.
+	want := map[string]Quote{
+		"118955": {SchemeCode: "118955", SchemeName: "HDFC Flexi Cap Fund - Direct Growth", NAV: 1876.452, Date: "2026-10-15"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Latest() = %+v, want %+v", got, want)
+	}
+
+	if _, err := m.Latest(context.Background(), []string{"999999"}); err == nil {
+		t.Error("Latest() error = nil for a server error, want an error")
+	}
+}
+
+func TestMFAPIHistory(t *testing.T) {
+	srv := mfapiServer(t)
+	defer srv.Close()
+
+	got, err := NewMFAPI(srv.URL).History(context.Background(), "118955")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	// Oldest first, without the zero NAV
+	var dates []string
+	for _, q := range got {
+		dates = append(dates, q.Date)
+	}
+	want := []string{"2026-10-10", "2026-10-13", "2026-10-15"}
+	if !reflect.DeepEqual(dates, want) {
+		t.Errorf("History() dates = %v, want %v", dates, want)
+	}
+}
@@ -0,0 +1,50 @@
+// Package navprovider fetches mutual fund NAVs and applies them to stored
+// investments.
+package navprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Supported provider names (config "nav_provider")
+const (
+	ProviderMFAPI = "mfapi" // JSON API in the api.mfapi.in format
+	ProviderAMFI  = "amfi"  // AMFI NAVAll.txt, fetched over HTTP or read from disk
+)
+
+// Quote is the latest NAV of one scheme
+type Quote struct {
+	SchemeCode string  `json:"schemeCode"`
+	SchemeName string  `json:"schemeName"`
+	NAV        float64 `json:"nav"`
+	Date       string  `json:"date"` // YYYY-MM-DD
+}
+
+// Provider looks up the latest NAV for a set of scheme codes. Codes the
+// provider does not know are left out of the result rather than failing the
+// whole batch.
+type Provider interface {
+	Name() string
+	Latest(ctx context.Context, codes []string) (map[string]Quote, error)
+}
+
+// HistoryProvider is implemented by providers that can also return the full
+// NAV history of a scheme, used to backfill price history for charts
+type HistoryProvider interface {
+	History(ctx context.Context, code string) ([]Quote, error)
+}
+
+// New returns the provider for name. source overrides the provider's default
+// location: a base URL for mfapi, a URL or file path for amfi.
+func New(name, source string) (Provider, error) {
+	switch strings.ToLower(name) {
+	case "", ProviderMFAPI:
+		return NewMFAPI(source), nil
+	case ProviderAMFI:
+		return NewAMFI(source), nil
+	default:
+		return nil, fmt.Errorf("unknown NAV provider %q (expected %q or %q)", name, ProviderMFAPI, ProviderAMFI)
+	}
+}
@@ -0,0 +1,169 @@
+package navprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/storage"
+)
+
+// Result summarises one refresh
+type Result struct {
+	Provider string   `json:"provider"`
+	Total    int      `json:"total"`   // Investments with a scheme code
+	Updated  int      `json:"updated"` // Investments whose value was recomputed
+	Missing  []string `json:"missing"` // Scheme codes the provider had no NAV for
+	Prices   int      `json:"prices"`  // Price history points stored, including backfill
+	Errors   []string `json:"errors"`  // Investments that could not be updated, and why
+}
+
+// errNotPriced skips an investment edited since the refresh began so that
+// it no longer has units or a NAV in this refresh
+var errNotPriced = errors.New("no NAV to apply")
+
+// Refresher recomputes Current = Units × NAV for every investment that has a
+// scheme code. Refreshes are serialised so the scheduler and an API request
+// do not fetch the same NAVs twice at once.
+type Refresher struct {
+	mu         sync.Mutex
+	store      storage.Storage
+	provider   Provider
+	backfilled map[string]bool // codes whose history was already requested
+}
+
+// NewRefresher creates a refresher for store using provider
+func NewRefresher(store storage.Storage, provider Provider) *Refresher {
+	return &Refresher{store: store, provider: provider, backfilled: map[string]bool{}}
+}
+
+// Refresh fetches the latest NAVs and updates the matching investments.
+// Investments without units keep their current value.
+func (r *Refresher) Refresh(ctx context.Context) (Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := Result{Provider: r.provider.Name(), Missing: []string{}, Errors: []string{}}
+
+	earliest := map[string]string{} // scheme code -> first purchase date
+	var codes []string
+	for _, inv := range r.store.GetInvestments() {
+		if inv.SchemeCode == "" {
+			continue
+		}
+		result.Total++
+		first, seen := earliest[inv.SchemeCode]
+		if !seen {
+			codes = append(codes, inv.SchemeCode)
+		}
+		if !seen || inv.Date < first {
+			earliest[inv.SchemeCode] = inv.Date
+		}
+	}
+	if len(codes) == 0 {
+		return result, nil
+	}
+	sort.Strings(codes)
+
+	// Fetch before touching the store; this is the slow part
+	quotes, err := r.provider.Latest(ctx, codes)
+	if err != nil {
+		return result, err
+	}
+	var points []models.PricePoint
+	for _, code := range codes {
+		quote, ok := quotes[code]
+		if !ok {
+			result.Missing = append(result.Missing, code)
+			continue
+		}
+		points = append(points, pricePoint(quote, r.provider.Name()))
+		history, err := r.backfill(ctx, code, earliest[code])
+		if err != nil {
+			return result, err
+		}
+		points = append(points, history...)
+	}
+	if err := r.store.AddPrices(points); err != nil {
+		return result, fmt.Errorf("failed to store prices: %w", err)
+	}
+	if err := r.store.SavePrices(); err != nil {
+		return result, fmt.Errorf("failed to save prices: %w", err)
+	}
+	result.Prices = len(points)
+
+	// Each investment is read again as it is updated: the fetch may have
+	// taken long enough for someone to edit it
+	now := time.Now().Format(time.RFC3339)
+	for _, inv := range r.store.GetInvestments() {
+		if _, ok := quotes[inv.SchemeCode]; inv.SchemeCode == "" || !ok || inv.Units <= 0 {
+			continue
+		}
+		err := r.store.ModifyInvestment(inv.ID, func(fresh *models.Investment) error {
+			quote, ok := quotes[fresh.SchemeCode]
+			if fresh.SchemeCode == "" || !ok || fresh.Units <= 0 {
+				return errNotPriced
+			}
+			fresh.Current = math.Round(fresh.Units*quote.NAV*100) / 100
+			fresh.UpdatedAt = now
+			return nil
+		})
+		switch {
+		case errors.Is(err, storage.ErrNotFound), errors.Is(err, errNotPriced):
+			// Deleted or changed since the list was read
+			continue
+		case err != nil:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s (%s): %v", inv.Name, inv.ID, err))
+			continue
+		}
+		result.Updated++
+	}
+
+	if err := r.store.SaveInvestments(); err != nil {
+		return result, fmt.Errorf("failed to save investments: %w", err)
+	}
+	return result, nil
+}
+
+// backfill returns the provider's price history for code from since onwards
+// when the stored history does not reach back that far. Each code is tried
+// once per process, so a scheme whose history really starts later is not
+// downloaded on every refresh.
+func (r *Refresher) backfill(ctx context.Context, code, since string) ([]models.PricePoint, error) {
+	hp, ok := r.provider.(HistoryProvider)
+	if !ok || r.backfilled[code] {
+		return nil, nil
+	}
+	if stored := r.store.GetPrices(code); len(stored) > 0 && stored[0].Date <= since {
+		return nil, nil
+	}
+
+	quotes, err := hp.History(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	r.backfilled[code] = true
+
+	// Keep a few days before the purchase so it has a price even when it
+	// fell on a holiday
+	cutoff := since
+	if t, err := time.Parse("2006-01-02", since); err == nil {
+		cutoff = t.AddDate(0, 0, -7).Format("2006-01-02")
+	}
+	var points []models.PricePoint
+	for _, q := range quotes {
+		if q.Date >= cutoff {
+			points = append(points, pricePoint(q, r.provider.Name()))
+		}
+	}
+	return points, nil
+}
+
+func pricePoint(q Quote, source string) models.PricePoint {
+	return models.PricePoint{Symbol: q.SchemeCode, Date: q.Date, Price: q.NAV, Source: source}
+}
@@ -0,0 +1,90 @@
+// Package networth totals what the household owns against what it owes:
+// investments at their current value and chit instalments paid before the
+// pot is won, less outstanding loan principal and the instalments still due
+// on chits already won.
+package networth
+
+import (
+	"sort"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/chits"
+	"finance-tracker/internal/loans"
+	"finance-tracker/internal/models"
+)
+
+// Kinds of items besides investment types
+const (
+	KindChit = "Chit fund"
+	KindLoan = "Loan"
+)
+
+// Item is one asset or liability
+type Item struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Kind   string  `json:"kind"` // Investment type, "Chit fund" or "Loan"
+	Member string  `json:"member,omitempty"`
+	Value  float64 `json:"value"`
+}
+
+// Report is the net worth on a date
+type Report struct {
+	AsOf        string             `json:"asOf"`
+	Assets      float64            `json:"assets"`
+	Liabilities float64            `json:"liabilities"`
+	NetWorth    float64            `json:"netWorth"`
+	ByKind      map[string]float64 `json:"byKind"` // Assets positive, liabilities negative
+	Owned       []Item             `json:"owned"`
+	Owed        []Item             `json:"owed"`
+}
+
+// Build values investments, chits and loans on asOf
+func Build(investments []models.Investment, chitList []chits.Status, loanList []loans.Status, asOf time.Time) Report {
+	r := Report{
+		AsOf:   asOf.Format("2006-01-02"),
+		ByKind: map[string]float64{},
+		Owned:  []Item{},
+		Owed:   []Item{},
+	}
+
+	for _, inv := range investments {
+		if inv.Current <= 0 {
+			continue
+		}
+		r.Owned = append(r.Owned, Item{ID: inv.ID, Name: inv.Name, Kind: inv.Type, Value: inv.Current})
+	}
+	for _, c := range chitList {
+		switch {
+		case !c.Won && c.TotalPaid > 0:
+			r.Owned = append(r.Owned, Item{ID: c.ID, Name: c.Name, Kind: KindChit, Value: c.TotalPaid})
+		case c.Won && c.EstimatedLiability > 0:
+			r.Owed = append(r.Owed, Item{ID: c.ID, Name: c.Name, Kind: KindChit, Value: c.EstimatedLiability})
+		}
+	}
+	for _, l := range loanList {
+		if l.Outstanding > 0 {
+			r.Owed = append(r.Owed, Item{ID: l.ID, Name: l.Name, Kind: KindLoan, Member: l.Member, Value: l.Outstanding})
+		}
+	}
+
+	for _, it := range r.Owned {
+		r.Assets += it.Value
+		r.ByKind[it.Kind] += it.Value
+	}
+	for _, it := range r.Owed {
+		r.Liabilities += it.Value
+		r.ByKind[it.Kind] -= it.Value
+	}
+	for k, v := range r.ByKind {
+		r.ByKind[k] = calc.Round2(v)
+	}
+	r.Assets = calc.Round2(r.Assets)
+	r.Liabilities = calc.Round2(r.Liabilities)
+	r.NetWorth = calc.Round2(r.Assets - r.Liabilities)
+
+	sort.SliceStable(r.Owned, func(i, j int) bool { return r.Owned[i].Value > r.Owned[j].Value })
+	sort.SliceStable(r.Owed, func(i, j int) bool { return r.Owed[i].Value > r.Owed[j].Value })
+	return r
+}
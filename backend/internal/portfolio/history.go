@@ -0,0 +1,145 @@
+// Package portfolio derives holdings and valuations from stored investments
+// and price history.
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// History intervals
+const (
+	IntervalDaily   = "daily"
+	IntervalMonthly = "monthly" // month ends, plus the last day of the range
+)
+
+// MaxPoints bounds one series; longer daily ranges must use monthly
+const MaxPoints = 3700
+
+// Point is the value of a holding (or the portfolio) on one day
+type Point struct {
+	Date     string  `json:"date"`
+	Value    float64 `json:"value"`
+	Invested float64 `json:"invested"`
+}
+
+// Series is a valuation time series
+type Series struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Interval string  `json:"interval"`
+	Points   []Point `json:"points"`
+}
+
+// Prices is the price history of each symbol, oldest first
+type Prices map[string][]models.PricePoint
+
+// Dates returns the sample days between from and to, inclusive
+func Dates(from, to time.Time, interval string) ([]time.Time, error) {
+	var days []time.Time
+	switch interval {
+	case IntervalDaily:
+		if int(to.Sub(from).Hours()/24)+1 > MaxPoints {
+			return nil, fmt.Errorf("range too long for daily points (max %d), use interval=monthly", MaxPoints)
+		}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case IntervalMonthly:
+		for d := monthEnd(from); d.Before(to); d = monthEnd(d.AddDate(0, 0, 1)) {
+			days = append(days, d)
+		}
+		days = append(days, to)
+	default:
+		return nil, fmt.Errorf("interval must be %s or %s", IntervalDaily, IntervalMonthly)
+	}
+	return days, nil
+}
+
+func monthEnd(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Value returns what inv was worth and its cost basis on day, replaying only
+// the transactions made by then. Holdings with a scheme code and units are
+// valued at the last known price on or before day, and at cost before the
+// first price. Other holdings are worth their cost until their last update
+// and Current from then on.
+func Value(inv models.Investment, prices []models.PricePoint, day string) (value, invested float64) {
+	n := sort.Search(len(inv.Transactions), func(i int) bool { return inv.Transactions[i].Date > day })
+	if n == 0 {
+		return 0, 0
+	}
+	h, err := Build(inv.Transactions[:n])
+	if err != nil {
+		return 0, 0
+	}
+	if h.UnitTracked && inv.SchemeCode != "" {
+		if price, ok := PriceOn(prices, day); ok {
+			return h.Units * price, h.CostBasis
+		}
+		return h.CostBasis, h.CostBasis
+	}
+	if n == len(inv.Transactions) && inv.UpdatedAt != "" && day >= calc.DateOnly(inv.UpdatedAt) {
+		return inv.Current, h.CostBasis
+	}
+	return h.CostBasis, h.CostBasis
+}
+
+// PriceOn returns the last price on or before day
+func PriceOn(prices []models.PricePoint, day string) (float64, bool) {
+	i := sort.Search(len(prices), func(i int) bool { return prices[i].Date > day })
+	if i == 0 {
+		return 0, false
+	}
+	return prices[i-1].Price, true
+}
+
+// History sums the value of investments on each sample day
+func History(investments []models.Investment, prices Prices, from, to time.Time, interval string) (Series, error) {
+	days, err := Dates(from, to, interval)
+	if err != nil {
+		return Series{}, err
+	}
+
+	series := Series{
+		From:     from.Format(dateLayout),
+		To:       to.Format(dateLayout),
+		Interval: interval,
+		Points:   make([]Point, 0, len(days)),
+	}
+	for _, d := range days {
+		day := d.Format(dateLayout)
+		point := Point{Date: day}
+		for _, inv := range investments {
+			value, invested := Value(inv, prices[inv.SchemeCode], day)
+			point.Value += value
+			point.Invested += invested
+		}
+		point.Value = calc.Round2(point.Value)
+		point.Invested = calc.Round2(point.Invested)
+		series.Points = append(series.Points, point)
+	}
+	return series, nil
+}
+
+// FirstDate returns the earliest purchase date among investments
+func FirstDate(investments []models.Investment) (time.Time, bool) {
+	var first time.Time
+	for _, inv := range investments {
+		t, err := time.Parse(dateLayout, calc.DateOnly(inv.Date))
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	return first, !first.IsZero()
+}
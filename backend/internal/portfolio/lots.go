@@ -0,0 +1,214 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+// epsilon absorbs float noise when matching units
+const epsilon = 1e-6
+
+// Lot is a parcel bought in one transaction and not yet sold
+type Lot struct {
+	TxnID string  `json:"txnId"`
+	Date  string  `json:"date"`
+	Units float64 `json:"units"`
+	Cost  float64 `json:"cost"`
+}
+
+// Disposal is the part of one lot consumed by a sale
+type Disposal struct {
+	TxnID    string  `json:"txnId"` // The sale
+	Type     string  `json:"type"`
+	BuyDate  string  `json:"buyDate"`
+	SellDate string  `json:"sellDate"`
+	Units    float64 `json:"units"`
+	Cost     float64 `json:"cost"`
+	Proceeds float64 `json:"proceeds"`
+	Gain     float64 `json:"gain"`
+}
+
+// Holding is the state derived from a holding's transactions
+type Holding struct {
+	Units         float64    `json:"units"`
+	CostBasis     float64    `json:"costBasis"`
+	TotalInvested float64    `json:"totalInvested"`
+	RealizedGain  float64    `json:"realizedGain"`
+	UnitTracked   bool       `json:"unitTracked"` // False when amounts stand in for units
+	Lots          []Lot      `json:"lots"`
+	Disposals     []Disposal `json:"disposals"`
+}
+
+// SortTransactions orders transactions by date, keeping the entered order
+// within a day
+func SortTransactions(txns []models.InvestmentTransaction) {
+	sort.SliceStable(txns, func(i, j int) bool { return txns[i].Date < txns[j].Date })
+}
+
+// Build replays transactions (already in date order) and matches sales
+// against the oldest lots first.
+func Build(txns []models.InvestmentTransaction) (Holding, error) {
+	h := Holding{Lots: []Lot{}, Disposals: []Disposal{}}
+	for _, t := range txns {
+		if (t.IsAcquisition() || t.IsDisposal()) && t.Type != models.TxnBonus && t.Units > 0 {
+			h.UnitTracked = true
+			break
+		}
+	}
+
+	for _, t := range txns {
+		qty := t.Units
+		if !h.UnitTracked {
+			// Without units, lots are matched on cost: what was paid for
+			// purchases, and the cost of the part sold (or all of it) for sales
+			qty = t.Amount
+			if t.IsDisposal() {
+				qty = t.CostSold
+				if qty == 0 {
+					qty = h.held()
+				}
+			}
+		}
+
+		switch {
+		case t.Type == models.TxnSplit || t.Type == models.TxnBonus:
+			if !h.UnitTracked {
+				return h, fmt.Errorf("%s on %s needs a holding tracked in units", t.Type, t.Date)
+			}
+			if t.Type == models.TxnBonus {
+				h.Lots = append(h.Lots, Lot{TxnID: t.ID, Date: t.Date, Units: t.Units})
+				continue
+			}
+			for i := range h.Lots {
+				h.Lots[i].Units *= t.Ratio
+			}
+
+		case t.IsAcquisition():
+			if qty <= 0 {
+				return h, fmt.Errorf("%s on %s needs units", t.Type, t.Date)
+			}
+			h.Lots = append(h.Lots, Lot{TxnID: t.ID, Date: t.Date, Units: qty, Cost: t.Amount})
+			if t.Type != models.TxnDividendReinvest {
+				h.TotalInvested += t.Amount
+			}
+
+		case t.IsDisposal():
+			if qty <= 0 && !h.UnitTracked {
+				return h, fmt.Errorf("%s on %s sells from an empty holding", t.Type, t.Date)
+			}
+			if qty <= 0 {
+				return h, fmt.Errorf("%s on %s needs units", t.Type, t.Date)
+			}
+			if err := h.dispose(t, qty); err != nil {
+				return h, err
+			}
+		}
+	}
+
+	for _, lot := range h.Lots {
+		h.Units += lot.Units
+		h.CostBasis += lot.Cost
+	}
+	for _, d := range h.Disposals {
+		h.RealizedGain += d.Gain
+	}
+	return h, nil
+}
+
+// held is the units (or cost, without units) in the open lots
+func (h *Holding) held() float64 {
+	held := 0.0
+	for _, lot := range h.Lots {
+		held += lot.Units
+	}
+	return held
+}
+
+// dispose consumes qty from the oldest lots, splitting the sale proceeds
+// across them in proportion to the units taken
+func (h *Holding) dispose(t models.InvestmentTransaction, qty float64) error {
+	if held := h.held(); qty > held+epsilon {
+		return fmt.Errorf("%s on %s of %g exceeds the %g held", t.Type, t.Date, qty, held)
+	}
+
+	remaining := qty
+	for remaining > epsilon && len(h.Lots) > 0 {
+		lot := &h.Lots[0]
+		take := lot.Units
+		if remaining < take {
+			take = remaining
+		}
+		cost := lot.Cost * take / lot.Units
+		proceeds := t.Amount * take / qty
+		h.Disposals = append(h.Disposals, Disposal{
+			TxnID:    t.ID,
+			Type:     t.Type,
+			BuyDate:  lot.Date,
+			SellDate: t.Date,
+			Units:    take,
+			Cost:     cost,
+			Proceeds: proceeds,
+			Gain:     proceeds - cost,
+		})
+
+		lot.Units -= take
+		lot.Cost -= cost
+		remaining -= take
+		if lot.Units <= epsilon {
+			h.Lots = h.Lots[1:]
+		}
+	}
+	return nil
+}
+
+// Apply sorts inv's transactions and recomputes its derived fields
+func Apply(inv *models.Investment) (Holding, error) {
+	SortTransactions(inv.Transactions)
+	h, err := Build(inv.Transactions)
+	if err != nil {
+		return h, err
+	}
+
+	inv.Units = 0
+	if h.UnitTracked {
+		inv.Units = round6(h.Units)
+	}
+	inv.Invested = calc.Round2(h.CostBasis)
+	inv.TotalInvested = calc.Round2(h.TotalInvested)
+	inv.RealizedGain = calc.Round2(h.RealizedGain)
+	if len(inv.Transactions) > 0 {
+		inv.Date = inv.Transactions[0].Date
+	}
+	return h, nil
+}
+
+// Revalue moves Current in step with a change in the holding: at the latest
+// price when there is one, otherwise by the ratio of units (or cost, for
+// holdings without units) before and after.
+func Revalue(inv *models.Investment, prev models.Investment, prices []models.PricePoint) {
+	if inv.SchemeCode != "" && inv.Units > 0 && len(prices) > 0 {
+		inv.Current = calc.Round2(inv.Units * prices[len(prices)-1].Price)
+		return
+	}
+
+	before, after := prev.Units, inv.Units
+	if before <= 0 && after <= 0 {
+		before, after = prev.Invested, inv.Invested
+	}
+	switch {
+	case after <= 0:
+		inv.Current = 0
+	case before <= 0:
+		inv.Current = inv.Invested
+	default:
+		inv.Current = calc.Round2(prev.Current * after / before)
+	}
+}
+
+func round6(f float64) float64 {
+	return math.Round(f*1e6) / 1e6
+}
@@ -0,0 +1,194 @@
+package portfolio
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"finance-tracker/internal/models"
+)
+
+func txn(id, typ, date string, units, amount float64) models.InvestmentTransaction {
+	return models.InvestmentTransaction{ID: id, Type: typ, Date: date, Units: units, Amount: amount}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestBuild(t *testing.T) {
+	split := txn("s", models.TxnSplit, "2024-03-01", 0, 0)
+	split.Ratio = 2
+	goldSale := txn("g2", models.TxnSell, "2025-06-01", 0, 30000)
+	goldSale.CostSold = 20000
+
+	tests := []struct {
+		name      string
+		txns      []models.InvestmentTransaction
+		units     float64
+		costBasis float64
+		realized  float64
+		lots      []Lot
+		disposals []Disposal
+	}{
+		{
+			name:      "buys only",
+			txns:      []models.InvestmentTransaction{txn("b1", models.TxnBuy, "2024-01-01", 10, 1000), txn("b2", models.TxnSIP, "2024-02-01", 5, 600)},
+			units:     15,
+			costBasis: 1600,
+			lots:      []Lot{{"b1", "2024-01-01", 10, 1000}, {"b2", "2024-02-01", 5, 600}},
+		},
+		{
+			name: "partial sell takes the oldest lot first",
+			txns: []models.InvestmentTransaction{
+				txn("b1", models.TxnBuy, "2024-01-01", 10, 1000),
+				txn("b2", models.TxnBuy, "2024-02-01", 10, 1500),
+				txn("x1", models.TxnSell, "2024-06-01", 4, 800),
+			},
+			units:     16,
+			costBasis: 2100,
+			realized:  400,
+			lots:      []Lot{{"b1", "2024-01-01", 6, 600}, {"b2", "2024-02-01", 10, 1500}},
+			disposals: []Disposal{{TxnID: "x1", Type: models.TxnSell, BuyDate: "2024-01-01", SellDate: "2024-06-01", Units: 4, Cost: 400, Proceeds: 800, Gain: 400}},
+		},
+		{
+			name: "sell across lots splits the proceeds by units",
+			txns: []models.InvestmentTransaction{
+				txn("b1", models.TxnBuy, "2024-01-01", 10, 1000),
+				txn("b2", models.TxnBuy, "2024-02-01", 10, 1500),
+				txn("x1", models.TxnRedeem, "2024-06-01", 15, 3000),
+			},
+			units:     5,
+			costBasis: 750,
+			realized:  1250,
+			lots:      []Lot{{"b2", "2024-02-01", 5, 750}},
+			disposals: []Disposal{
+				{TxnID: "x1", Type: models.TxnRedeem, BuyDate: "2024-01-01", SellDate: "2024-06-01", Units: 10, Cost: 1000, Proceeds: 2000, Gain: 1000},
+				{TxnID: "x1", Type: models.TxnRedeem, BuyDate: "2024-02-01", SellDate: "2024-06-01", Units: 5, Cost: 750, Proceeds: 1000, Gain: 250},
+			},
+		},
+		{
+			name: "split multiplies units and keeps cost",
+			txns: []models.InvestmentTransaction{
+				txn("b1", models.TxnBuy, "2024-01-01", 10, 1000),
+				split,
+				txn("x1", models.TxnSell, "2024-06-01", 5, 400),
+			},
+			units:     15,
+			costBasis: 750,
+			realized:  150,
+			lots:      []Lot{{"b1", "2024-01-01", 15, 750}},
+			disposals: []Disposal{{TxnID: "x1", Type: models.TxnSell, BuyDate: "2024-01-01", SellDate: "2024-06-01", Units: 5, Cost: 250, Proceeds: 400, Gain: 150}},
+		},
+		{
+			name: "bonus is a lot at zero cost, sold after the older lots",
+			txns: []models.InvestmentTransaction{
+				txn("b1", models.TxnBuy, "2024-01-01", 10, 1000),
+				txn("bo", models.TxnBonus, "2024-03-01", 5, 0),
+				txn("x1", models.TxnSell, "2024-06-01", 12, 1800),
+			},
+			units:     3,
+			costBasis: 0,
+			realized:  800,
+			lots:      []Lot{{"bo", "2024-03-01", 3, 0}},
+			disposals: []Disposal{
+				{TxnID: "x1", Type: models.TxnSell, BuyDate: "2024-01-01", SellDate: "2024-06-01", Units: 10, Cost: 1000, Proceeds: 1500, Gain: 500},
+				{TxnID: "x1", Type: models.TxnSell, BuyDate: "2024-03-01", SellDate: "2024-06-01", Units: 2, Cost: 0, Proceeds: 300, Gain: 300},
+			},
+		},
+		{
+			name: "without units a sale at a gain consumes its cost sold",
+			txns: []models.InvestmentTransaction{
+				txn("g1", models.TxnBuy, "2023-01-01", 0, 50000),
+				goldSale,
+			},
+			units:     30000, // Cost stands in for units; Apply reports 0
+			costBasis: 30000,
+			realized:  10000,
+			lots:      []Lot{{"g1", "2023-01-01", 30000, 30000}},
+			disposals: []Disposal{{TxnID: "g2", Type: models.TxnSell, BuyDate: "2023-01-01", SellDate: "2025-06-01", Units: 20000, Cost: 20000, Proceeds: 30000, Gain: 10000}},
+		},
+		{
+			name: "without units and no cost sold the whole holding is sold",
+			txns: []models.InvestmentTransaction{
+				txn("g1", models.TxnBuy, "2023-01-01", 0, 50000),
+				txn("g2", models.TxnBuy, "2024-01-01", 0, 10000),
+				txn("g3", models.TxnSell, "2025-06-01", 0, 90000),
+			},
+			realized: 30000,
+			lots:     []Lot{},
+			disposals: []Disposal{
+				{TxnID: "g3", Type: models.TxnSell, BuyDate: "2023-01-01", SellDate: "2025-06-01", Units: 50000, Cost: 50000, Proceeds: 75000, Gain: 25000},
+				{TxnID: "g3", Type: models.TxnSell, BuyDate: "2024-01-01", SellDate: "2025-06-01", Units: 10000, Cost: 10000, Proceeds: 15000, Gain: 5000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := Build(tt.txns)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if !near(h.Units, tt.units) || !near(h.CostBasis, tt.costBasis) || !near(h.RealizedGain, tt.realized) {
+				t.Errorf("Build() units, cost basis, realized = %g, %g, %g, want %g, %g, %g",
+					h.Units, h.CostBasis, h.RealizedGain, tt.units, tt.costBasis, tt.realized)
+			}
+			if len(h.Lots) != len(tt.lots) {
+				t.Fatalf("Build() lots = %+v, want %+v", h.Lots, tt.lots)
+			}
+			for i, lot := range h.Lots {
+				want := tt.lots[i]
+				if lot.TxnID != want.TxnID || lot.Date != want.Date || !near(lot.Units, want.Units) || !near(lot.Cost, want.Cost) {
+					t.Errorf("lot %d = %+v, want %+v", i, lot, want)
+				}
+			}
+			if len(h.Disposals) != len(tt.disposals) {
+				t.Fatalf("Build() disposals = %+v, want %+v", h.Disposals, tt.disposals)
+			}
+			for i, d := range h.Disposals {
+				want := tt.disposals[i]
+				if d.TxnID != want.TxnID || d.Type != want.Type || d.BuyDate != want.BuyDate || d.SellDate != want.SellDate ||
+					!near(d.Units, want.Units) || !near(d.Cost, want.Cost) || !near(d.Proceeds, want.Proceeds) || !near(d.Gain, want.Gain) {
+					t.Errorf("disposal %d = %+v, want %+v", i, d, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	oversold := txn("g2", models.TxnSell, "2025-06-01", 0, 90000)
+	oversold.CostSold = 60000
+
+	tests := []struct {
+		name string
+		txns []models.InvestmentTransaction
+		want string
+	}{
+		{"sell more units than held", []models.InvestmentTransaction{
+			txn("b1", models.TxnBuy, "2024-01-01", 10, 1000), txn("x1", models.TxnSell, "2024-06-01", 11, 1200),
+		}, "exceeds the 10 held"},
+		{"sell more cost than held", []models.InvestmentTransaction{
+			txn("g1", models.TxnBuy, "2024-01-01", 0, 50000), oversold,
+		}, "exceeds the 50000 held"},
+		{"sell from nothing", []models.InvestmentTransaction{
+			txn("g1", models.TxnSell, "2024-01-01", 0, 50000),
+		}, "empty holding"},
+		{"sell without units from a unit holding", []models.InvestmentTransaction{
+			txn("b1", models.TxnBuy, "2024-01-01", 10, 1000), txn("x1", models.TxnSell, "2024-06-01", 0, 500),
+		}, "needs units"},
+		{"bonus without units", []models.InvestmentTransaction{
+			txn("g1", models.TxnBuy, "2024-01-01", 0, 50000), txn("bo", models.TxnBonus, "2024-03-01", 0, 0),
+		}, "needs a holding tracked in units"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Build(tt.txns)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Build() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package portfolio
+
+import (
+	"time"
+
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/returns"
+)
+
+// CashFlows converts a holding's transactions into returns cash flows:
+// purchases are payments, sales are withdrawals. Reinvested dividends,
+// bonuses and splits move no cash and are left out.
+func CashFlows(inv models.Investment) []returns.CashFlow {
+	var flows []returns.CashFlow
+	for _, t := range inv.Transactions {
+		date, err := time.Parse(dateLayout, t.Date)
+		if err != nil {
+			continue
+		}
+		switch {
+		case t.Type == models.TxnDividendReinvest || t.Type == models.TxnBonus || t.Type == models.TxnSplit:
+		case t.IsAcquisition():
+			flows = append(flows, returns.CashFlow{Date: date, Amount: -t.Amount})
+		case t.IsDisposal():
+			flows = append(flows, returns.CashFlow{Date: date, Amount: t.Amount})
+		}
+	}
+	return flows
+}
+
+// HoldingReturns is the return of one holding
+type HoldingReturns struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	returns.Summary
+}
+
+// ReturnsReport covers the whole portfolio, each investment type and each
+// holding, valued at Current on AsOf
+type ReturnsReport struct {
+	AsOf      string                     `json:"asOf"`
+	Portfolio returns.Summary            `json:"portfolio"`
+	ByType    map[string]returns.Summary `json:"byType"`
+	Holdings  []HoldingReturns           `json:"holdings"`
+}
+
+// Returns computes the return of a single holding on asOf
+func Returns(inv models.Investment, asOf time.Time) returns.Summary {
+	return returns.Compute(CashFlows(inv), inv.Current, asOf)
+}
+
+// Report computes returns for investments, grouping their cash flows by type
+// and for the portfolio as a whole
+func Report(investments []models.Investment, asOf time.Time) ReturnsReport {
+	report := ReturnsReport{
+		AsOf:     asOf.Format(dateLayout),
+		ByType:   map[string]returns.Summary{},
+		Holdings: make([]HoldingReturns, 0, len(investments)),
+	}
+
+	var all []returns.CashFlow
+	var total float64
+	typeFlows := map[string][]returns.CashFlow{}
+	typeValue := map[string]float64{}
+	for _, inv := range investments {
+		flows := CashFlows(inv)
+		report.Holdings = append(report.Holdings, HoldingReturns{
+			ID:      inv.ID,
+			Name:    inv.Name,
+			Type:    inv.Type,
+			Summary: returns.Compute(flows, inv.Current, asOf),
+		})
+		all = append(all, flows...)
+		total += inv.Current
+		typeFlows[inv.Type] = append(typeFlows[inv.Type], flows...)
+		typeValue[inv.Type] += inv.Current
+	}
+
+	report.Portfolio = returns.Compute(all, total, asOf)
+	for typ, flows := range typeFlows {
+		report.ByType[typ] = returns.Compute(flows, typeValue[typ], asOf)
+	}
+	return report
+}
@@ -0,0 +1,359 @@
+// Package recurring materialises recurring rules into incomes, expenses and
+// investments. Each occurrence gets a deterministic record ID, so posting the
+// same occurrence twice (for example after a crash) never creates duplicates.
+package recurring
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/storage"
+)
+
+const dateLayout = "2006-01-02"
+
+// Occurrence states
+const (
+	StatusPosted    = "posted"    // Record created
+	StatusSkipped   = "skipped"   // Deliberately not posted
+	StatusDue       = "due"       // Date reached, waiting for confirmation
+	StatusScheduled = "scheduled" // In the future
+)
+
+// occurrenceNamespace seeds the deterministic occurrence IDs
+var occurrenceNamespace = uuid.MustParse("9b0f4c1e-4a53-4f57-9b8f-2f1c3f6f8a21")
+
+// Errors returned for bad occurrence requests
+var (
+	ErrRuleNotFound    = errors.New("recurring rule not found")
+	ErrNotAnOccurrence = errors.New("date is not an occurrence of this rule")
+	ErrAlreadyHandled  = errors.New("occurrence has already been posted or skipped")
+	ErrInvalidDate     = errors.New("date must be in YYYY-MM-DD format")
+)
+
+// Occurrence is one scheduled instance of a rule
+type Occurrence struct {
+	RuleID   string  `json:"ruleId"`
+	RuleName string  `json:"ruleName"`
+	Kind     string  `json:"kind"`
+	Date     string  `json:"date"`
+	Amount   float64 `json:"amount"`
+	AutoPost bool    `json:"autoPost"`
+	Status   string  `json:"status"`
+	RecordID string  `json:"recordId"` // ID the posted record has (or will have)
+}
+
+// RunResult summarises one scheduler pass
+type RunResult struct {
+	Posted []Occurrence `json:"posted"`
+	Errors []string     `json:"errors"`
+}
+
+// Engine posts due occurrences. It serialises posting so the background
+// scheduler and API requests cannot post the same occurrence concurrently.
+type Engine struct {
+	mu    sync.Mutex
+	store storage.Storage
+	now   func() time.Time
+}
+
+// NewEngine creates an engine backed by store
+func NewEngine(store storage.Storage) *Engine {
+	return &Engine{store: store, now: time.Now}
+}
+
+// Dates returns the occurrence dates of rule between from and to inclusive.
+// Monthly and yearly rules keep the start date's day, clamped to the end of
+// shorter months (a rule starting on the 31st posts on 30 April).
+func Dates(rule models.RecurringRule, from, to time.Time) []time.Time {
+	start, err := time.Parse(dateLayout, rule.StartDate)
+	if err != nil {
+		return nil
+	}
+	var end time.Time
+	if rule.EndDate != "" {
+		if end, err = time.Parse(dateLayout, rule.EndDate); err == nil && end.Before(to) {
+			to = end
+		}
+	}
+	interval := rule.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var dates []time.Time
+	for n := 0; ; n++ {
+		if rule.Count > 0 && n >= rule.Count {
+			break
+		}
+		d := nth(start, rule.Frequency, interval*n)
+		if d.After(to) {
+			break
+		}
+		if !d.Before(from) {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+// nth returns the date k frequency steps after start
+func nth(start time.Time, frequency string, k int) time.Time {
+	switch frequency {
+	case models.FrequencyDaily:
+		return start.AddDate(0, 0, k)
+	case models.FrequencyWeekly:
+		return start.AddDate(0, 0, 7*k)
+	case models.FrequencyYearly:
+		return calc.AddMonths(start, 12*k)
+	default:
+		return calc.AddMonths(start, k)
+	}
+}
+
+// RecordID is the ID given to the record posted for rule on date
+func RecordID(ruleID, date string) string {
+	return uuid.NewSHA1(occurrenceNamespace, []byte(ruleID+"/"+date)).String()
+}
+
+func amountOf(rule models.RecurringRule) float64 {
+	switch rule.Kind {
+	case models.KindIncome:
+		return rule.Income.Amount
+	case models.KindExpense:
+		return rule.Expense.Amount
+	default:
+		return rule.Investment.Invested
+	}
+}
+
+func (e *Engine) today() time.Time {
+	now := e.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (e *Engine) findRule(id string) (models.RecurringRule, bool) {
+	for _, rule := range e.store.GetRecurringRules() {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return models.RecurringRule{}, false
+}
+
+// Run posts every due occurrence of the auto-post rules, catching up on any
+// dates missed while the server was down
+func (e *Engine) Run() RunResult {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := RunResult{Posted: []Occurrence{}, Errors: []string{}}
+	today := e.today()
+	for _, rule := range e.store.GetRecurringRules() {
+		if !rule.AutoPost || rule.Paused {
+			continue
+		}
+		for _, d := range Dates(rule, time.Time{}, today) {
+			date := d.Format(dateLayout)
+			if rule.Occurrences[date] != "" {
+				continue
+			}
+			occ, err := e.post(&rule, date)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s on %s: %v", rule.Name, date, err))
+				break // retry this rule on the next run
+			}
+			result.Posted = append(result.Posted, occ)
+		}
+	}
+	return result
+}
+
+// Upcoming lists the unhandled occurrences up to days from today: past
+// dates awaiting confirmation first, then scheduled ones
+func (e *Engine) Upcoming(days int) []Occurrence {
+	today := e.today()
+	horizon := today.AddDate(0, 0, days)
+
+	list := []Occurrence{}
+	for _, rule := range e.store.GetRecurringRules() {
+		if rule.Paused {
+			continue
+		}
+		for _, d := range Dates(rule, time.Time{}, horizon) {
+			date := d.Format(dateLayout)
+			if rule.Occurrences[date] != "" {
+				continue
+			}
+			status := StatusScheduled
+			if !d.After(today) {
+				status = StatusDue
+			}
+			list = append(list, Occurrence{
+				RuleID:   rule.ID,
+				RuleName: rule.Name,
+				Kind:     rule.Kind,
+				Date:     date,
+				Amount:   amountOf(rule),
+				AutoPost: rule.AutoPost,
+				Status:   status,
+				RecordID: RecordID(rule.ID, date),
+			})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Date < list[j].Date })
+	return list
+}
+
+// checkOccurrence loads the rule and verifies date is one of its
+// unhandled occurrences
+func (e *Engine) checkOccurrence(ruleID, date string) (models.RecurringRule, error) {
+	rule, ok := e.findRule(ruleID)
+	if !ok {
+		return rule, ErrRuleNotFound
+	}
+	d, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return rule, ErrInvalidDate
+	}
+	if dates := Dates(rule, d, d); len(dates) == 0 {
+		return rule, ErrNotAnOccurrence
+	}
+	if rule.Occurrences[date] != "" {
+		return rule, ErrAlreadyHandled
+	}
+	return rule, nil
+}
+
+// Confirm posts one occurrence of a rule now, whether or not it is due yet
+func (e *Engine) Confirm(ruleID, date string) (Occurrence, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rule, err := e.checkOccurrence(ruleID, date)
+	if err != nil {
+		return Occurrence{}, err
+	}
+	return e.post(&rule, date)
+}
+
+// Skip marks one occurrence as skipped so it is never posted
+func (e *Engine) Skip(ruleID, date string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rule, err := e.checkOccurrence(ruleID, date)
+	if err != nil {
+		return err
+	}
+	return e.mark(&rule, date, StatusSkipped)
+}
+
+// post creates the record for one occurrence (unless it already exists from
+// an interrupted earlier attempt) and marks the occurrence posted
+func (e *Engine) post(rule *models.RecurringRule, date string) (Occurrence, error) {
+	id := RecordID(rule.ID, date)
+	stamp := e.now().Format(time.RFC3339)
+
+	switch rule.Kind {
+	case models.KindIncome:
+		if !e.incomeExists(id) {
+			inc := *rule.Income
+			inc.ID, inc.Date, inc.CreatedAt, inc.UpdatedAt = id, date, stamp, stamp
+			if err := e.store.AddIncome(inc); err != nil {
+				return Occurrence{}, err
+			}
+			if err := e.store.SaveIncomes(); err != nil {
+				return Occurrence{}, err
+			}
+		}
+	case models.KindExpense:
+		if !e.expenseExists(id) {
+			exp := *rule.Expense
+			exp.ID, exp.Date, exp.CreatedAt, exp.UpdatedAt = id, date, stamp, stamp
+			if err := e.store.AddExpense(exp); err != nil {
+				return Occurrence{}, err
+			}
+			if err := e.store.SaveExpenses(); err != nil {
+				return Occurrence{}, err
+			}
+		}
+	case models.KindInvestment:
+		if !e.investmentExists(id) {
+			inv := *rule.Investment
+			inv.ID, inv.Date, inv.CreatedAt, inv.UpdatedAt = id, date, stamp, stamp
+			if err := e.store.AddInvestment(inv); err != nil {
+				return Occurrence{}, err
+			}
+			if err := e.store.SaveInvestments(); err != nil {
+				return Occurrence{}, err
+			}
+		}
+	default:
+		return Occurrence{}, fmt.Errorf("unknown rule kind %q", rule.Kind)
+	}
+
+	if err := e.mark(rule, date, StatusPosted); err != nil {
+		return Occurrence{}, err
+	}
+	return Occurrence{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		Kind:     rule.Kind,
+		Date:     date,
+		Amount:   amountOf(*rule),
+		AutoPost: rule.AutoPost,
+		Status:   StatusPosted,
+		RecordID: id,
+	}, nil
+}
+
+// mark records the state of one occurrence on the rule
+func (e *Engine) mark(rule *models.RecurringRule, date, status string) error {
+	occurrences := make(map[string]string, len(rule.Occurrences)+1)
+	for k, v := range rule.Occurrences {
+		occurrences[k] = v
+	}
+	occurrences[date] = status
+	rule.Occurrences = occurrences
+	rule.UpdatedAt = e.now().Format(time.RFC3339)
+
+	if err := e.store.UpdateRecurringRule(rule.ID, *rule); err != nil {
+		return err
+	}
+	return e.store.SaveRecurringRules()
+}
+
+func (e *Engine) incomeExists(id string) bool {
+	for _, inc := range e.store.GetIncomes() {
+		if inc.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) expenseExists(id string) bool {
+	for _, exp := range e.store.GetExpenses() {
+		if exp.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) investmentExists(id string) bool {
+	for _, inv := range e.store.GetInvestments() {
+		if inv.ID == id {
+			return true
+		}
+	}
+	return false
+}
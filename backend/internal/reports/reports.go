@@ -0,0 +1,440 @@
+// Package reports computes cash-flow and spending summaries from stored
+// incomes and expenses, so every client gets the same numbers.
+package reports
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// Range is an inclusive date range
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// String formats the range as "YYYY-MM-DD..YYYY-MM-DD"
+func (r Range) String() string {
+	return r.From.Format(dateLayout) + ".." + r.To.Format(dateLayout)
+}
+
+// Days returns the number of days in the range
+func (r Range) Days() int {
+	return int(r.To.Sub(r.From).Hours()/24) + 1
+}
+
+// Contains reports whether t falls within the range
+func (r Range) Contains(t time.Time) bool {
+	return !t.Before(r.From) && !t.After(r.To)
+}
+
+// Previous returns the range of equal length ending the day before r
+func (r Range) Previous() Range {
+	return Range{From: r.From.AddDate(0, 0, -r.Days()), To: r.From.AddDate(0, 0, -1)}
+}
+
+// YearAgo returns the same dates one year earlier
+func (r Range) YearAgo() Range {
+	return Range{From: r.From.AddDate(-1, 0, 0), To: r.To.AddDate(-1, 0, 0)}
+}
+
+// ComparisonStart is the earliest date needed to compare r with both the
+// previous period and the same dates last year
+func (r Range) ComparisonStart() time.Time {
+	prev, yearAgo := r.Previous().From, r.YearAgo().From
+	if prev.Before(yearAgo) {
+		return prev
+	}
+	return yearAgo
+}
+
+// ParseRange reads from/to as YYYY-MM-DD or YYYY-MM. A month "to" means the
+// end of that month. Missing values default to the twelve months ending with
+// the current month.
+func ParseRange(from, to string, now time.Time) (Range, error) {
+	var r Range
+	var err error
+
+	if to == "" {
+		r.To = endOfMonth(now)
+	} else if r.To, err = parseDay(to, true); err != nil {
+		return r, fmt.Errorf("to: %w", err)
+	}
+
+	if from == "" {
+		r.From = startOfMonth(r.To).AddDate(0, -11, 0)
+	} else if r.From, err = parseDay(from, false); err != nil {
+		return r, fmt.Errorf("from: %w", err)
+	}
+
+	if r.From.After(r.To) {
+		return r, fmt.Errorf("from date cannot be after to date")
+	}
+	return r, nil
+}
+
+func parseDay(s string, end bool) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or YYYY-MM, got %q", s)
+	}
+	if end {
+		return endOfMonth(t), nil
+	}
+	return t, nil
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func endOfMonth(t time.Time) time.Time {
+	return startOfMonth(t).AddDate(0, 1, -1)
+}
+
+// parseRecordDate reads the day part of a stored date ("2025-01-31" or
+// "2025-01-31T10:00"); ok is false for dates that cannot be placed
+func parseRecordDate(s string) (time.Time, bool) {
+	if len(s) < len(dateLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(dateLayout, s[:len(dateLayout)])
+	return t, err == nil
+}
+
+// Delta compares a value with an earlier one
+type Delta struct {
+	Previous float64  `json:"previous"`
+	Change   float64  `json:"change"`
+	Percent  *float64 `json:"percent,omitempty"` // Omitted when Previous is 0
+}
+
+func newDelta(current, previous float64) *Delta {
+	d := &Delta{Previous: calc.Round2(previous), Change: calc.Round2(current - previous)}
+	if previous != 0 {
+		p := calc.Round2((current - previous) / math.Abs(previous) * 100)
+		d.Percent = &p
+	}
+	return d
+}
+
+// savingsRate is the share of income not spent, in percent
+func savingsRate(income, expense float64) float64 {
+	if income <= 0 {
+		return 0
+	}
+	return calc.Round2((income - expense) / income * 100)
+}
+
+// ----- CASH FLOW -----
+
+// MonthlyCashFlow is one month of income against expense
+type MonthlyCashFlow struct {
+	Month       string  `json:"month"` // YYYY-MM
+	Income      float64 `json:"income"`
+	Expense     float64 `json:"expense"`
+	NetSavings  float64 `json:"netSavings"`
+	SavingsRate float64 `json:"savingsRate"` // Percent of income saved
+	IncomeMoM   *Delta  `json:"incomeMoM"`
+	ExpenseMoM  *Delta  `json:"expenseMoM"`
+	IncomeYoY   *Delta  `json:"incomeYoY"`
+	ExpenseYoY  *Delta  `json:"expenseYoY"`
+}
+
+// Totals summarises a range
+type Totals struct {
+	Income      float64 `json:"income"`
+	Expense     float64 `json:"expense"`
+	NetSavings  float64 `json:"netSavings"`
+	SavingsRate float64 `json:"savingsRate"`
+}
+
+func newTotals(income, expense float64) Totals {
+	return Totals{
+		Income:      calc.Round2(income),
+		Expense:     calc.Round2(expense),
+		NetSavings:  calc.Round2(income - expense),
+		SavingsRate: savingsRate(income, expense),
+	}
+}
+
+// CashFlowReport is the per-month cash flow for a range
+type CashFlowReport struct {
+	From   string            `json:"from"`
+	To     string            `json:"to"`
+	Months []MonthlyCashFlow `json:"months"`
+	Totals Totals            `json:"totals"`
+}
+
+// CashFlow buckets incomes and expenses by month. Inputs should cover
+// r.ComparisonStart() through r.To so the first months get their deltas;
+// only records within r count towards the months and totals reported.
+func CashFlow(incomes []models.Income, expenses []models.Expense, r Range) CashFlowReport {
+	// All months, for the deltas, and the part of each month within r
+	income, expense := map[string]float64{}, map[string]float64{}
+	inRangeIncome, inRangeExpense := map[string]float64{}, map[string]float64{}
+	for _, inc := range incomes {
+		if t, ok := parseRecordDate(inc.Date); ok {
+			income[t.Format("2006-01")] += inc.Amount
+			if r.Contains(t) {
+				inRangeIncome[t.Format("2006-01")] += inc.Amount
+			}
+		}
+	}
+	for _, exp := range expenses {
+		if t, ok := parseRecordDate(exp.Date); ok {
+			expense[t.Format("2006-01")] += exp.Amount
+			if r.Contains(t) {
+				inRangeExpense[t.Format("2006-01")] += exp.Amount
+			}
+		}
+	}
+
+	report := CashFlowReport{From: r.From.Format(dateLayout), To: r.To.Format(dateLayout), Months: []MonthlyCashFlow{}}
+	var totalIncome, totalExpense float64
+	for m := startOfMonth(r.From); !m.After(r.To); m = m.AddDate(0, 1, 0) {
+		key := m.Format("2006-01")
+		prev := m.AddDate(0, -1, 0).Format("2006-01")
+		lastYear := m.AddDate(-1, 0, 0).Format("2006-01")
+		inc, exp := inRangeIncome[key], inRangeExpense[key]
+
+		report.Months = append(report.Months, MonthlyCashFlow{
+			Month:       key,
+			Income:      calc.Round2(inc),
+			Expense:     calc.Round2(exp),
+			NetSavings:  calc.Round2(inc - exp),
+			SavingsRate: savingsRate(inc, exp),
+			IncomeMoM:   newDelta(inc, income[prev]),
+			ExpenseMoM:  newDelta(exp, expense[prev]),
+			IncomeYoY:   newDelta(inc, income[lastYear]),
+			ExpenseYoY:  newDelta(exp, expense[lastYear]),
+		})
+		totalIncome += inc
+		totalExpense += exp
+	}
+	report.Totals = newTotals(totalIncome, totalExpense)
+	return report
+}
+
+// ----- BREAKDOWN -----
+
+// Expense grouping dimensions
+const (
+	GroupByCategory      = "category"
+	GroupByMember        = "addedBy"
+	GroupByPaymentMethod = "paymentMethod"
+	GroupByTag           = "tag"
+)
+
+// GroupByOptions lists the accepted grouping dimensions
+var GroupByOptions = []string{GroupByCategory, GroupByMember, GroupByPaymentMethod, GroupByTag}
+
+// ValidGroupBy reports whether g is a supported grouping dimension
+func ValidGroupBy(g string) bool {
+	for _, opt := range GroupByOptions {
+		if opt == g {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupTotal is the expense total for one group
+type GroupTotal struct {
+	Key   string  `json:"key"`
+	Total float64 `json:"total"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"` // Percent of all expenses in the range; by tag, shares overlap
+	Prior *Delta  `json:"prior"` // Against the previous period of equal length
+	YoY   *Delta  `json:"yoy"`   // Against the same dates last year
+}
+
+// BreakdownReport groups the expenses of a range
+type BreakdownReport struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	GroupBy string       `json:"groupBy"`
+	Total   float64      `json:"total"`
+	Groups  []GroupTotal `json:"groups"`
+}
+
+// groupKeys lists the groups exp counts towards: one, or by tag one per tag
+func groupKeys(exp models.Expense, groupBy string) []string {
+	var key string
+	switch groupBy {
+	case GroupByMember:
+		key = exp.AddedBy
+	case GroupByPaymentMethod:
+		key = exp.PaymentMethod
+	case GroupByTag:
+		if len(exp.Tags) == 0 {
+			return []string{"Untagged"}
+		}
+		return exp.Tags
+	default:
+		key = exp.Category
+	}
+	if strings.TrimSpace(key) == "" {
+		return []string{"Unknown"}
+	}
+	return []string{key}
+}
+
+// Breakdown totals expenses in r by groupBy and compares each group with the
+// previous period and the same period last year. Split expenses count line
+// by line. expenses should cover r.ComparisonStart() through r.To.
+func Breakdown(expenses []models.Expense, groupBy string, r Range) BreakdownReport {
+	prior, yearAgo := r.Previous(), r.YearAgo()
+	current := map[string]*GroupTotal{}
+	priorTotals := map[string]float64{}
+	yoyTotals := map[string]float64{}
+	var total float64
+	// Tags differing only in case are one group, spelled as first seen
+	spelling := map[string]string{}
+
+	for _, exp := range lines(expenses) {
+		t, ok := parseRecordDate(exp.Date)
+		if !ok {
+			continue
+		}
+		if r.Contains(t) {
+			total += exp.Amount
+		}
+		for _, key := range groupKeys(exp, groupBy) {
+			if groupBy == GroupByTag {
+				if first, ok := spelling[strings.ToLower(key)]; ok {
+					key = first
+				} else {
+					spelling[strings.ToLower(key)] = key
+				}
+			}
+			if r.Contains(t) {
+				g, ok := current[key]
+				if !ok {
+					g = &GroupTotal{Key: key}
+					current[key] = g
+				}
+				g.Total += exp.Amount
+				g.Count++
+			}
+			if prior.Contains(t) {
+				priorTotals[key] += exp.Amount
+			}
+			if yearAgo.Contains(t) {
+				yoyTotals[key] += exp.Amount
+			}
+		}
+	}
+
+	// Groups that only had spending in a comparison period still show up
+	for key := range priorTotals {
+		if _, ok := current[key]; !ok {
+			current[key] = &GroupTotal{Key: key}
+		}
+	}
+	for key := range yoyTotals {
+		if _, ok := current[key]; !ok {
+			current[key] = &GroupTotal{Key: key}
+		}
+	}
+
+	report := BreakdownReport{
+		From:    r.From.Format(dateLayout),
+		To:      r.To.Format(dateLayout),
+		GroupBy: groupBy,
+		Total:   calc.Round2(total),
+		Groups:  make([]GroupTotal, 0, len(current)),
+	}
+	for key, g := range current {
+		g.Prior = newDelta(g.Total, priorTotals[key])
+		g.YoY = newDelta(g.Total, yoyTotals[key])
+		g.Total = calc.Round2(g.Total)
+		if total > 0 {
+			g.Share = calc.Round2(g.Total / total * 100)
+		}
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Total != report.Groups[j].Total {
+			return report.Groups[i].Total > report.Groups[j].Total
+		}
+		return report.Groups[i].Key < report.Groups[j].Key
+	})
+	return report
+}
+
+// lines spreads split expenses into their lines
+func lines(expenses []models.Expense) []models.Expense {
+	out := make([]models.Expense, 0, len(expenses))
+	for _, exp := range expenses {
+		out = append(out, exp.Lines()...)
+	}
+	return out
+}
+
+// ----- SUMMARY -----
+
+// SummaryReport is the headline numbers for a range
+type SummaryReport struct {
+	From          string       `json:"from"`
+	To            string       `json:"to"`
+	Totals        Totals       `json:"totals"`
+	Prior         Totals       `json:"prior"`         // Previous period of equal length
+	YearAgo       Totals       `json:"yearAgo"`       // Same dates last year
+	IncomeChange  *Delta       `json:"incomeChange"`  // Against Prior
+	ExpenseChange *Delta       `json:"expenseChange"` // Against Prior
+	IncomeYoY     *Delta       `json:"incomeYoY"`
+	ExpenseYoY    *Delta       `json:"expenseYoY"`
+	ByCategory    []GroupTotal `json:"byCategory"`
+	ByMember      []GroupTotal `json:"byMember"`
+	ByTag         []GroupTotal `json:"byTag"`
+}
+
+// Summary combines range totals, comparisons and the category, member and
+// tag breakdowns. Inputs should cover r.ComparisonStart() through r.To.
+func Summary(incomes []models.Income, expenses []models.Expense, r Range) SummaryReport {
+	sum := func(rg Range) (float64, float64) {
+		var inc, exp float64
+		for _, i := range incomes {
+			if t, ok := parseRecordDate(i.Date); ok && rg.Contains(t) {
+				inc += i.Amount
+			}
+		}
+		for _, e := range expenses {
+			if t, ok := parseRecordDate(e.Date); ok && rg.Contains(t) {
+				exp += e.Amount
+			}
+		}
+		return inc, exp
+	}
+
+	inc, exp := sum(r)
+	priorInc, priorExp := sum(r.Previous())
+	yoyInc, yoyExp := sum(r.YearAgo())
+
+	return SummaryReport{
+		From:          r.From.Format(dateLayout),
+		To:            r.To.Format(dateLayout),
+		Totals:        newTotals(inc, exp),
+		Prior:         newTotals(priorInc, priorExp),
+		YearAgo:       newTotals(yoyInc, yoyExp),
+		IncomeChange:  newDelta(inc, priorInc),
+		ExpenseChange: newDelta(exp, priorExp),
+		IncomeYoY:     newDelta(inc, yoyInc),
+		ExpenseYoY:    newDelta(exp, yoyExp),
+		ByCategory:    Breakdown(expenses, GroupByCategory, r).Groups,
+		ByMember:      Breakdown(expenses, GroupByMember, r).Groups,
+		ByTag:         Breakdown(expenses, GroupByTag, r).Groups,
+	}
+}
@@ -0,0 +1,188 @@
+// Package returns computes investment returns from dated cash flows: XIRR,
+// CAGR and absolute return. Amounts follow the spreadsheet convention:
+// money paid in is negative, money taken out (and the final value) positive.
+package returns
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// CashFlow is one dated amount
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// Errors returned by XIRR
+var (
+	ErrNoSignChange  = errors.New("cash flows need at least one negative and one positive amount")
+	ErrNoConvergence = errors.New("XIRR did not converge")
+)
+
+const (
+	daysPerYear   = 365.0 // Actual/365, as spreadsheet XIRR
+	tolerance     = 1e-9
+	maxIterations = 100
+	minRate       = -0.999999999
+)
+
+// XIRR returns the annual rate r at which the flows' net present value is
+// zero, discounting each flow by (1+r)^(days since the first flow / 365).
+// Newton–Raphson is tried first; if it fails to converge or leaves the valid
+// range, the root is bracketed and found by bisection.
+func XIRR(flows []CashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, ErrNoSignChange
+	}
+	flows = append([]CashFlow(nil), flows...)
+	sort.SliceStable(flows, func(i, j int) bool { return flows[i].Date.Before(flows[j].Date) })
+
+	hasNeg, hasPos := false, false
+	for _, f := range flows {
+		hasNeg = hasNeg || f.Amount < 0
+		hasPos = hasPos || f.Amount > 0
+	}
+	if !hasNeg || !hasPos {
+		return 0, ErrNoSignChange
+	}
+
+	start := flows[0].Date
+	years := make([]float64, len(flows))
+	for i, f := range flows {
+		years[i] = f.Date.Sub(start).Hours() / 24 / daysPerYear
+	}
+
+	if r, ok := newton(flows, years, 0.1); ok {
+		return r, nil
+	}
+	return bisect(flows, years)
+}
+
+// npv returns the net present value of flows at rate and its derivative
+func npv(flows []CashFlow, years []float64, rate float64) (value, deriv float64) {
+	for i, f := range flows {
+		d := math.Pow(1+rate, years[i])
+		value += f.Amount / d
+		deriv -= years[i] * f.Amount / (d * (1 + rate))
+	}
+	return value, deriv
+}
+
+func newton(flows []CashFlow, years []float64, guess float64) (float64, bool) {
+	rate := guess
+	for i := 0; i < maxIterations; i++ {
+		value, deriv := npv(flows, years, rate)
+		if deriv == 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+			return 0, false
+		}
+		next := rate - value/deriv
+		if next <= minRate || math.IsNaN(next) {
+			return 0, false
+		}
+		if math.Abs(next-rate) < tolerance {
+			return next, true
+		}
+		rate = next
+	}
+	return 0, false
+}
+
+func bisect(flows []CashFlow, years []float64) (float64, error) {
+	lo, hi := minRate, 1.0
+	fLo, _ := npv(flows, years, lo)
+	fHi, _ := npv(flows, years, hi)
+	for fLo*fHi > 0 {
+		if hi > 1e6 {
+			return 0, ErrNoConvergence
+		}
+		hi *= 2
+		fHi, _ = npv(flows, years, hi)
+	}
+
+	for i := 0; i < 1000; i++ {
+		mid := (lo + hi) / 2
+		fMid, _ := npv(flows, years, mid)
+		if math.Abs(fMid) < tolerance || hi-lo < tolerance {
+			return mid, nil
+		}
+		if fLo*fMid < 0 {
+			hi = mid
+		} else {
+			lo, fLo = mid, fMid
+		}
+	}
+	return 0, ErrNoConvergence
+}
+
+// CAGR is the constant annual rate that grows begin into end over years
+func CAGR(begin, end, years float64) (float64, bool) {
+	if begin <= 0 || end < 0 || years <= 0 {
+		return 0, false
+	}
+	return math.Pow(end/begin, 1/years) - 1, true
+}
+
+// Absolute is the total gain as a fraction of the amount invested
+func Absolute(invested, value float64) float64 {
+	if invested == 0 {
+		return 0
+	}
+	return (value - invested) / invested
+}
+
+// Summary reports the returns of one set of cash flows valued at a date.
+// Rates are fractions (0.12 for 12%); CAGR and XIRR are nil when they cannot
+// be computed, e.g. before any money has been put in.
+type Summary struct {
+	Invested  float64  `json:"invested"`  // Total paid in
+	Withdrawn float64  `json:"withdrawn"` // Total taken out
+	Value     float64  `json:"value"`     // Value still held
+	Gain      float64  `json:"gain"`      // Value + withdrawn - invested
+	Absolute  float64  `json:"absolute"`
+	CAGR      *float64 `json:"cagr"`
+	XIRR      *float64 `json:"xirr"`
+	Days      int      `json:"days"` // From the first flow to the valuation date
+}
+
+// Compute summarises flows (payments negative, withdrawals positive) with
+// the remaining value held on asOf. CAGR treats the whole amount as invested
+// on the first flow date, which is exact for a lump sum and understates
+// returns for staggered investments; XIRR accounts for the timing.
+func Compute(flows []CashFlow, value float64, asOf time.Time) Summary {
+	var s Summary
+	s.Value = value
+	if len(flows) == 0 {
+		return s
+	}
+
+	first := flows[0].Date
+	for _, f := range flows {
+		if f.Amount < 0 {
+			s.Invested -= f.Amount
+		} else {
+			s.Withdrawn += f.Amount
+		}
+		if f.Date.Before(first) {
+			first = f.Date
+		}
+	}
+	s.Gain = value + s.Withdrawn - s.Invested
+	s.Absolute = Absolute(s.Invested, value+s.Withdrawn)
+	s.Days = int(asOf.Sub(first).Hours() / 24)
+
+	if c, ok := CAGR(s.Invested, value+s.Withdrawn, float64(s.Days)/daysPerYear); ok {
+		s.CAGR = &c
+	}
+
+	all := flows
+	if value > 0 {
+		all = append(append([]CashFlow(nil), flows...), CashFlow{Date: asOf, Amount: value})
+	}
+	if x, err := XIRR(all); err == nil {
+		s.XIRR = &x
+	}
+	return s
+}
@@ -0,0 +1,248 @@
+package returns
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func monthly(from string, n int, amount float64) []CashFlow {
+	start := day(from)
+	flows := make([]CashFlow, n)
+	for i := range flows {
+		flows[i] = CashFlow{Date: start.AddDate(0, i, 0), Amount: amount}
+	}
+	return flows
+}
+
+func TestXIRR(t *testing.T) {
+	// Expected values match spreadsheet XIRR (Actual/365)
+	tests := []struct {
+		name  string
+		flows []CashFlow
+		want  float64
+	}{
+		{
+			name: "spreadsheet documentation example",
+			flows: []CashFlow{
+				{day("2008-01-01"), -10000},
+				{day("2008-03-01"), 2750},
+				{day("2008-10-30"), 4250},
+				{day("2009-02-15"), 3250},
+				{day("2009-04-01"), 2750},
+			},
+			want: 0.373362534,
+		},
+		{
+			name:  "lump sum over a leap year",
+			flows: []CashFlow{{day("2020-01-01"), -1000}, {day("2021-01-01"), 1100}},
+			want:  0.099713586,
+		},
+		{
+			name:  "monthly SIP",
+			flows: append(monthly("2023-01-01", 12, -1000), CashFlow{day("2024-01-01"), 13000}),
+			want:  0.156698351,
+		},
+		{
+			name:  "loss",
+			flows: []CashFlow{{day("2022-01-01"), -10000}, {day("2024-01-01"), 7000}},
+			want:  -0.163339973,
+		},
+		{
+			name: "partial redemptions",
+			flows: []CashFlow{
+				{day("2021-04-01"), -5000},
+				{day("2021-10-01"), -5000},
+				{day("2022-04-01"), 3000},
+				{day("2023-04-01"), 9000},
+			},
+			want: 0.130029087,
+		},
+		{
+			name: "unsorted input",
+			flows: []CashFlow{
+				{day("2023-04-01"), 9000},
+				{day("2022-04-01"), 3000},
+				{day("2021-10-01"), -5000},
+				{day("2021-04-01"), -5000},
+			},
+			want: 0.130029087,
+		},
+		{
+			name:  "very high short-term return needs bisection",
+			flows: []CashFlow{{day("2024-01-01"), -100}, {day("2024-01-31"), 200}},
+			want:  4596.604549875,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := XIRR(tt.flows)
+			if err != nil {
+				t.Fatalf("XIRR() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-6*math.Max(1, math.Abs(tt.want)) {
+				t.Errorf("XIRR() = %.9f, want %.9f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXIRRErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []CashFlow
+	}{
+		{"no flows", nil},
+		{"single flow", []CashFlow{{day("2024-01-01"), -100}}},
+		{"only payments", []CashFlow{{day("2024-01-01"), -100}, {day("2024-06-01"), -100}}},
+		{"only withdrawals", []CashFlow{{day("2024-01-01"), 100}, {day("2024-06-01"), 100}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := XIRR(tt.flows); !errors.Is(err, ErrNoSignChange) {
+				t.Errorf("XIRR() error = %v, want %v", err, ErrNoSignChange)
+			}
+		})
+	}
+}
+
+func TestCAGR(t *testing.T) {
+	tests := []struct {
+		name       string
+		begin, end float64
+		years      float64
+		want       float64
+		ok         bool
+	}{
+		{"doubling in five years", 1000, 2000, 5, 0.148698355, true},
+		{"flat", 1000, 1000, 3, 0, true},
+		{"halving in two years", 1000, 500, 2, -0.292893219, true},
+		{"under a year", 1000, 1050, 0.5, 0.1025, true},
+		{"nothing invested", 0, 100, 1, 0, false},
+		{"no time elapsed", 1000, 1100, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CAGR(tt.begin, tt.end, tt.years)
+			if ok != tt.ok {
+				t.Fatalf("CAGR() ok = %v, want %v", ok, tt.ok)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CAGR() = %.9f, want %.9f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbsolute(t *testing.T) {
+	tests := []struct {
+		invested, value, want float64
+	}{
+		{1000, 1250, 0.25},
+		{1000, 800, -0.2},
+		{1000, 1000, 0},
+		{0, 500, 0},
+	}
+
+	for _, tt := range tests {
+		if got := Absolute(tt.invested, tt.value); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Absolute(%v, %v) = %v, want %v", tt.invested, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name     string
+		flows    []CashFlow
+		value    float64
+		asOf     string
+		want     Summary
+		wantCAGR float64
+		wantXIRR float64
+		noCAGR   bool
+		noXIRR   bool
+	}{
+		{
+			name:     "monthly SIP still held",
+			flows:    monthly("2023-01-01", 12, -1000),
+			value:    13000,
+			asOf:     "2024-01-01",
+			want:     Summary{Invested: 12000, Value: 13000, Gain: 1000, Absolute: 1000.0 / 12000, Days: 365},
+			wantCAGR: 1000.0 / 12000,
+			wantXIRR: 0.156698351,
+		},
+		{
+			name: "fully redeemed",
+			flows: []CashFlow{
+				{day("2021-04-01"), -5000},
+				{day("2021-10-01"), -5000},
+				{day("2022-04-01"), 3000},
+				{day("2023-04-01"), 9000},
+			},
+			value:    0,
+			asOf:     "2023-04-01",
+			want:     Summary{Invested: 10000, Withdrawn: 12000, Gain: 2000, Absolute: 0.2, Days: 730},
+			wantCAGR: 0.095445115,
+			wantXIRR: 0.130029087,
+		},
+		{
+			name:   "nothing invested",
+			asOf:   "2024-01-01",
+			want:   Summary{},
+			noCAGR: true,
+			noXIRR: true,
+		},
+		{
+			name:     "total loss",
+			flows:    []CashFlow{{day("2023-01-01"), -1000}},
+			value:    0,
+			asOf:     "2024-01-01",
+			want:     Summary{Invested: 1000, Gain: -1000, Absolute: -1, Days: 365},
+			wantCAGR: -1,
+			noXIRR:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(tt.flows, tt.value, day(tt.asOf))
+
+			if math.Abs(got.Invested-tt.want.Invested) > 1e-9 || math.Abs(got.Withdrawn-tt.want.Withdrawn) > 1e-9 ||
+				math.Abs(got.Value-tt.want.Value) > 1e-9 || math.Abs(got.Gain-tt.want.Gain) > 1e-9 ||
+				math.Abs(got.Absolute-tt.want.Absolute) > 1e-9 || got.Days != tt.want.Days {
+				t.Errorf("Compute() = %+v, want %+v", got, tt.want)
+			}
+
+			switch {
+			case tt.noCAGR && got.CAGR != nil:
+				t.Errorf("CAGR = %v, want nil", *got.CAGR)
+			case !tt.noCAGR && got.CAGR == nil:
+				t.Errorf("CAGR = nil, want %.9f", tt.wantCAGR)
+			case !tt.noCAGR && math.Abs(*got.CAGR-tt.wantCAGR) > 1e-6:
+				t.Errorf("CAGR = %.9f, want %.9f", *got.CAGR, tt.wantCAGR)
+			}
+
+			switch {
+			case tt.noXIRR && got.XIRR != nil:
+				t.Errorf("XIRR = %v, want nil", *got.XIRR)
+			case !tt.noXIRR && got.XIRR == nil:
+				t.Errorf("XIRR = nil, want %.9f", tt.wantXIRR)
+			case !tt.noXIRR && math.Abs(*got.XIRR-tt.wantXIRR) > 1e-6:
+				t.Errorf("XIRR = %.9f, want %.9f", *got.XIRR, tt.wantXIRR)
+			}
+		})
+	}
+}
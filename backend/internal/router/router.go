@@ -10,13 +10,14 @@ import (
 	"finance-tracker/internal/storage"
 )
 
-// RegisterRoutes sets up all API routes and returns the configured Mux router
-func RegisterRoutes(store storage.Storage) *mux.Router {
-	h := handlers.NewHandler(store)
+// RegisterRoutes sets up all API routes and returns the configured Mux router.
+// Browsers may call the API from corsOrigins.
+func RegisterRoutes(store storage.Storage, svc handlers.Services, corsOrigins []string) *mux.Router {
+	h := handlers.NewHandler(store, svc)
 	r := mux.NewRouter()
 
 	// Apply CORS middleware to all routes
-	r.Use(middleware.CORS)
+	r.Use(middleware.CORS(corsOrigins...))
 
 	// Health check endpoint (unversioned, always available)
 	r.HandleFunc("/health", h.HealthCheck).Methods("GET")
@@ -24,6 +25,11 @@ func RegisterRoutes(store storage.Storage) *mux.Router {
 	// API v1 routes
 	api := r.PathPrefix("/v1/api").Subrouter()
 
+	// Every API route needs a logged-in user, apart from logging in and
+	// creating the first user, whose role allows the route
+	api.Use(middleware.Auth(svc.Auth.Authenticate, "/v1/api/auth/login", "/v1/api/auth/setup"))
+	api.Use(h.Authorize)
+
 	// Catch-all OPTIONS handler for CORS preflight requests
 	// This must be registered BEFORE specific routes
 	api.PathPrefix("").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -33,10 +39,40 @@ func RegisterRoutes(store storage.Storage) *mux.Router {
 		}
 	}).Methods("OPTIONS")
 
+	// Authentication, user and API token routes
+	api.HandleFunc("/auth/setup", h.Setup).Methods("POST")
+	api.HandleFunc("/auth/login", h.Login).Methods("POST")
+	api.HandleFunc("/auth/logout", h.Logout).Methods("POST")
+	api.HandleFunc("/auth/me", h.Me).Methods("GET")
+	api.HandleFunc("/auth/password", h.ChangePassword).Methods("POST")
+	api.HandleFunc("/users", h.UsersHandler).Methods("GET", "POST")
+	api.HandleFunc("/users/{id}", h.UserHandler).Methods("PUT", "DELETE")
+	api.HandleFunc("/tokens", h.TokensHandler).Methods("GET", "POST")
+	api.HandleFunc("/tokens/{id}", h.RevokeToken).Methods("DELETE")
+
 	// Investment routes
 	api.HandleFunc("/investments", h.InvestmentsHandler).Methods("GET", "POST")
 	api.HandleFunc("/investments/{id}", h.InvestmentHandler).Methods("GET", "PUT", "DELETE")
 	api.HandleFunc("/investments/refresh-nav", h.RefreshNAV).Methods("POST")
+	api.HandleFunc("/investments/{id}/history", h.InvestmentHistory).Methods("GET")
+	api.HandleFunc("/investments/{id}/lots", h.GetInvestmentLots).Methods("GET")
+	api.HandleFunc("/investments/{id}/transactions", h.InvestmentTransactionsHandler).Methods("GET", "POST")
+	api.HandleFunc("/investments/{id}/transactions/{txnId}", h.InvestmentTransactionHandler).Methods("PUT", "DELETE")
+	api.HandleFunc("/investments/{id}/returns", h.InvestmentReturns).Methods("GET")
+	api.HandleFunc("/investments/{id}/deposit", h.InvestmentDeposit).Methods("GET")
+	api.HandleFunc("/deposits/maturities", h.DepositMaturities).Methods("GET")
+	api.HandleFunc("/deposits/accrue", h.AccrueDeposits).Methods("POST")
+	api.HandleFunc("/portfolio/history", h.PortfolioHistory).Methods("GET")
+	api.HandleFunc("/portfolio/returns", h.PortfolioReturns).Methods("GET")
+
+	// Account routes
+	api.HandleFunc("/accounts", h.AccountsHandler).Methods("GET", "POST")
+	api.HandleFunc("/accounts/balances", h.AccountBalances).Methods("GET")
+	api.HandleFunc("/accounts/{id}", h.AccountHandler).Methods("GET", "PUT", "DELETE")
+	api.HandleFunc("/accounts/{id}/ledger", h.AccountLedger).Methods("GET")
+	api.HandleFunc("/accounts/{id}/statements", h.CardStatements).Methods("GET")
+	api.HandleFunc("/transfers", h.TransfersHandler).Methods("GET", "POST")
+	api.HandleFunc("/transfers/{id}", h.TransferHandler).Methods("PUT", "DELETE")
 
 	// Income routes
 	api.HandleFunc("/incomes", h.IncomesHandler).Methods("GET", "POST")
@@ -49,9 +85,77 @@ func RegisterRoutes(store storage.Storage) *mux.Router {
 	// Settings routes
 	api.HandleFunc("/settings", h.SettingsHandler).Methods("GET", "PUT")
 
+	// Budget routes (status before {id} so it is not taken as an ID)
+	api.HandleFunc("/budgets", h.BudgetsHandler).Methods("GET", "POST")
+	api.HandleFunc("/budgets/status", h.BudgetStatus).Methods("GET")
+	api.HandleFunc("/budgets/{id}", h.BudgetHandler).Methods("PUT", "DELETE")
+
+	// Recurring transaction routes
+	api.HandleFunc("/recurring", h.RecurringRulesHandler).Methods("GET", "POST")
+	api.HandleFunc("/recurring/upcoming", h.UpcomingOccurrences).Methods("GET")
+	api.HandleFunc("/recurring/run", h.RunRecurring).Methods("POST")
+	api.HandleFunc("/recurring/{id}", h.RecurringRuleHandler).Methods("PUT", "DELETE")
+	api.HandleFunc("/recurring/{id}/occurrences/{date}/confirm", h.ConfirmOccurrence).Methods("POST")
+	api.HandleFunc("/recurring/{id}/occurrences/{date}/skip", h.SkipOccurrence).Methods("POST")
+
+	// Chit fund routes (status and upcoming before {id})
+	api.HandleFunc("/chits", h.ChitsHandler).Methods("GET", "POST")
+	api.HandleFunc("/chits/status", h.ChitsStatus).Methods("GET")
+	api.HandleFunc("/chits/upcoming", h.UpcomingInstalments).Methods("GET")
+	api.HandleFunc("/chits/{id}", h.ChitHandler).Methods("GET", "PUT", "DELETE")
+	api.HandleFunc("/chits/{id}/auctions", h.RecordChitAuction).Methods("POST")
+
+	// Loan routes (status and tax before {id})
+	api.HandleFunc("/loans", h.LoansHandler).Methods("GET", "POST")
+	api.HandleFunc("/loans/status", h.LoansStatus).Methods("GET")
+	api.HandleFunc("/loans/tax", h.LoanTaxReport).Methods("GET")
+	api.HandleFunc("/loans/{id}", h.LoanHandler).Methods("GET", "PUT", "DELETE")
+	api.HandleFunc("/loans/{id}/schedule", h.GetLoanSchedule).Methods("GET")
+	api.HandleFunc("/loans/{id}/prepayments", h.AddLoanPrepayment).Methods("POST")
+	api.HandleFunc("/loans/{id}/rate-changes", h.AddLoanRateChange).Methods("POST")
+	api.HandleFunc("/networth", h.NetWorth).Methods("GET")
+
+	// Duplicate routes
+	api.HandleFunc("/duplicates", h.GetDuplicates).Methods("GET")
+	api.HandleFunc("/duplicates/merge", h.MergeDuplicates).Methods("POST")
+	api.HandleFunc("/duplicates/merges", h.GetMerges).Methods("GET")
+
+	// Shared expense routes
+	api.HandleFunc("/balances", h.GetBalances).Methods("GET")
+	api.HandleFunc("/balances/settle", h.SettleBalances).Methods("POST")
+	api.HandleFunc("/settlements", h.SettlementsHandler).Methods("GET", "POST")
+	api.HandleFunc("/settlements/{id}", h.SettlementHandler).Methods("PUT", "DELETE")
+
+	// Tag routes
+	api.HandleFunc("/tags", h.GetTags).Methods("GET")
+	api.HandleFunc("/tags/rename", h.RenameTag).Methods("POST")
+	api.HandleFunc("/tags/merge", h.MergeTags).Methods("POST")
+
+	// Categorisation rule routes (reapply before {id})
+	api.HandleFunc("/rules", h.RulesHandler).Methods("GET", "POST")
+	api.HandleFunc("/rules/reapply", h.ReapplyRulesHandler).Methods("GET", "POST")
+	api.HandleFunc("/rules/{id}", h.RuleHandler).Methods("PUT", "DELETE")
+
+	// Report routes
+	api.HandleFunc("/reports/summary", h.SummaryReport).Methods("GET")
+	api.HandleFunc("/reports/cashflow", h.CashFlowReport).Methods("GET")
+	api.HandleFunc("/reports/breakdown", h.BreakdownReport).Methods("GET")
+	api.HandleFunc("/reports/capital-gains", h.CapitalGainsReport).Methods("GET")
+
 	// Export/Import routes
 	api.HandleFunc("/export", h.ExportData).Methods("GET")
 	api.HandleFunc("/import", h.ImportData).Methods("POST")
+	api.HandleFunc("/export/ofx", h.ExportOFX).Methods("GET")
+	api.HandleFunc("/export/qif", h.ExportQIF).Methods("GET")
+	api.HandleFunc("/import/ofx", h.ImportOFX).Methods("POST")
+	api.HandleFunc("/import/qif", h.ImportQIF).Methods("POST")
+
+	// Statement import routes (profiles before {id})
+	api.HandleFunc("/import/statement", h.StatementImportsHandler).Methods("GET", "POST")
+	api.HandleFunc("/import/statement/profiles", h.StatementProfilesHandler).Methods("GET", "POST")
+	api.HandleFunc("/import/statement/profiles/{id}", h.StatementProfileHandler).Methods("PUT", "DELETE")
+	api.HandleFunc("/import/statement/{id}", h.StatementImportHandler).Methods("GET", "PUT", "DELETE")
+	api.HandleFunc("/import/statement/{id}/commit", h.CommitStatementImport).Methods("POST")
 
 	return r
 }
@@ -0,0 +1,217 @@
+// Package rules applies user-defined categorisation rules to expenses and
+// incomes: conditions on the description, amount, payment method and
+// member, and actions that set the category, tags, member and account.
+package rules
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/tags"
+)
+
+// Target is the part of an expense or income rules read and change
+type Target struct {
+	Kind          string
+	Description   string
+	Amount        float64
+	PaymentMethod string
+	Category      string
+	Tags          []string
+	AddedBy       string
+	AccountID     string
+}
+
+// FromExpense is the target of an expense
+func FromExpense(e models.Expense) Target {
+	return Target{models.KindExpense, e.Desc, e.Amount, e.PaymentMethod, e.Category, e.Tags, e.AddedBy, e.AccountID}
+}
+
+// FromIncome is the target of an income
+func FromIncome(i models.Income) Target {
+	return Target{models.KindIncome, i.Source, i.Amount, i.PaymentMethod, i.Category, i.Tags, i.AddedBy, i.AccountID}
+}
+
+// Engine holds the enabled rules in the order they run
+type Engine struct {
+	rules []compiled
+}
+
+type compiled struct {
+	models.Rule
+	re *regexp.Regexp
+}
+
+// New prepares rules to run. Disabled rules and rules whose regex does not
+// compile are left out; ties in priority run in creation order.
+func New(list []models.Rule) *Engine {
+	e := &Engine{}
+	for _, r := range list {
+		if r.Disabled {
+			continue
+		}
+		c := compiled{Rule: r}
+		if r.DescriptionRegex != "" {
+			re, err := regexp.Compile("(?i)" + r.DescriptionRegex)
+			if err != nil {
+				continue
+			}
+			c.re = re
+		}
+		e.rules = append(e.rules, c)
+	}
+	sort.SliceStable(e.rules, func(i, j int) bool {
+		if e.rules[i].Priority != e.rules[j].Priority {
+			return e.rules[i].Priority < e.rules[j].Priority
+		}
+		return e.rules[i].CreatedAt < e.rules[j].CreatedAt
+	})
+	return e
+}
+
+// match reports whether every condition r sets holds for t
+func (r compiled) match(t Target) bool {
+	switch {
+	case r.Kind != "" && r.Kind != t.Kind:
+		return false
+	case r.DescriptionContains != "" && !strings.Contains(strings.ToLower(t.Description), strings.ToLower(r.DescriptionContains)):
+		return false
+	case r.re != nil && !r.re.MatchString(t.Description):
+		return false
+	case r.MinAmount != nil && t.Amount < *r.MinAmount:
+		return false
+	case r.MaxAmount != nil && t.Amount > *r.MaxAmount:
+		return false
+	case r.PaymentMethod != "" && !strings.EqualFold(r.PaymentMethod, t.PaymentMethod):
+		return false
+	case r.Member != "" && !strings.EqualFold(r.Member, t.AddedBy):
+		return false
+	}
+	return true
+}
+
+// Apply runs the rules on t, returning the changed target and the IDs of
+// the rules that matched. Conditions are checked against t as given; with
+// keep, the category, member and account t already has are left alone.
+func (e *Engine) Apply(t Target, keep bool) (Target, []string) {
+	out := t
+	out.Tags = append([]string(nil), t.Tags...)
+	var matched []string
+	category, member, account := keep && t.Category != "", keep && t.AddedBy != "", keep && t.AccountID != ""
+	for _, r := range e.rules {
+		if !r.match(t) {
+			continue
+		}
+		matched = append(matched, r.ID)
+		if r.SetCategory != "" && !category {
+			out.Category, category = r.SetCategory, true
+		}
+		if r.SetMember != "" && !member {
+			out.AddedBy, member = r.SetMember, true
+		}
+		if r.SetAccountID != "" && !account {
+			out.AccountID, account = r.SetAccountID, true
+		}
+		out.Tags = tags.Add(out.Tags, r.AddTags...)
+		if r.Stop {
+			break
+		}
+	}
+	return out, matched
+}
+
+// Expense runs the rules on exp, reporting whether any matched
+func (e *Engine) Expense(exp *models.Expense, keep bool) bool {
+	t, matched := e.Apply(FromExpense(*exp), keep)
+	exp.Category, exp.Tags, exp.AddedBy, exp.AccountID = t.Category, t.Tags, t.AddedBy, t.AccountID
+	return len(matched) > 0
+}
+
+// Income runs the rules on inc, reporting whether any matched
+func (e *Engine) Income(inc *models.Income, keep bool) bool {
+	t, matched := e.Apply(FromIncome(*inc), keep)
+	inc.Category, inc.Tags, inc.AddedBy, inc.AccountID = t.Category, t.Tags, t.AddedBy, t.AccountID
+	return len(matched) > 0
+}
+
+// Row runs the rules on a statement row. The import's member is matched
+// against; the member and account are only set on the row when a rule
+// changes them.
+func (e *Engine) Row(row *models.StatementRow, addedBy, accountID string) bool {
+	t, matched := e.Apply(Target{
+		Kind: row.Kind, Description: row.Description, Amount: row.Amount,
+		PaymentMethod: row.PaymentMethod, Category: row.Category, Tags: row.Tags,
+		AddedBy: addedBy, AccountID: accountID,
+	}, false)
+	row.Category, row.Tags = t.Category, t.Tags
+	if t.AddedBy != addedBy {
+		row.AddedBy = t.AddedBy
+	}
+	if t.AccountID != accountID {
+		row.AccountID = t.AccountID
+	}
+	return len(matched) > 0
+}
+
+// Change is what re-applying the rules would do to one recorded expense or
+// income
+type Change struct {
+	Kind        string   `json:"kind"`
+	ID          string   `json:"id"`
+	Date        string   `json:"date"`
+	Description string   `json:"description"`
+	Amount      float64  `json:"amount"`
+	Rules       []string `json:"rules"` // IDs of the rules that matched
+	Before      Fields   `json:"before"`
+	After       Fields   `json:"after"`
+}
+
+// Fields are the values rules set
+type Fields struct {
+	Category  string   `json:"category"`
+	Tags      []string `json:"tags"`
+	AddedBy   string   `json:"addedBy"`
+	AccountID string   `json:"accountId"`
+}
+
+func fields(t Target) Fields {
+	list := t.Tags
+	if list == nil {
+		list = []string{}
+	}
+	return Fields{t.Category, list, t.AddedBy, t.AccountID}
+}
+
+// Changes lists the records the rules would change
+func (e *Engine) Changes(expenses []models.Expense, incomes []models.Income) []Change {
+	changes := []Change{}
+	check := func(id, date string, t Target) {
+		after, matched := e.Apply(t, false)
+		before, now := fields(t), fields(after)
+		if len(matched) == 0 || equal(before, now) {
+			return
+		}
+		changes = append(changes, Change{t.Kind, id, date, t.Description, t.Amount, matched, before, now})
+	}
+	for _, exp := range expenses {
+		check(exp.ID, exp.Date, FromExpense(exp))
+	}
+	for _, inc := range incomes {
+		check(inc.ID, inc.Date, FromIncome(inc))
+	}
+	return changes
+}
+
+func equal(a, b Fields) bool {
+	if a.Category != b.Category || a.AddedBy != b.AddedBy || a.AccountID != b.AccountID || len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for i := range a.Tags {
+		if a.Tags[i] != b.Tags[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+// Package sharing works out what family members owe each other for shared
+// expenses: each member's share, the balances left after settlements, and
+// a plan of the fewest payments that squares them.
+package sharing
+
+import (
+	"math"
+	"sort"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+// Payer is the member who paid exp
+func Payer(exp models.Expense) string {
+	if exp.PaidBy != "" {
+		return exp.PaidBy
+	}
+	return exp.AddedBy
+}
+
+// Shares divides a shared expense among its members, to the paisa. What
+// rounding leaves over goes to the first members listed. An expense that
+// is not shared is its payer's alone.
+func Shares(exp models.Expense) map[string]float64 {
+	if len(exp.SharedAmong) == 0 {
+		return map[string]float64{Payer(exp): exp.Amount}
+	}
+
+	total := paise(exp.Amount)
+	parts := make([]int64, len(exp.SharedAmong))
+	var assigned int64
+	for i, share := range exp.SharedAmong {
+		switch exp.ShareMode {
+		case models.SharePercent:
+			parts[i] = int64(math.Floor(float64(total) * share.Percent / 100))
+		case models.ShareExact:
+			parts[i] = paise(share.Amount)
+		default:
+			parts[i] = total / int64(len(parts))
+		}
+		assigned += parts[i]
+	}
+	for i := 0; assigned < total; i = (i + 1) % len(parts) {
+		parts[i]++
+		assigned++
+	}
+
+	shares := map[string]float64{}
+	for i, share := range exp.SharedAmong {
+		shares[share.Member] += rupees(parts[i])
+	}
+	return shares
+}
+
+// Balance is where one member stands. Net above 0 is owed to the member,
+// below 0 is owed by them.
+type Balance struct {
+	Member   string  `json:"member"`
+	Paid     float64 `json:"paid"`     // Shared expenses they paid for
+	Share    float64 `json:"share"`    // Their part of those expenses
+	Sent     float64 `json:"sent"`     // Settlements they paid
+	Received float64 `json:"received"` // Settlements paid to them
+	Net      float64 `json:"net"`
+}
+
+// Balances totals the shared expenses and settlements for every member,
+// listed in order of members and then as first met
+func Balances(members []string, expenses []models.Expense, settlements []models.Settlement) []Balance {
+	byMember := map[string]*Balance{}
+	var order []string
+	get := func(m string) *Balance {
+		b, ok := byMember[m]
+		if !ok {
+			b = &Balance{Member: m}
+			byMember[m] = b
+			order = append(order, m)
+		}
+		return b
+	}
+	for _, m := range members {
+		get(m)
+	}
+
+	for _, exp := range expenses {
+		if len(exp.SharedAmong) == 0 {
+			continue
+		}
+		get(Payer(exp)).Paid += exp.Amount
+		// Sorted so members first met here are listed the same way each time
+		shares := Shares(exp)
+		names := make([]string, 0, len(shares))
+		for m := range shares {
+			names = append(names, m)
+		}
+		sort.Strings(names)
+		for _, m := range names {
+			get(m).Share += shares[m]
+		}
+	}
+	for _, st := range settlements {
+		get(st.From).Sent += st.Amount
+		get(st.To).Received += st.Amount
+	}
+
+	balances := make([]Balance, 0, len(order))
+	for _, m := range order {
+		b := byMember[m]
+		b.Net = rupees(paise(b.Paid - b.Share + b.Sent - b.Received))
+		b.Paid, b.Share = calc.Round2(b.Paid), calc.Round2(b.Share)
+		b.Sent, b.Received = calc.Round2(b.Sent), calc.Round2(b.Received)
+		balances = append(balances, *b)
+	}
+	return balances
+}
+
+// Transfer is one payment of a settlement plan
+type Transfer struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Amount float64 `json:"amount"`
+}
+
+// Plan squares the balances with few payments: the member owing most pays
+// the one owed most, as much as either allows, until all are even. This
+// needs at most one payment fewer than there are members out of balance.
+func Plan(balances []Balance) []Transfer {
+	type party struct {
+		member string
+		amount int64
+	}
+	var owed, owing []party
+	for _, b := range balances {
+		switch n := paise(b.Net); {
+		case n > 0:
+			owed = append(owed, party{b.Member, n})
+		case n < 0:
+			owing = append(owing, party{b.Member, -n})
+		}
+	}
+	byAmount := func(p []party) {
+		sort.SliceStable(p, func(i, j int) bool { return p[i].amount > p[j].amount })
+	}
+
+	plan := []Transfer{}
+	for len(owed) > 0 && len(owing) > 0 {
+		byAmount(owed)
+		byAmount(owing)
+		pay := owing[0].amount
+		if owed[0].amount < pay {
+			pay = owed[0].amount
+		}
+		plan = append(plan, Transfer{From: owing[0].member, To: owed[0].member, Amount: rupees(pay)})
+		owed[0].amount -= pay
+		owing[0].amount -= pay
+		if owed[0].amount == 0 {
+			owed = owed[1:]
+		}
+		if owing[0].amount == 0 {
+			owing = owing[1:]
+		}
+	}
+	return plan
+}
+
+func paise(f float64) int64 {
+	return int64(math.Round(f * 100))
+}
+
+func rupees(p int64) float64 {
+	return float64(p) / 100
+}
@@ -0,0 +1,241 @@
+// Package statements reads bank statement exports (CSV or XLSX) into
+// candidate transactions using column mapping profiles. Built-in presets
+// cover the usual HDFC, ICICI, SBI and Axis layouts; users save their own
+// profiles for anything else.
+package statements
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"finance-tracker/internal/calc"
+	"finance-tracker/internal/models"
+)
+
+// Default categories of imported rows, until someone reviews them
+const (
+	DefaultExpenseCategory = "Other"
+	DefaultIncomeCategory  = "Other"
+)
+
+// Presets are profiles for common bank exports, keyed by their ID
+var Presets = []models.StatementProfile{
+	{
+		ID: "hdfc", Name: "HDFC Bank",
+		DateColumn: "Date", DescriptionColumn: "Narration", ReferenceColumn: "Chq./Ref.No.",
+		DebitColumn: "Withdrawal Amt.", CreditColumn: "Deposit Amt.", BalanceColumn: "Closing Balance",
+	},
+	{
+		ID: "icici", Name: "ICICI Bank",
+		DateColumn: "Transaction Date", DescriptionColumn: "Transaction Remarks", ReferenceColumn: "Cheque Number",
+		DebitColumn: "Withdrawal Amount (INR )", CreditColumn: "Deposit Amount (INR )", BalanceColumn: "Balance (INR )",
+	},
+	{
+		ID: "sbi", Name: "State Bank of India",
+		DateColumn: "Txn Date", DescriptionColumn: "Description", ReferenceColumn: "Ref No./Cheque No.",
+		DebitColumn: "Debit", CreditColumn: "Credit", BalanceColumn: "Balance",
+	},
+	{
+		ID: "axis", Name: "Axis Bank",
+		DateColumn: "Tran Date", DescriptionColumn: "PARTICULARS", ReferenceColumn: "CHQNO",
+		DebitColumn: "DR", CreditColumn: "CR", BalanceColumn: "BAL",
+	},
+}
+
+// Preset returns the built-in profile with id
+func Preset(id string) (models.StatementProfile, bool) {
+	for _, p := range Presets {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return models.StatementProfile{}, false
+}
+
+// columns are the positions of a profile's columns in one file; -1 when
+// the profile does not use the column
+type columns struct {
+	date, desc, debit, credit, amount, kind, ref, balance int
+}
+
+// locate finds the header row of t for p: the first row that has every
+// column p names. Bank exports put account details above it.
+func locate(t Table, p models.StatementProfile) (int, columns, bool) {
+	for i, row := range t.Rows {
+		index := map[string]int{}
+		for j, cell := range row {
+			if key := normalize(cell); key != "" {
+				if _, seen := index[key]; !seen {
+					index[key] = j
+				}
+			}
+		}
+		col := func(name string) (int, bool) {
+			if name == "" {
+				return -1, true
+			}
+			j, ok := index[normalize(name)]
+			return j, ok
+		}
+		var c columns
+		found := true
+		for _, f := range []struct {
+			dst  *int
+			name string
+		}{
+			{&c.date, p.DateColumn}, {&c.desc, p.DescriptionColumn},
+			{&c.debit, p.DebitColumn}, {&c.credit, p.CreditColumn},
+			{&c.amount, p.AmountColumn}, {&c.kind, p.TypeColumn},
+			{&c.ref, p.ReferenceColumn}, {&c.balance, p.BalanceColumn},
+		} {
+			j, ok := col(f.name)
+			if !ok {
+				found = false
+				break
+			}
+			*f.dst = j
+		}
+		if found {
+			return i, c, true
+		}
+	}
+	return 0, columns{}, false
+}
+
+// Detect returns the first profile whose columns all appear in t
+func Detect(t Table, profiles []models.StatementProfile) (models.StatementProfile, bool) {
+	for _, p := range profiles {
+		if _, _, ok := locate(t, p); ok {
+			return p, true
+		}
+	}
+	return models.StatementProfile{}, false
+}
+
+// Parse maps the rows of t below its header onto statement rows. Rows
+// without a date or amount (blank lines, opening balance and totals) are
+// left out; rows that cannot be read are kept with an Error and skipped.
+func Parse(t Table, p models.StatementProfile) ([]models.StatementRow, error) {
+	header, c, ok := locate(t, p)
+	if !ok {
+		return nil, fmt.Errorf("no header row with the columns of profile %q", p.Name)
+	}
+
+	rows := []models.StatementRow{}
+	for i := header + 1; i < len(t.Rows); i++ {
+		cells := t.Rows[i]
+		cell := func(j int) string {
+			if j < 0 || j >= len(cells) {
+				return ""
+			}
+			return strings.TrimSpace(cells[j])
+		}
+		if cell(c.date) == "" {
+			continue
+		}
+
+		row := models.StatementRow{
+			Line:        t.Line[i],
+			Description: strings.Join(strings.Fields(cell(c.desc)), " "),
+			Reference:   cell(c.ref),
+		}
+		amount, err := rowAmount(cell, c)
+		if errors.Is(err, errNoAmount) {
+			continue
+		}
+		date, dateErr := ParseDate(cell(c.date), p.DateFormat)
+		switch {
+		case dateErr != nil:
+			row.Error = dateErr.Error()
+		case err != nil:
+			row.Error = err.Error()
+		}
+		if row.Error != "" {
+			row.Kind = models.KindExpense
+			row.Skip = true
+			rows = append(rows, row)
+			continue
+		}
+
+		row.Date = date.Format(dateLayout)
+		row.Kind = models.KindIncome
+		row.Category = DefaultIncomeCategory
+		if amount < 0 {
+			row.Kind = models.KindExpense
+			row.Category = DefaultExpenseCategory
+		}
+		row.Amount = calc.Round2(abs(amount))
+		row.PaymentMethod = PaymentMethod(row.Description)
+		if b, ok, err := ParseAmount(cell(c.balance)); ok && err == nil {
+			row.Balance = &b
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+var errNoAmount = errors.New("no amount")
+
+// rowAmount is a row's amount, negative for money out
+func rowAmount(cell func(int) string, c columns) (float64, error) {
+	if c.amount >= 0 {
+		v, ok, err := ParseAmount(cell(c.amount))
+		if err != nil {
+			return 0, err
+		}
+		if !ok || v == 0 {
+			return 0, errNoAmount
+		}
+		switch kind := strings.ToLower(cell(c.kind)); {
+		case strings.HasPrefix(kind, "d"), strings.HasPrefix(kind, "w"):
+			return -abs(v), nil
+		case strings.HasPrefix(kind, "c"):
+			return abs(v), nil
+		}
+		return v, nil
+	}
+
+	debit, hasDebit, err := ParseAmount(cell(c.debit))
+	if err != nil {
+		return 0, err
+	}
+	credit, hasCredit, err := ParseAmount(cell(c.credit))
+	if err != nil {
+		return 0, err
+	}
+	hasDebit = hasDebit && debit != 0
+	hasCredit = hasCredit && credit != 0
+	switch {
+	case hasDebit && hasCredit:
+		return 0, errors.New("both debit and credit are filled in")
+	case hasDebit:
+		return -abs(debit), nil
+	case hasCredit:
+		return abs(credit), nil
+	}
+	return 0, errNoAmount
+}
+
+// PaymentMethod guesses how money moved from a statement narration
+func PaymentMethod(desc string) string {
+	d := strings.ToUpper(desc)
+	switch {
+	case strings.Contains(d, "UPI"):
+		return "UPI"
+	case strings.Contains(d, "NEFT"), strings.Contains(d, "IMPS"), strings.Contains(d, "RTGS"):
+		return "Bank Transfer"
+	case strings.Contains(d, "ATM"), strings.Contains(d, "CASH"):
+		return "Cash"
+	case strings.Contains(d, "POS"), strings.Contains(d, "CARD"):
+		return "Card"
+	}
+	return "Online"
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
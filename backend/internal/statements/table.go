@@ -0,0 +1,252 @@
+package statements
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on a statement, so a crafted upload cannot exhaust memory. Excel
+// itself stops at column XFD and 1,048,576 rows; no bank statement comes
+// near either.
+const (
+	maxRows     = 100000
+	maxColumns  = 16384    // XFD
+	maxPartSize = 64 << 20 // an XLSX part, uncompressed
+)
+
+var errTooManyRows = fmt.Errorf("statement has more than %d rows", maxRows)
+
+// Table is a statement read into rows of cells. Line holds each row's line
+// (CSV) or row (XLSX) number in the file for messages.
+type Table struct {
+	Rows [][]string
+	Line []int
+}
+
+// Read reads a CSV or XLSX statement. The format is taken from the file
+// name, or from the content when the name does not say.
+func Read(name string, data []byte, delimiter string) (Table, error) {
+	ext := strings.ToLower(path.Ext(name))
+	switch {
+	case ext == ".xls":
+		return Table{}, errors.New("old .xls files are not supported; save the statement as CSV or XLSX")
+	case ext == ".xlsx", ext == "" && bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return readXLSX(data)
+	default:
+		return readCSV(data, delimiter)
+	}
+}
+
+func readCSV(data []byte, delimiter string) (Table, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		// Older exports are often Windows-1252; keep the ASCII and drop the rest
+		data = bytes.ToValidUTF8(data, nil)
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+	if delimiter != "" {
+		r.Comma, _ = utf8.DecodeRuneInString(delimiter)
+	}
+
+	var t Table
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return t, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(t.Rows) == maxRows {
+			return Table{}, errTooManyRows
+		}
+		line, _ := r.FieldPos(0)
+		t.Rows = append(t.Rows, rec)
+		t.Line = append(t.Line, line)
+	}
+}
+
+// ----- XLSX -----
+
+// An XLSX file is a zip of XML parts. Only the cell values of the first
+// worksheet are needed, so the parts are read directly.
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRels struct {
+	Rels []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (x xlsxText) String() string {
+	if len(x.Runs) == 0 {
+		return x.T
+	}
+	var b strings.Builder
+	for _, r := range x.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) (Table, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Table{}, fmt.Errorf("invalid XLSX: %w", err)
+	}
+	parts := map[string]*zip.File{}
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+	decode := func(name string, v interface{}) error {
+		f, ok := parts[name]
+		if !ok {
+			return fmt.Errorf("invalid XLSX: missing %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		lr := &io.LimitedReader{R: rc, N: maxPartSize + 1}
+		err = xml.NewDecoder(lr).Decode(v)
+		if lr.N <= 0 {
+			return fmt.Errorf("%s is larger than %d MB", name, maxPartSize>>20)
+		}
+		return err
+	}
+
+	sheetPath := firstSheet(decode)
+	var shared []string
+	if _, ok := parts["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decode("xl/sharedStrings.xml", &sst); err != nil {
+			return Table{}, fmt.Errorf("invalid XLSX shared strings: %w", err)
+		}
+		for _, it := range sst.Items {
+			shared = append(shared, it.String())
+		}
+	}
+	var sheet xlsxSheet
+	if err := decode(sheetPath, &sheet); err != nil {
+		return Table{}, fmt.Errorf("invalid XLSX worksheet: %w", err)
+	}
+
+	if len(sheet.Rows) > maxRows {
+		return Table{}, errTooManyRows
+	}
+	var t Table
+	for i, row := range sheet.Rows {
+		var cells []string
+		for _, c := range row.Cells {
+			col := len(cells)
+			if c.Ref != "" {
+				var ok bool
+				if col, ok = columnIndex(c.Ref); !ok {
+					return Table{}, fmt.Errorf("invalid XLSX: cell %q is not within columns A to XFD", c.Ref)
+				}
+			}
+			if col >= maxColumns {
+				return Table{}, fmt.Errorf("invalid XLSX: row %d has more than %d columns", i+1, maxColumns)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(c.Value); err == nil && n >= 0 && n < len(shared) {
+					cells[col] = shared[n]
+				}
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			default:
+				cells[col] = c.Value
+			}
+		}
+		line := row.R
+		if line == 0 {
+			line = i + 1
+		}
+		t.Rows = append(t.Rows, cells)
+		t.Line = append(t.Line, line)
+	}
+	return t, nil
+}
+
+// firstSheet finds the part holding the workbook's first worksheet
+func firstSheet(decode func(string, interface{}) error) string {
+	var wb xlsxWorkbook
+	var rels xlsxRels
+	if decode("xl/workbook.xml", &wb) != nil || len(wb.Sheets) == 0 ||
+		decode("xl/_rels/workbook.xml.rels", &rels) != nil {
+		return "xl/worksheets/sheet1.xml"
+	}
+	for _, rel := range rels.Rels {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return "xl/worksheets/sheet1.xml"
+}
+
+// columnIndex converts a cell reference such as "C12" to its zero-based
+// column. It reports ok false when the reference has no column letters or
+// names a column beyond XFD.
+func columnIndex(ref string) (col int, ok bool) {
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		if n = n*26 + int(r-'A') + 1; n > maxColumns {
+			return 0, false
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return n - 1, true
+}
@@ -0,0 +1,91 @@
+package statements
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// xlsx builds a workbook whose only part is a worksheet holding rows
+func xlsx(t *testing.T, rows string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`<worksheet><sheetData>` + rows + `</sheetData></worksheet>`))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want int
+		ok   bool
+	}{
+		{"A1", 0, true},
+		{"C12", 2, true},
+		{"Z3", 25, true},
+		{"AA3", 26, true},
+		{"XFD1", 16383, true},
+		{"XFE1", 0, false},
+		{"XFDXFD1", 0, false},
+		{"12", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := columnIndex(tt.ref)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d, %v", tt.ref, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := xlsx(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>Date</t></is></c><c r="C1" t="inlineStr"><is><t>Amount</t></is></c></row>`+
+		`<row r="2"><c r="A2"><v>45385</v></c><c r="C2"><v>500</v></c></row>`)
+	got, err := Read("hdfc.xlsx", data, "")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := Table{Rows: [][]string{{"Date", "", "Amount"}, {"45385", "", "500"}}, Line: []int{1, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    func(t *testing.T) []byte
+		wantErr string
+	}{
+		{"column beyond XFD", "a.xlsx", func(t *testing.T) []byte {
+			return xlsx(t, `<row r="1"><c r="XFDXFD1"><v>1</v></c></row>`)
+		}, "not within columns A to XFD"},
+		{"reference without a column", "a.xlsx", func(t *testing.T) []byte {
+			return xlsx(t, `<row r="1"><c r="1"><v>1</v></c></row>`)
+		}, "not within columns A to XFD"},
+		{"too many XLSX rows", "a.xlsx", func(t *testing.T) []byte {
+			return xlsx(t, strings.Repeat(`<row/>`, maxRows+1))
+		}, "more than 100000 rows"},
+		{"too many CSV rows", "a.csv", func(t *testing.T) []byte {
+			return []byte(strings.Repeat("1,2\n", maxRows+1))
+		}, "more than 100000 rows"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(tt.file, tt.data(t), "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Read() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package statements
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// ParseAmount reads an amount as banks print it: "1,23,456.78", "₹ 500",
+// "Rs.1,200.00", "(250.00)" or "1,000.00 Dr". Debits marked Dr and amounts
+// in brackets come back negative. A blank cell (or "-") reports ok false.
+func ParseAmount(s string) (amount float64, ok bool, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return 0, false, nil
+	}
+	orig := s
+	sign := 1.0
+	upper := strings.ToUpper(s)
+	switch {
+	case strings.HasSuffix(upper, "DR"), strings.HasSuffix(upper, "DR."):
+		sign = -1
+		s = strings.TrimRight(s[:strings.LastIndex(upper, "DR")], " .")
+	case strings.HasSuffix(upper, "CR"), strings.HasSuffix(upper, "CR."):
+		s = strings.TrimRight(s[:strings.LastIndex(upper, "CR")], " .")
+	}
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		sign = -sign
+		s = s[1 : len(s)-1]
+	}
+	for _, prefix := range []string{"₹", "INR", "Rs.", "Rs", "RS.", "RS"} {
+		s = strings.TrimPrefix(strings.TrimSpace(s), prefix)
+	}
+	s = strings.NewReplacer(",", "", " ", "", "\u00a0", "").Replace(s)
+	if strings.HasPrefix(s, "-") {
+		sign = -sign
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, false, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false, fmt.Errorf("%q is not an amount", orig)
+	}
+	return sign * v, true, nil
+}
+
+// dateLayouts are the formats tried when a profile has no date format.
+// Indian statements put the day first, so 03/04/2024 is 3 April.
+var dateLayouts = []string{
+	"02/01/2006", "2/1/2006", "02/01/06", "2/1/06",
+	"02-01-2006", "2-1-2006", "02-01-06",
+	"02.01.2006", "02.01.06",
+	"02-Jan-2006", "2-Jan-2006", "02-Jan-06", "2-Jan-06",
+	"02 Jan 2006", "2 Jan 2006", "02 Jan 06",
+	"02/Jan/2006", "02-January-2006", "2 January 2006",
+	"Jan 2, 2006", "2006-01-02", "2006/01/02",
+}
+
+// excelEpoch is day 0 of Excel's date serials
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// ParseDate reads a statement date with format (see Layout), or with the
+// common Indian formats when format is empty. Times after the date and
+// Excel date serials are accepted.
+func ParseDate(s, format string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("date is empty")
+	}
+	layouts := dateLayouts
+	if format != "" {
+		layouts = []string{Layout(format)}
+	}
+	candidates := []string{s}
+	if i := strings.IndexAny(s, "T "); i > 0 {
+		// "03/04/2024 10:15:00", "2024-04-03T10:15:00"; "3 Apr 2024" is tried whole first
+		candidates = append(candidates, s[:i])
+	}
+	for _, c := range candidates {
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, c); err == nil {
+				return t, nil
+			}
+		}
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil && n > 20000 && n < 80000 {
+		return excelEpoch.AddDate(0, 0, int(n)), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date", s)
+}
+
+var dateTokens = regexp.MustCompile(`(?i)yyyy|yy|mmmm|mmm|mon|mm|m|dd|d`)
+
+// Layout converts a date format written as users know it ("dd/mm/yyyy",
+// "dd-mon-yy", "d mmm yyyy") to a Go time layout
+func Layout(format string) string {
+	return dateTokens.ReplaceAllStringFunc(format, func(tok string) string {
+		switch strings.ToLower(tok) {
+		case "yyyy":
+			return "2006"
+		case "yy":
+			return "06"
+		case "mmmm":
+			return "January"
+		case "mmm", "mon":
+			return "Jan"
+		case "mm":
+			return "01"
+		case "m":
+			return "1"
+		case "dd":
+			return "02"
+		default:
+			return "2"
+		}
+	})
+}
+
+// normalize reduces a column heading to lower-case letters and digits, so
+// "Withdrawal Amt." matches "withdrawal amt"
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package statements
+
+import (
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		ok      bool
+		wantErr bool
+	}{
+		{"1,23,456.78", 123456.78, true, false},
+		{"₹ 500", 500, true, false},
+		{"₹1,200", 1200, true, false},
+		{"Rs.1,200.00", 1200, true, false},
+		{"Rs 75", 75, true, false},
+		{"INR 2,50,000", 250000, true, false},
+		{"1,000.00 Dr", -1000, true, false},
+		{"1,000.00 DR.", -1000, true, false},
+		{"1,000.00 Cr", 1000, true, false},
+		{"(250.00)", -250, true, false},
+		{"-99.50", -99.5, true, false},
+		{"12 345", 12345, true, false},
+		{"", 0, false, false},
+		{"-", 0, false, false},
+		{"Rs.", 0, false, false},
+		{"N/A", 0, false, true},
+		{"NaN", 0, false, true},
+	}
+	for _, tt := range tests {
+		got, ok, err := ParseAmount(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAmount(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseAmount(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in, format string
+		want       string
+		wantErr    bool
+	}{
+		{"03/04/2024", "", "2024-04-03", false}, // Day first
+		{"3/4/2024", "", "2024-04-03", false},
+		{"03/04/24", "", "2024-04-03", false},
+		{"03-04-2024", "", "2024-04-03", false},
+		{"03.04.24", "", "2024-04-03", false},
+		{"03-Apr-2024", "", "2024-04-03", false},
+		{"3-Apr-24", "", "2024-04-03", false},
+		{"03 Apr 2024", "", "2024-04-03", false},
+		{"3 April 2024", "", "2024-04-03", false},
+		{"2024-04-03", "", "2024-04-03", false},
+		{"03/04/2024 10:15:00", "", "2024-04-03", false},
+		{"2024-04-03T10:15:00", "", "2024-04-03", false},
+		{"45385", "", "2024-04-03", false}, // Excel serial
+		{"04/03/2024", "mm/dd/yyyy", "2024-04-03", false},
+		{"03-apr-24", "dd-mon-yy", "2024-04-03", false},
+		{"31/02/2024", "", "", true},
+		{"", "", "", true},
+		{"yesterday", "", "", true},
+		{"03/04/2024", "dd-mon-yyyy", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDate(%q, %q) error = %v, wantErr %v", tt.in, tt.format, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Format(dateLayout) != tt.want {
+			t.Errorf("ParseDate(%q, %q) = %s, want %s", tt.in, tt.format, got.Format(dateLayout), tt.want)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct{ format, want string }{
+		{"dd/mm/yyyy", "02/01/2006"},
+		{"DD-MM-YY", "02-01-06"},
+		{"dd-mon-yyyy", "02-Jan-2006"},
+		{"d mmm yyyy", "2 Jan 2006"},
+		{"dd mmmm yyyy", "02 January 2006"},
+		{"d/m/yy", "2/1/06"},
+		{"yyyy-mm-dd", "2006-01-02"},
+	}
+	for _, tt := range tests {
+		if got := Layout(tt.format); got != tt.want {
+			t.Errorf("Layout(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
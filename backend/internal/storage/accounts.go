@@ -0,0 +1,99 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// accountDocs stores accounts (see docKind)
+var accountDocs = docKind[models.Account]{
+	noun:       "account",
+	collection: accountsCollection,
+	table:      "accounts",
+	slice:      func(ds *DataStore) *[]models.Account { return &ds.accounts },
+	id:         func(a models.Account) string { return a.ID },
+	validate:   (*models.Account).Validate,
+}
+
+// ----- DataStore -----
+
+// GetAccounts returns all accounts
+func (ds *DataStore) GetAccounts() []models.Account { return accountDocs.dsGet(ds) }
+
+// AddAccount adds a new account
+func (ds *DataStore) AddAccount(a models.Account) error { return accountDocs.dsAdd(ds, a) }
+
+// UpdateAccount updates an existing account
+func (ds *DataStore) UpdateAccount(id string, updated models.Account) error {
+	return accountDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteAccount removes a account
+func (ds *DataStore) DeleteAccount(id string) error { return accountDocs.dsDelete(ds, id) }
+
+// SaveAccounts writes accounts to file
+func (ds *DataStore) SaveAccounts() error { return accountDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetAccounts returns all accounts
+func (s *SQLiteStore) GetAccounts() []models.Account { return accountDocs.sqlGet(s) }
+
+// AddAccount adds a new account
+func (s *SQLiteStore) AddAccount(a models.Account) error { return accountDocs.sqlAdd(s, a) }
+
+// UpdateAccount updates an existing account
+func (s *SQLiteStore) UpdateAccount(id string, updated models.Account) error {
+	return accountDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteAccount removes a account
+func (s *SQLiteStore) DeleteAccount(id string) error { return accountDocs.sqlDelete(s, id) }
+
+// SaveAccounts is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveAccounts() error { return nil }
+
+// transferDocs stores transfers (see docKind)
+var transferDocs = docKind[models.Transfer]{
+	noun:       "transfer",
+	collection: transfersCollection,
+	table:      "transfers",
+	slice:      func(ds *DataStore) *[]models.Transfer { return &ds.transfers },
+	id:         func(t models.Transfer) string { return t.ID },
+	validate:   (*models.Transfer).Validate,
+}
+
+// ----- DataStore -----
+
+// GetTransfers returns all transfers
+func (ds *DataStore) GetTransfers() []models.Transfer { return transferDocs.dsGet(ds) }
+
+// AddTransfer adds a new transfer
+func (ds *DataStore) AddTransfer(t models.Transfer) error { return transferDocs.dsAdd(ds, t) }
+
+// UpdateTransfer updates an existing transfer
+func (ds *DataStore) UpdateTransfer(id string, updated models.Transfer) error {
+	return transferDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteTransfer removes a transfer
+func (ds *DataStore) DeleteTransfer(id string) error { return transferDocs.dsDelete(ds, id) }
+
+// SaveTransfers writes transfers to file
+func (ds *DataStore) SaveTransfers() error { return transferDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetTransfers returns all transfers
+func (s *SQLiteStore) GetTransfers() []models.Transfer { return transferDocs.sqlGet(s) }
+
+// AddTransfer adds a new transfer
+func (s *SQLiteStore) AddTransfer(t models.Transfer) error { return transferDocs.sqlAdd(s, t) }
+
+// UpdateTransfer updates an existing transfer
+func (s *SQLiteStore) UpdateTransfer(id string, updated models.Transfer) error {
+	return transferDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteTransfer removes a transfer
+func (s *SQLiteStore) DeleteTransfer(id string) error { return transferDocs.sqlDelete(s, id) }
+
+// SaveTransfers is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveTransfers() error { return nil }
@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// budgetDocs stores budgets (see docKind)
+var budgetDocs = docKind[models.Budget]{
+	noun:       "budget",
+	collection: budgetsCollection,
+	table:      "budgets",
+	slice:      func(ds *DataStore) *[]models.Budget { return &ds.budgets },
+	id:         func(b models.Budget) string { return b.ID },
+	validate:   (*models.Budget).Validate,
+}
+
+// ----- DataStore -----
+
+// GetBudgets returns all budgets
+func (ds *DataStore) GetBudgets() []models.Budget { return budgetDocs.dsGet(ds) }
+
+// AddBudget adds a new budget
+func (ds *DataStore) AddBudget(b models.Budget) error { return budgetDocs.dsAdd(ds, b) }
+
+// UpdateBudget updates an existing budget
+func (ds *DataStore) UpdateBudget(id string, updated models.Budget) error {
+	return budgetDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteBudget removes a budget
+func (ds *DataStore) DeleteBudget(id string) error { return budgetDocs.dsDelete(ds, id) }
+
+// SaveBudgets writes budgets to file
+func (ds *DataStore) SaveBudgets() error { return budgetDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetBudgets returns all budgets
+func (s *SQLiteStore) GetBudgets() []models.Budget { return budgetDocs.sqlGet(s) }
+
+// AddBudget adds a new budget
+func (s *SQLiteStore) AddBudget(b models.Budget) error { return budgetDocs.sqlAdd(s, b) }
+
+// UpdateBudget updates an existing budget
+func (s *SQLiteStore) UpdateBudget(id string, updated models.Budget) error {
+	return budgetDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteBudget removes a budget
+func (s *SQLiteStore) DeleteBudget(id string) error { return budgetDocs.sqlDelete(s, id) }
+
+// SaveBudgets is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveBudgets() error { return nil }
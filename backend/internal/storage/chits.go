@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// chitDocs stores chits (see docKind)
+var chitDocs = docKind[models.Chit]{
+	noun:       "chit",
+	collection: chitsCollection,
+	table:      "chits",
+	slice:      func(ds *DataStore) *[]models.Chit { return &ds.chits },
+	id:         func(c models.Chit) string { return c.ID },
+	validate:   (*models.Chit).Validate,
+}
+
+// ----- DataStore -----
+
+// GetChits returns all chits
+func (ds *DataStore) GetChits() []models.Chit { return chitDocs.dsGet(ds) }
+
+// AddChit adds a new chit
+func (ds *DataStore) AddChit(c models.Chit) error { return chitDocs.dsAdd(ds, c) }
+
+// UpdateChit updates an existing chit
+func (ds *DataStore) UpdateChit(id string, updated models.Chit) error {
+	return chitDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteChit removes a chit
+func (ds *DataStore) DeleteChit(id string) error { return chitDocs.dsDelete(ds, id) }
+
+// SaveChits writes chits to file
+func (ds *DataStore) SaveChits() error { return chitDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetChits returns all chits
+func (s *SQLiteStore) GetChits() []models.Chit { return chitDocs.sqlGet(s) }
+
+// AddChit adds a new chit
+func (s *SQLiteStore) AddChit(c models.Chit) error { return chitDocs.sqlAdd(s, c) }
+
+// UpdateChit updates an existing chit
+func (s *SQLiteStore) UpdateChit(id string, updated models.Chit) error {
+	return chitDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteChit removes a chit
+func (s *SQLiteStore) DeleteChit(id string) error { return chitDocs.sqlDelete(s, id) }
+
+// SaveChits is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveChits() error { return nil }
@@ -11,138 +11,488 @@ import (
 	"finance-tracker/internal/models"
 )
 
-// DataStore manages all data and file operations
+// DataStore manages all data and file operations.
+// Every mutation is appended to a journal before it touches memory, and each
+// Save* call atomically rewrites one JSON file. The journal is cleared once
+// all collections are saved, so leftover entries on startup mean the previous
+// run stopped before its changes reached the data files.
 type DataStore struct {
-	mu          sync.RWMutex
-	dataDir     string
-	investments []models.Investment
-	incomes     []models.Income
-	expenses    []models.Expense
-	settings    models.Settings
+	mu                sync.RWMutex
+	dataDir           string
+	journal           *journal
+	dirty             map[string]bool // collections with journaled but unsaved changes
+	investments       []models.Investment
+	incomes           []models.Income
+	expenses          []models.Expense
+	settings          models.Settings
+	budgets           []models.Budget
+	recurring         []models.RecurringRule
+	prices            []models.PricePoint
+	chits             []models.Chit
+	loans             []models.Loan
+	accounts          []models.Account
+	transfers         []models.Transfer
+	statementProfiles []models.StatementProfile
+	statementImports  []models.StatementImport
+	merges            []models.Merge
+	rules             []models.Rule
+	settlements       []models.Settlement
+	users             []models.User
+	sessions          []models.Session
+	apiTokens         []models.APIToken
 }
 
-// NewDataStore creates and initializes the data store
-func NewDataStore(dataDir string) *DataStore {
+// Collection names, used as file stems and journal entities
+const (
+	investmentsCollection       = "investments"
+	incomesCollection           = "incomes"
+	expensesCollection          = "expenses"
+	settingsCollection          = "settings"
+	budgetsCollection           = "budgets"
+	recurringCollection         = "recurring"
+	pricesCollection            = "prices"
+	chitsCollection             = "chits"
+	loansCollection             = "loans"
+	accountsCollection          = "accounts"
+	transfersCollection         = "transfers"
+	statementProfilesCollection = "statementProfiles"
+	statementImportsCollection  = "statementImports"
+	mergesCollection            = "merges"
+	rulesCollection             = "rules"
+	settlementsCollection       = "settlements"
+	usersCollection             = "users"
+	sessionsCollection          = "sessions"
+	apiTokensCollection         = "apiTokens"
+	importCollection            = "import"
+)
+
+// collections lists every collection kept in its own JSON file
+var collections = []string{
+	investmentsCollection,
+	incomesCollection,
+	expensesCollection,
+	settingsCollection,
+	budgetsCollection,
+	recurringCollection,
+	pricesCollection,
+	chitsCollection,
+	loansCollection,
+	accountsCollection,
+	transfersCollection,
+	statementProfilesCollection,
+	statementImportsCollection,
+	mergesCollection,
+	rulesCollection,
+	settlementsCollection,
+	usersCollection,
+	sessionsCollection,
+	apiTokensCollection,
+}
+
+// collection returns a pointer to the in-memory value saved as <name>.json
+func (ds *DataStore) collection(name string) interface{} {
+	switch name {
+	case investmentsCollection:
+		return &ds.investments
+	case incomesCollection:
+		return &ds.incomes
+	case expensesCollection:
+		return &ds.expenses
+	case settingsCollection:
+		return &ds.settings
+	case budgetsCollection:
+		return &ds.budgets
+	case recurringCollection:
+		return &ds.recurring
+	case pricesCollection:
+		return &ds.prices
+	case chitsCollection:
+		return &ds.chits
+	case loansCollection:
+		return &ds.loans
+	case accountsCollection:
+		return &ds.accounts
+	case transfersCollection:
+		return &ds.transfers
+	case statementProfilesCollection:
+		return &ds.statementProfiles
+	case statementImportsCollection:
+		return &ds.statementImports
+	case mergesCollection:
+		return &ds.merges
+	case rulesCollection:
+		return &ds.rules
+	case settlementsCollection:
+		return &ds.settlements
+	case usersCollection:
+		return &ds.users
+	case sessionsCollection:
+		return &ds.sessions
+	case apiTokensCollection:
+		return &ds.apiTokens
+	default:
+		panic("storage: unknown collection " + name)
+	}
+}
+
+// NewDataStore creates and initializes the data store.
+// It refuses to start on a corrupt data file rather than starting empty.
+func NewDataStore(dataDir string) (*DataStore, error) {
 	ds := &DataStore{
-		dataDir: dataDir,
-		settings: models.Settings{
-			Categories:       []string{"Food", "Transport", "Utilities", "Shopping", "Entertainment", "Health", "EMI", "Household", "Other"},
-			InvestmentTypes:  []string{"Mutual Fund", "Stocks", "FD", "Gold", "PPF", "NPS", "Chit", "Other"},
-			IncomeCategories: []string{"Salary", "Business", "Rental", "Freelance", "Interest", "Dividend", "Other"},
-			PaymentMethods:   []string{"Online", "Cash", "Card", "UPI", "Bank Transfer"},
-			Members:          []string{"Ravi", "Akshata"},
-		},
+		dataDir:  dataDir,
+		dirty:    map[string]bool{},
+		settings: defaultSettings(),
+	}
+	if err := ds.load(); err != nil {
+		return nil, err
 	}
-	ds.load()
-	return ds
+	return ds, nil
 }
 
-// load reads data from JSON files into memory
-func (ds *DataStore) load() {
+// Close releases the journal file
+func (ds *DataStore) Close() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.journal.close()
+}
+
+// load reads data from JSON files into memory and replays the journal
+func (ds *DataStore) load() error {
 	if err := os.MkdirAll(ds.dataDir, 0755); err != nil {
-		log.Printf("Warning: Failed to create data directory: %v", err)
+		return fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	// Load investments
-	if data, err := os.ReadFile(filepath.Join(ds.dataDir, "investments.json")); err == nil {
-		if err := json.Unmarshal(data, &ds.investments); err != nil {
-			log.Printf("Warning: Failed to load investments: %v", err)
-			ds.investments = []models.Investment{}
+	for _, name := range collections {
+		if err := ds.readFile(name, ds.collection(name)); err != nil {
+			return err
 		}
-	} else if !os.IsNotExist(err) {
-		log.Printf("Warning: Error reading investments file: %v", err)
 	}
 
-	// Load incomes
-	if data, err := os.ReadFile(filepath.Join(ds.dataDir, "incomes.json")); err == nil {
-		if err := json.Unmarshal(data, &ds.incomes); err != nil {
-			log.Printf("Warning: Failed to load incomes: %v", err)
-			ds.incomes = []models.Income{}
-		}
-	} else if !os.IsNotExist(err) {
-		log.Printf("Warning: Error reading incomes file: %v", err)
+	j, entries, err := openJournal(ds.dataDir)
+	if err != nil {
+		return err
 	}
+	ds.journal = j
 
-	// Load expenses
-	if data, err := os.ReadFile(filepath.Join(ds.dataDir, "expenses.json")); err == nil {
-		if err := json.Unmarshal(data, &ds.expenses); err != nil {
-			log.Printf("Warning: Failed to load expenses: %v", err)
-			ds.expenses = []models.Expense{}
+	if len(entries) > 0 {
+		// Unclean shutdown: bring memory up to date, then checkpoint
+		log.Printf("Recovering %d journal entries from unclean shutdown", len(entries))
+		for _, entry := range entries {
+			if err := ds.apply(entry); err != nil {
+				j.close()
+				return fmt.Errorf("failed to replay journal entry %d: %w", entry.Seq, err)
+			}
+		}
+		for _, name := range collections {
+			ds.dirty[name] = true
+		}
+		if err := ds.checkpoint(); err != nil {
+			j.close()
+			return fmt.Errorf("failed to checkpoint recovered data: %w", err)
 		}
-	} else if !os.IsNotExist(err) {
-		log.Printf("Warning: Error reading expenses file: %v", err)
 	}
 
-	// Load settings
-	if data, err := os.ReadFile(filepath.Join(ds.dataDir, "settings.json")); err == nil {
-		if err := json.Unmarshal(data, &ds.settings); err != nil {
-			log.Printf("Warning: Failed to load settings: %v", err)
+	// Investments saved before transactions existed get an opening one
+	migrated := 0
+	for i := range ds.investments {
+		inv := &ds.investments[i]
+		if !inv.NeedsTransactions() {
+			continue
+		}
+		flat := *inv
+		if !inv.EnsureTransactions() {
+			log.Printf("Warning: Investment %s (%s) left without an opening transaction: date %q is not YYYY-MM-DD or DD/MM/YYYY", inv.ID, inv.Name, inv.Date)
+			continue
 		}
-	} else if !os.IsNotExist(err) {
-		log.Printf("Warning: Error reading settings file: %v", err)
+		if err := inv.Validate(); err != nil {
+			log.Printf("Warning: Investment %s (%s) left without an opening transaction: %v", inv.ID, inv.Name, err)
+			*inv = flat
+			continue
+		}
+		migrated++
 	}
+	if migrated > 0 {
+		if err := ds.persist(investmentsCollection, ds.investments); err != nil {
+			j.close()
+			return fmt.Errorf("failed to save migrated investments: %w", err)
+		}
+		log.Printf("Migrated %d investments to opening transactions", migrated)
+	}
+	return nil
 }
 
-// SaveInvestments writes investments to file
-func (ds *DataStore) SaveInvestments() error {
-	ds.mu.RLock()
-	defer ds.mu.RUnlock()
-	data, err := json.MarshalIndent(ds.investments, "", "  ")
+// readFile decodes <name>.json into v. A missing file is fine; an unreadable
+// or malformed one is an error, because starting empty would lose the data.
+func (ds *DataStore) readFile(name string, v interface{}) error {
+	path := filepath.Join(ds.dataDir, name+".json")
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to marshal investments: %w", err)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
 	}
-	filePath := filepath.Join(ds.dataDir, "investments.json")
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write investments file: %w", err)
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s is corrupt, refusing to start (restore it from a backup export): %w", path, err)
 	}
 	return nil
 }
 
-// SaveExpenses writes expenses to file
-func (ds *DataStore) SaveExpenses() error {
-	ds.mu.RLock()
-	defer ds.mu.RUnlock()
-	data, err := json.MarshalIndent(ds.expenses, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal expenses: %w", err)
+// record journals a mutation and marks its collection dirty.
+// Callers must hold ds.mu for writing and apply the change only on success.
+func (ds *DataStore) record(op, entity, id string, v interface{}) error {
+	if err := ds.journal.append(op, entity, id, v); err != nil {
+		return err
 	}
-	filePath := filepath.Join(ds.dataDir, "expenses.json")
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write expenses file: %w", err)
+	if entity == importCollection {
+		for _, name := range collections {
+			ds.dirty[name] = true
+		}
+	} else {
+		ds.dirty[entity] = true
 	}
 	return nil
 }
 
-// SaveIncomes writes incomes to file
-func (ds *DataStore) SaveIncomes() error {
-	ds.mu.RLock()
-	defer ds.mu.RUnlock()
-	data, err := json.MarshalIndent(ds.incomes, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal incomes: %w", err)
-	}
-	filePath := filepath.Join(ds.dataDir, "incomes.json")
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write incomes file: %w", err)
+// apply replays one journal entry against memory
+func (ds *DataStore) apply(entry journalEntry) error {
+	switch entry.Entity {
+	case investmentsCollection:
+		var inv models.Investment
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &inv); err != nil {
+				return err
+			}
+		}
+		ds.investments = applyToSlice(ds.investments, entry, inv, func(i models.Investment) string { return i.ID })
+	case incomesCollection:
+		var inc models.Income
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &inc); err != nil {
+				return err
+			}
+		}
+		ds.incomes = applyToSlice(ds.incomes, entry, inc, func(i models.Income) string { return i.ID })
+	case expensesCollection:
+		var exp models.Expense
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &exp); err != nil {
+				return err
+			}
+		}
+		ds.expenses = applyToSlice(ds.expenses, entry, exp, func(e models.Expense) string { return e.ID })
+	case budgetsCollection:
+		var b models.Budget
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &b); err != nil {
+				return err
+			}
+		}
+		ds.budgets = applyToSlice(ds.budgets, entry, b, func(b models.Budget) string { return b.ID })
+	case recurringCollection:
+		var item models.RecurringRule
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.recurring = applyToSlice(ds.recurring, entry, item, func(v models.RecurringRule) string { return v.ID })
+	case pricesCollection:
+		var points []models.PricePoint
+		if err := json.Unmarshal(entry.Data, &points); err != nil {
+			return err
+		}
+		ds.prices = mergePrices(ds.prices, points)
+	case chitsCollection:
+		var item models.Chit
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.chits = applyToSlice(ds.chits, entry, item, func(v models.Chit) string { return v.ID })
+	case loansCollection:
+		var item models.Loan
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.loans = applyToSlice(ds.loans, entry, item, func(v models.Loan) string { return v.ID })
+	case accountsCollection:
+		var item models.Account
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.accounts = applyToSlice(ds.accounts, entry, item, func(v models.Account) string { return v.ID })
+	case transfersCollection:
+		var item models.Transfer
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.transfers = applyToSlice(ds.transfers, entry, item, func(v models.Transfer) string { return v.ID })
+	case statementProfilesCollection:
+		var item models.StatementProfile
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.statementProfiles = applyToSlice(ds.statementProfiles, entry, item, func(v models.StatementProfile) string { return v.ID })
+	case statementImportsCollection:
+		var item models.StatementImport
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.statementImports = applyToSlice(ds.statementImports, entry, item, func(v models.StatementImport) string { return v.ID })
+	case mergesCollection:
+		var item models.Merge
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.merges = applyToSlice(ds.merges, entry, item, func(v models.Merge) string { return v.ID })
+	case rulesCollection:
+		var item models.Rule
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.rules = applyToSlice(ds.rules, entry, item, func(v models.Rule) string { return v.ID })
+	case settlementsCollection:
+		var item models.Settlement
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.settlements = applyToSlice(ds.settlements, entry, item, func(v models.Settlement) string { return v.ID })
+	case usersCollection:
+		var item models.User
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.users = applyToSlice(ds.users, entry, item, func(v models.User) string { return v.ID })
+	case sessionsCollection:
+		var item models.Session
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.sessions = applyToSlice(ds.sessions, entry, item, func(v models.Session) string { return v.ID })
+	case apiTokensCollection:
+		var item models.APIToken
+		if entry.Op == opPut {
+			if err := json.Unmarshal(entry.Data, &item); err != nil {
+				return err
+			}
+		}
+		ds.apiTokens = applyToSlice(ds.apiTokens, entry, item, func(v models.APIToken) string { return v.ID })
+	case settingsCollection:
+		var settings models.Settings
+		if err := json.Unmarshal(entry.Data, &settings); err != nil {
+			return err
+		}
+		ds.settings = settings
+	case importCollection:
+		var data models.ExportData
+		if err := json.Unmarshal(entry.Data, &data); err != nil {
+			return err
+		}
+		ds.importLocked(data)
+	default:
+		return fmt.Errorf("unknown journal entity %q", entry.Entity)
 	}
 	return nil
 }
 
-// SaveSettings writes settings to file
-func (ds *DataStore) SaveSettings() error {
-	ds.mu.RLock()
-	defer ds.mu.RUnlock()
-	data, err := json.MarshalIndent(ds.settings, "", "  ")
+// applyToSlice applies a put or delete entry to a collection by ID
+func applyToSlice[T any](items []T, entry journalEntry, item T, idOf func(T) string) []T {
+	for i := range items {
+		if idOf(items[i]) != entry.ID {
+			continue
+		}
+		if entry.Op == opDelete {
+			return append(items[:i], items[i+1:]...)
+		}
+		items[i] = item
+		return items
+	}
+	if entry.Op == opPut {
+		items = append(items, item)
+	}
+	return items
+}
+
+// persist atomically writes one collection and checkpoints if nothing else
+// is pending. Callers must hold ds.mu for writing.
+func (ds *DataStore) persist(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal settings: %w", err)
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
 	}
-	filePath := filepath.Join(ds.dataDir, "settings.json")
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write settings file: %w", err)
+	filePath := filepath.Join(ds.dataDir, name+".json")
+	if err := writeFileAtomic(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", name, err)
+	}
+	delete(ds.dirty, name)
+	if len(ds.dirty) == 0 {
+		return ds.journal.reset()
 	}
 	return nil
 }
 
+// checkpoint writes every dirty collection, which also clears the journal
+func (ds *DataStore) checkpoint() error {
+	for _, name := range collections {
+		if ds.dirty[name] {
+			if err := ds.persist(name, ds.collection(name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SaveInvestments writes investments to file
+func (ds *DataStore) SaveInvestments() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.persist(investmentsCollection, ds.investments)
+}
+
+// SaveExpenses writes expenses to file
+func (ds *DataStore) SaveExpenses() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.persist(expensesCollection, ds.expenses)
+}
+
+// SaveIncomes writes incomes to file
+func (ds *DataStore) SaveIncomes() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.persist(incomesCollection, ds.incomes)
+}
+
+// SaveSettings writes settings to file
+func (ds *DataStore) SaveSettings() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.persist(settingsCollection, ds.settings)
+}
+
 // GetInvestments returns all investments
 func (ds *DataStore) GetInvestments() []models.Investment {
 	ds.mu.RLock()
@@ -150,19 +500,35 @@ func (ds *DataStore) GetInvestments() []models.Investment {
 	return ds.investments
 }
 
+// QueryInvestments returns one page of investments matching q and the total match count
+func (ds *DataStore) QueryInvestments(q Query) ([]models.Investment, int, error) {
+	if err := q.Validate(); err != nil {
+		return nil, 0, err
+	}
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	page, total := filterSortPage(ds.investments, q, q.matchInvestment, investmentSortKey)
+	return page, total, nil
+}
+
 // AddInvestment adds a new investment
 func (ds *DataStore) AddInvestment(inv models.Investment) error {
+	inv.EnsureTransactions()
 	if err := inv.Validate(); err != nil {
 		return fmt.Errorf("invalid investment: %w", err)
 	}
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
+	if err := ds.record(opPut, investmentsCollection, inv.ID, inv); err != nil {
+		return err
+	}
 	ds.investments = append(ds.investments, inv)
 	return nil
 }
 
 // UpdateInvestment updates an existing investment
 func (ds *DataStore) UpdateInvestment(id string, updated models.Investment) error {
+	updated.EnsureTransactions()
 	if err := updated.Validate(); err != nil {
 		return fmt.Errorf("invalid investment: %w", err)
 	}
@@ -170,6 +536,9 @@ func (ds *DataStore) UpdateInvestment(id string, updated models.Investment) erro
 	defer ds.mu.Unlock()
 	for i, inv := range ds.investments {
 		if inv.ID == id {
+			if err := ds.record(opPut, investmentsCollection, id, updated); err != nil {
+				return err
+			}
 			ds.investments[i] = updated
 			return nil
 		}
@@ -177,12 +546,40 @@ func (ds *DataStore) UpdateInvestment(id string, updated models.Investment) erro
 	return fmt.Errorf("investment not found")
 }
 
+// ModifyInvestment applies change to an investment under the store lock
+func (ds *DataStore) ModifyInvestment(id string, change func(*models.Investment) error) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	for i, inv := range ds.investments {
+		if inv.ID != id {
+			continue
+		}
+		inv.Transactions = append([]models.InvestmentTransaction(nil), inv.Transactions...)
+		if err := change(&inv); err != nil {
+			return err
+		}
+		inv.EnsureTransactions()
+		if err := inv.Validate(); err != nil {
+			return fmt.Errorf("invalid investment: %w", err)
+		}
+		if err := ds.record(opPut, investmentsCollection, id, inv); err != nil {
+			return err
+		}
+		ds.investments[i] = inv
+		return nil
+	}
+	return fmt.Errorf("investment %w", ErrNotFound)
+}
+
 // DeleteInvestment removes an investment
 func (ds *DataStore) DeleteInvestment(id string) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 	for i, inv := range ds.investments {
 		if inv.ID == id {
+			if err := ds.record(opDelete, investmentsCollection, id, nil); err != nil {
+				return err
+			}
 			ds.investments = append(ds.investments[:i], ds.investments[i+1:]...)
 			return nil
 		}
@@ -197,6 +594,17 @@ func (ds *DataStore) GetIncomes() []models.Income {
 	return ds.incomes
 }
 
+// QueryIncomes returns one page of incomes matching q and the total match count
+func (ds *DataStore) QueryIncomes(q Query) ([]models.Income, int, error) {
+	if err := q.Validate(); err != nil {
+		return nil, 0, err
+	}
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	page, total := filterSortPage(ds.incomes, q, q.matchIncome, incomeSortKey)
+	return page, total, nil
+}
+
 // AddIncome adds a new income
 func (ds *DataStore) AddIncome(inc models.Income) error {
 	if err := inc.Validate(); err != nil {
@@ -204,6 +612,9 @@ func (ds *DataStore) AddIncome(inc models.Income) error {
 	}
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
+	if err := ds.record(opPut, incomesCollection, inc.ID, inc); err != nil {
+		return err
+	}
 	ds.incomes = append(ds.incomes, inc)
 	return nil
 }
@@ -217,6 +628,9 @@ func (ds *DataStore) UpdateIncome(id string, updated models.Income) error {
 	defer ds.mu.Unlock()
 	for i, inc := range ds.incomes {
 		if inc.ID == id {
+			if err := ds.record(opPut, incomesCollection, id, updated); err != nil {
+				return err
+			}
 			ds.incomes[i] = updated
 			return nil
 		}
@@ -230,6 +644,9 @@ func (ds *DataStore) DeleteIncome(id string) error {
 	defer ds.mu.Unlock()
 	for i, inc := range ds.incomes {
 		if inc.ID == id {
+			if err := ds.record(opDelete, incomesCollection, id, nil); err != nil {
+				return err
+			}
 			ds.incomes = append(ds.incomes[:i], ds.incomes[i+1:]...)
 			return nil
 		}
@@ -244,6 +661,17 @@ func (ds *DataStore) GetExpenses() []models.Expense {
 	return ds.expenses
 }
 
+// QueryExpenses returns one page of expenses matching q and the total match count
+func (ds *DataStore) QueryExpenses(q Query) ([]models.Expense, int, error) {
+	if err := q.Validate(); err != nil {
+		return nil, 0, err
+	}
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	page, total := filterSortPage(ds.expenses, q, q.matchExpense, expenseSortKey)
+	return page, total, nil
+}
+
 // AddExpense adds a new expense
 func (ds *DataStore) AddExpense(exp models.Expense) error {
 	if err := exp.Validate(); err != nil {
@@ -251,6 +679,9 @@ func (ds *DataStore) AddExpense(exp models.Expense) error {
 	}
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
+	if err := ds.record(opPut, expensesCollection, exp.ID, exp); err != nil {
+		return err
+	}
 	ds.expenses = append(ds.expenses, exp)
 	return nil
 }
@@ -264,6 +695,9 @@ func (ds *DataStore) UpdateExpense(id string, updated models.Expense) error {
 	defer ds.mu.Unlock()
 	for i, exp := range ds.expenses {
 		if exp.ID == id {
+			if err := ds.record(opPut, expensesCollection, id, updated); err != nil {
+				return err
+			}
 			ds.expenses[i] = updated
 			return nil
 		}
@@ -277,6 +711,9 @@ func (ds *DataStore) DeleteExpense(id string) error {
 	defer ds.mu.Unlock()
 	for i, exp := range ds.expenses {
 		if exp.ID == id {
+			if err := ds.record(opDelete, expensesCollection, id, nil); err != nil {
+				return err
+			}
 			ds.expenses = append(ds.expenses[:i], ds.expenses[i+1:]...)
 			return nil
 		}
@@ -295,6 +732,9 @@ func (ds *DataStore) GetSettings() models.Settings {
 func (ds *DataStore) UpdateSettings(settings models.Settings) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
+	if err := ds.record(opReplace, settingsCollection, "", settings); err != nil {
+		return err
+	}
 	ds.settings = settings
 	return nil
 }
@@ -304,10 +744,21 @@ func (ds *DataStore) GetExportData() models.ExportData {
 	ds.mu.RLock()
 	defer ds.mu.RUnlock()
 	return models.ExportData{
-		Investments: ds.investments,
-		Incomes:     ds.incomes,
-		Expenses:    ds.expenses,
-		Settings:    ds.settings,
+		Investments:       ds.investments,
+		Incomes:           ds.incomes,
+		Expenses:          ds.expenses,
+		Settings:          ds.settings,
+		Budgets:           ds.budgets,
+		Recurring:         ds.recurring,
+		Prices:            ds.prices,
+		Chits:             ds.chits,
+		Loans:             ds.loans,
+		Accounts:          ds.accounts,
+		Transfers:         ds.transfers,
+		StatementProfiles: ds.statementProfiles,
+		Merges:            ds.merges,
+		Rules:             ds.rules,
+		Settlements:       ds.settlements,
 	}
 }
 
@@ -315,7 +766,19 @@ func (ds *DataStore) GetExportData() models.ExportData {
 func (ds *DataStore) ImportData(data models.ExportData) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
+	if err := ds.record(opReplace, importCollection, "", data); err != nil {
+		return err
+	}
+	ds.importLocked(data)
+	return nil
+}
+
+// importLocked replaces each non-empty collection. Callers must hold ds.mu.
+func (ds *DataStore) importLocked(data models.ExportData) {
 	if len(data.Investments) > 0 {
+		for i := range data.Investments {
+			data.Investments[i].EnsureTransactions()
+		}
 		ds.investments = data.Investments
 	}
 	if len(data.Incomes) > 0 {
@@ -327,5 +790,37 @@ func (ds *DataStore) ImportData(data models.ExportData) error {
 	if len(data.Settings.Categories) > 0 {
 		ds.settings = data.Settings
 	}
-	return nil
+	if len(data.Budgets) > 0 {
+		ds.budgets = data.Budgets
+	}
+	if len(data.Recurring) > 0 {
+		ds.recurring = data.Recurring
+	}
+	if len(data.Prices) > 0 {
+		ds.prices = data.Prices
+	}
+	if len(data.Chits) > 0 {
+		ds.chits = data.Chits
+	}
+	if len(data.Loans) > 0 {
+		ds.loans = data.Loans
+	}
+	if len(data.Accounts) > 0 {
+		ds.accounts = data.Accounts
+	}
+	if len(data.Transfers) > 0 {
+		ds.transfers = data.Transfers
+	}
+	if len(data.StatementProfiles) > 0 {
+		ds.statementProfiles = data.StatementProfiles
+	}
+	if len(data.Merges) > 0 {
+		ds.merges = data.Merges
+	}
+	if len(data.Rules) > 0 {
+		ds.rules = data.Rules
+	}
+	if len(data.Settlements) > 0 {
+		ds.settlements = data.Settlements
+	}
 }
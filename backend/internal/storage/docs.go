@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+)
+
+// docKind describes a kind of record both stores keep whole, by ID: a
+// slice and journal collection in DataStore, a document table in SQLite.
+// The per-type files wrap its methods in the Storage interface.
+type docKind[T any] struct {
+	noun       string // e.g. "budget", in errors
+	collection string // DataStore collection
+	table      string // SQLite table
+	slice      func(ds *DataStore) *[]T
+	id         func(T) string
+	validate   func(*T) error
+}
+
+// ----- DataStore -----
+
+// dsGet returns every record
+func (k docKind[T]) dsGet(ds *DataStore) []T {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	return *k.slice(ds)
+}
+
+// dsAdd adds a new record
+func (k docKind[T]) dsAdd(ds *DataStore, item T) error {
+	if err := k.validate(&item); err != nil {
+		return fmt.Errorf("invalid %s: %w", k.noun, err)
+	}
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if err := ds.record(opPut, k.collection, k.id(item), item); err != nil {
+		return err
+	}
+	items := k.slice(ds)
+	*items = append(*items, item)
+	return nil
+}
+
+// dsUpdate replaces the record with id
+func (k docKind[T]) dsUpdate(ds *DataStore, id string, updated T) error {
+	if err := k.validate(&updated); err != nil {
+		return fmt.Errorf("invalid %s: %w", k.noun, err)
+	}
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	items := *k.slice(ds)
+	for i, item := range items {
+		if k.id(item) == id {
+			if err := ds.record(opPut, k.collection, id, updated); err != nil {
+				return err
+			}
+			items[i] = updated
+			return nil
+		}
+	}
+	return fmt.Errorf("%s not found", k.noun)
+}
+
+// dsDelete removes the record with id
+func (k docKind[T]) dsDelete(ds *DataStore, id string) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	items := k.slice(ds)
+	for i, item := range *items {
+		if k.id(item) == id {
+			if err := ds.record(opDelete, k.collection, id, nil); err != nil {
+				return err
+			}
+			*items = append((*items)[:i], (*items)[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s not found", k.noun)
+}
+
+// dsSave writes the records to their file
+func (k docKind[T]) dsSave(ds *DataStore) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.persist(k.collection, *k.slice(ds))
+}
+
+// ----- SQLiteStore -----
+
+// sqlGet returns every record; a failed query is logged and reads as none
+func (k docKind[T]) sqlGet(s *SQLiteStore) []T {
+	items, err := listDocs[T](s, k.table)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return []T{}
+	}
+	return items
+}
+
+// sqlAdd adds a new record
+func (k docKind[T]) sqlAdd(s *SQLiteStore, item T) error {
+	if err := k.validate(&item); err != nil {
+		return fmt.Errorf("invalid %s: %w", k.noun, err)
+	}
+	return insertDoc(s.db, k.table, k.id(item), item)
+}
+
+// sqlUpdate replaces the record with id
+func (k docKind[T]) sqlUpdate(s *SQLiteStore, id string, updated T) error {
+	if err := k.validate(&updated); err != nil {
+		return fmt.Errorf("invalid %s: %w", k.noun, err)
+	}
+	return updateDoc(s.db, k.table, id, updated, k.noun+" not found")
+}
+
+// sqlDelete removes the record with id
+func (k docKind[T]) sqlDelete(s *SQLiteStore, id string) error {
+	return deleteDoc(s.db, k.table, id, k.noun+" not found")
+}
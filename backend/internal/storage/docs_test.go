@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"finance-tracker/internal/models"
+)
+
+// closeStore closes a store's files or database
+func closeStore(store Storage) {
+	if c, ok := store.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func TestDocKind(t *testing.T) {
+	for _, driver := range []string{DriverJSON, DriverSQLite} {
+		t.Run(driver, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := Open(driver, dir)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			food := models.Budget{ID: "food", Name: "Food", Categories: []string{"Food"}, Period: models.PeriodMonthly, Limit: 8000}
+			fuel := models.Budget{ID: "fuel", Name: "Fuel", Categories: []string{"Transport"}, Period: models.PeriodMonthly, Limit: 3000}
+			for _, b := range []models.Budget{food, fuel} {
+				if err := store.AddBudget(b); err != nil {
+					t.Fatalf("AddBudget() error = %v", err)
+				}
+			}
+			if err := store.AddBudget(models.Budget{ID: "bad"}); err == nil {
+				t.Error("AddBudget() of an invalid budget error = nil")
+			}
+
+			food.Limit = 9000
+			if err := store.UpdateBudget("food", food); err != nil {
+				t.Fatalf("UpdateBudget() error = %v", err)
+			}
+			if err := store.UpdateBudget("none", food); err == nil || err.Error() != "budget not found" {
+				t.Errorf("UpdateBudget() of a missing budget error = %v, want budget not found", err)
+			}
+			if err := store.DeleteBudget("none"); err == nil || err.Error() != "budget not found" {
+				t.Errorf("DeleteBudget() of a missing budget error = %v, want budget not found", err)
+			}
+			if got, want := store.GetBudgets(), []models.Budget{food, fuel}; !reflect.DeepEqual(got, want) {
+				t.Errorf("GetBudgets() = %+v, want %+v", got, want)
+			}
+
+			if err := store.DeleteBudget("food"); err != nil {
+				t.Fatalf("DeleteBudget() error = %v", err)
+			}
+			if err := store.SaveBudgets(); err != nil {
+				t.Fatalf("SaveBudgets() error = %v", err)
+			}
+			closeStore(store)
+
+			// What was saved reads back
+			store, err = Open(driver, dir)
+			if err != nil {
+				t.Fatalf("Open() again error = %v", err)
+			}
+			defer closeStore(store)
+			if got, want := store.GetBudgets(), []models.Budget{fuel}; !reflect.DeepEqual(got, want) {
+				t.Errorf("GetBudgets() after reopening = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
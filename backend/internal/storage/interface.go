@@ -1,19 +1,33 @@
 package storage
 
-import "finance-tracker/internal/models"
+import (
+	"errors"
+
+	"finance-tracker/internal/models"
+)
+
+// ErrNotFound is wrapped by errors for records that do not exist, where
+// callers need to tell them apart from failures
+var ErrNotFound = errors.New("not found")
 
 // Storage defines the interface for data storage operations
 // This allows for testing with mock implementations
 type Storage interface {
 	// Investments
 	GetInvestments() []models.Investment
+	QueryInvestments(q Query) ([]models.Investment, int, error)
 	AddInvestment(inv models.Investment) error
 	UpdateInvestment(id string, updated models.Investment) error
+	// ModifyInvestment reads investment id, applies change and stores the
+	// result in one step, so edits made meanwhile are not overwritten. A
+	// change error aborts it; a missing investment wraps ErrNotFound.
+	ModifyInvestment(id string, change func(*models.Investment) error) error
 	DeleteInvestment(id string) error
 	SaveInvestments() error
 
 	// Incomes
 	GetIncomes() []models.Income
+	QueryIncomes(q Query) ([]models.Income, int, error)
 	AddIncome(inc models.Income) error
 	UpdateIncome(id string, updated models.Income) error
 	DeleteIncome(id string) error
@@ -21,6 +35,7 @@ type Storage interface {
 
 	// Expenses
 	GetExpenses() []models.Expense
+	QueryExpenses(q Query) ([]models.Expense, int, error)
 	AddExpense(exp models.Expense) error
 	UpdateExpense(id string, updated models.Expense) error
 	DeleteExpense(id string) error
@@ -31,6 +46,109 @@ type Storage interface {
 	UpdateSettings(settings models.Settings) error
 	SaveSettings() error
 
+	// Budgets
+	GetBudgets() []models.Budget
+	AddBudget(b models.Budget) error
+	UpdateBudget(id string, updated models.Budget) error
+	DeleteBudget(id string) error
+	SaveBudgets() error
+
+	// Recurring rules
+	GetRecurringRules() []models.RecurringRule
+	AddRecurringRule(rule models.RecurringRule) error
+	UpdateRecurringRule(id string, updated models.RecurringRule) error
+	DeleteRecurringRule(id string) error
+	SaveRecurringRules() error
+
+	// Price history
+	GetPrices(symbol string) []models.PricePoint
+	AddPrices(points []models.PricePoint) error
+	SavePrices() error
+
+	// Chits
+	GetChits() []models.Chit
+	AddChit(c models.Chit) error
+	UpdateChit(id string, updated models.Chit) error
+	DeleteChit(id string) error
+	SaveChits() error
+
+	// Loans
+	GetLoans() []models.Loan
+	AddLoan(l models.Loan) error
+	UpdateLoan(id string, updated models.Loan) error
+	DeleteLoan(id string) error
+	SaveLoans() error
+
+	// Accounts
+	GetAccounts() []models.Account
+	AddAccount(a models.Account) error
+	UpdateAccount(id string, updated models.Account) error
+	DeleteAccount(id string) error
+	SaveAccounts() error
+
+	// Transfers
+	GetTransfers() []models.Transfer
+	AddTransfer(t models.Transfer) error
+	UpdateTransfer(id string, updated models.Transfer) error
+	DeleteTransfer(id string) error
+	SaveTransfers() error
+
+	// Statement profiles
+	GetStatementProfiles() []models.StatementProfile
+	AddStatementProfile(p models.StatementProfile) error
+	UpdateStatementProfile(id string, updated models.StatementProfile) error
+	DeleteStatementProfile(id string) error
+	SaveStatementProfiles() error
+
+	// Statement imports
+	GetStatementImports() []models.StatementImport
+	AddStatementImport(si models.StatementImport) error
+	UpdateStatementImport(id string, updated models.StatementImport) error
+	DeleteStatementImport(id string) error
+	SaveStatementImports() error
+
+	// Merges
+	GetMerges() []models.Merge
+	AddMerge(m models.Merge) error
+	UpdateMerge(id string, updated models.Merge) error
+	DeleteMerge(id string) error
+	SaveMerges() error
+
+	// Categorisation rules
+	GetRules() []models.Rule
+	AddRule(rule models.Rule) error
+	UpdateRule(id string, updated models.Rule) error
+	DeleteRule(id string) error
+	SaveRules() error
+
+	// Settlements
+	GetSettlements() []models.Settlement
+	AddSettlement(st models.Settlement) error
+	UpdateSettlement(id string, updated models.Settlement) error
+	DeleteSettlement(id string) error
+	SaveSettlements() error
+
+	// Users
+	GetUsers() []models.User
+	AddUser(u models.User) error
+	UpdateUser(id string, updated models.User) error
+	DeleteUser(id string) error
+	SaveUsers() error
+
+	// Sessions
+	GetSessions() []models.Session
+	AddSession(sess models.Session) error
+	UpdateSession(id string, updated models.Session) error
+	DeleteSession(id string) error
+	SaveSessions() error
+
+	// API tokens
+	GetAPITokens() []models.APIToken
+	AddAPIToken(tok models.APIToken) error
+	UpdateAPIToken(id string, updated models.APIToken) error
+	DeleteAPIToken(id string) error
+	SaveAPITokens() error
+
 	// Export/Import
 	GetExportData() models.ExportData
 	ImportData(data models.ExportData) error
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// journalFileName is the append-only mutation log kept next to the data files
+const journalFileName = "journal.log"
+
+// journalEntry is one mutation, written before it is applied in memory.
+// Every op sets state rather than adjusting it, so replaying entries on top
+// of a snapshot that already contains some of them is harmless.
+type journalEntry struct {
+	Seq    uint64          `json:"seq"`
+	Op     string          `json:"op"`     // "put", "delete", "replace" or "merge"
+	Entity string          `json:"entity"` // collection name, e.g. "expenses"
+	ID     string          `json:"id,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// Journal operations
+const (
+	opPut     = "put"     // insert or overwrite the record with ID
+	opDelete  = "delete"  // remove the record with ID if present
+	opReplace = "replace" // overwrite the whole collection (settings, import)
+	opMerge   = "merge"   // insert or overwrite every record in Data (price batches)
+)
+
+// journal is the write-ahead log used by DataStore. It is cleared once
+// every collection it mentions has been written back to its JSON file.
+type journal struct {
+	file *os.File
+	seq  uint64
+}
+
+// openJournal opens the journal in dataDir and returns any entries left over
+// from a previous run. A torn final line (crash mid-append) is dropped and
+// cut from the file; any other unreadable line is reported as corruption.
+func openJournal(dataDir string) (*journal, []journalEntry, error) {
+	path := filepath.Join(dataDir, journalFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []journalEntry
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry journalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if i == len(lines)-1 {
+				// Last line without a newline: the append never completed.
+				// Cut it off, or the next append would follow it.
+				if err := truncateJournal(file, int64(bytes.LastIndexByte(data, '\n')+1)); err != nil {
+					file.Close()
+					return nil, nil, err
+				}
+				break
+			}
+			file.Close()
+			return nil, nil, fmt.Errorf("journal %s is corrupt at line %d: %w", path, i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	j := &journal{file: file}
+	if len(entries) > 0 {
+		j.seq = entries[len(entries)-1].Seq
+	}
+	return j, entries, nil
+}
+
+// append durably records one mutation
+func (j *journal) append(op, entity, id string, v interface{}) error {
+	var data json.RawMessage
+	if v != nil {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal journal entry: %w", err)
+		}
+		data = raw
+	}
+
+	line, err := json.Marshal(journalEntry{Seq: j.seq + 1, Op: op, Entity: entity, ID: id, Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+
+	// A failed write or sync is cut off again, so a part-written line does
+	// not end up in the middle of the journal
+	offset, err := j.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to append to journal: %w", err)
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		truncateJournal(j.file, offset)
+		return fmt.Errorf("failed to append to journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		truncateJournal(j.file, offset)
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	j.seq++
+	return nil
+}
+
+// truncateJournal cuts the journal file back to size and continues writing
+// from there
+func truncateJournal(file *os.File, size int64) error {
+	if err := file.Truncate(size); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return nil
+}
+
+// reset empties the journal after a checkpoint
+func (j *journal) reset() error {
+	return truncateJournal(j.file, 0)
+}
+
+// close closes the journal file
+func (j *journal) close() error {
+	return j.file.Close()
+}
+
+// writeFileAtomic replaces path with data so that readers see either the old
+// or the new contents, never a partial file: it writes a temp file in the same
+// directory, fsyncs it, renames it over path and fsyncs the directory.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once the rename has succeeded
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
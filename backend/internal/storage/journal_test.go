@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenJournalTornTail(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int // entries read back before the new append
+	}{
+		{"only a torn line", `{"seq":1,"op":"put","enti`, 0},
+		{"torn after an entry", `{"seq":1,"op":"put","entity":"expenses","id":"a"}` + "\n" + `{"seq":2,"op":"pu`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, journalFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			j, entries, err := openJournal(dir)
+			if err != nil {
+				t.Fatalf("openJournal() error = %v", err)
+			}
+			if len(entries) != tt.want {
+				t.Fatalf("openJournal() read %d entries, want %d", len(entries), tt.want)
+			}
+			if err := j.append(opPut, "expenses", "b", map[string]string{"id": "b"}); err != nil {
+				t.Fatalf("append() error = %v", err)
+			}
+			j.close()
+
+			// The torn line is gone, so the journal reads back cleanly
+			j, entries, err = openJournal(dir)
+			if err != nil {
+				t.Fatalf("openJournal() after append error = %v", err)
+			}
+			defer j.close()
+			if len(entries) != tt.want+1 {
+				t.Fatalf("openJournal() after append read %d entries, want %d", len(entries), tt.want+1)
+			}
+			if last := entries[len(entries)-1]; last.ID != "b" || last.Seq != uint64(tt.want+1) {
+				t.Errorf("last entry = %+v, want id b and seq %d", last, tt.want+1)
+			}
+		})
+	}
+}
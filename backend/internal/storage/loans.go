@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// loanDocs stores loans (see docKind)
+var loanDocs = docKind[models.Loan]{
+	noun:       "loan",
+	collection: loansCollection,
+	table:      "loans",
+	slice:      func(ds *DataStore) *[]models.Loan { return &ds.loans },
+	id:         func(l models.Loan) string { return l.ID },
+	validate:   (*models.Loan).Validate,
+}
+
+// ----- DataStore -----
+
+// GetLoans returns all loans
+func (ds *DataStore) GetLoans() []models.Loan { return loanDocs.dsGet(ds) }
+
+// AddLoan adds a new loan
+func (ds *DataStore) AddLoan(l models.Loan) error { return loanDocs.dsAdd(ds, l) }
+
+// UpdateLoan updates an existing loan
+func (ds *DataStore) UpdateLoan(id string, updated models.Loan) error {
+	return loanDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteLoan removes a loan
+func (ds *DataStore) DeleteLoan(id string) error { return loanDocs.dsDelete(ds, id) }
+
+// SaveLoans writes loans to file
+func (ds *DataStore) SaveLoans() error { return loanDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetLoans returns all loans
+func (s *SQLiteStore) GetLoans() []models.Loan { return loanDocs.sqlGet(s) }
+
+// AddLoan adds a new loan
+func (s *SQLiteStore) AddLoan(l models.Loan) error { return loanDocs.sqlAdd(s, l) }
+
+// UpdateLoan updates an existing loan
+func (s *SQLiteStore) UpdateLoan(id string, updated models.Loan) error {
+	return loanDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteLoan removes a loan
+func (s *SQLiteStore) DeleteLoan(id string) error { return loanDocs.sqlDelete(s, id) }
+
+// SaveLoans is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveLoans() error { return nil }
@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// mergeDocs stores merges (see docKind)
+var mergeDocs = docKind[models.Merge]{
+	noun:       "merge",
+	collection: mergesCollection,
+	table:      "merges",
+	slice:      func(ds *DataStore) *[]models.Merge { return &ds.merges },
+	id:         func(m models.Merge) string { return m.ID },
+	validate:   (*models.Merge).Validate,
+}
+
+// ----- DataStore -----
+
+// GetMerges returns all merges
+func (ds *DataStore) GetMerges() []models.Merge { return mergeDocs.dsGet(ds) }
+
+// AddMerge adds a new merge
+func (ds *DataStore) AddMerge(m models.Merge) error { return mergeDocs.dsAdd(ds, m) }
+
+// UpdateMerge updates an existing merge
+func (ds *DataStore) UpdateMerge(id string, updated models.Merge) error {
+	return mergeDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteMerge removes a merge
+func (ds *DataStore) DeleteMerge(id string) error { return mergeDocs.dsDelete(ds, id) }
+
+// SaveMerges writes merges to file
+func (ds *DataStore) SaveMerges() error { return mergeDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetMerges returns all merges
+func (s *SQLiteStore) GetMerges() []models.Merge { return mergeDocs.sqlGet(s) }
+
+// AddMerge adds a new merge
+func (s *SQLiteStore) AddMerge(m models.Merge) error { return mergeDocs.sqlAdd(s, m) }
+
+// UpdateMerge updates an existing merge
+func (s *SQLiteStore) UpdateMerge(id string, updated models.Merge) error {
+	return mergeDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteMerge removes a merge
+func (s *SQLiteStore) DeleteMerge(id string) error { return mergeDocs.sqlDelete(s, id) }
+
+// SaveMerges is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveMerges() error { return nil }
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"fmt"
+
+	"finance-tracker/internal/models"
+)
+
+// Supported storage drivers (config.Config.StorageDriver)
+const (
+	DriverJSON   = "json"
+	DriverSQLite = "sqlite"
+)
+
+// Open creates the storage backend selected by driver
+// An empty driver falls back to the JSON file store
+func Open(driver, dataDir string) (Storage, error) {
+	switch driver {
+	case "", DriverJSON:
+		return NewDataStore(dataDir)
+	case DriverSQLite:
+		return NewSQLiteStore(dataDir)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q (expected %q or %q)", driver, DriverJSON, DriverSQLite)
+	}
+}
+
+// defaultSettings returns the settings used before the user saves their own
+func defaultSettings() models.Settings {
+	return models.Settings{
+		Categories:       []string{"Food", "Transport", "Utilities", "Shopping", "Entertainment", "Health", "EMI", "Household", "Other"},
+		InvestmentTypes:  []string{"Mutual Fund", "Stocks", "FD", "RD", "Gold", "PPF", "NPS", "Chit", "Other"},
+		IncomeCategories: []string{"Salary", "Business", "Rental", "Freelance", "Interest", "Dividend", "Other"},
+		PaymentMethods:   []string{"Online", "Cash", "Card", "UPI", "Bank Transfer"},
+		Members:          []string{"Ravi", "Akshata"},
+		Tags:             []string{"reimbursable", "tax-deductible"},
+	}
+}
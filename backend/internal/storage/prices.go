@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"finance-tracker/internal/models"
+)
+
+// ----- DataStore -----
+
+// GetPrices returns the price history of symbol, oldest first
+func (ds *DataStore) GetPrices(symbol string) []models.PricePoint {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	points := []models.PricePoint{}
+	for _, p := range ds.prices {
+		if p.Symbol == symbol {
+			points = append(points, p)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Date < points[j].Date })
+	return points
+}
+
+// AddPrices stores a batch of prices, overwriting any existing price for the
+// same symbol and date
+func (ds *DataStore) AddPrices(points []models.PricePoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	for i := range points {
+		if err := points[i].Validate(); err != nil {
+			return fmt.Errorf("invalid price: %w", err)
+		}
+	}
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if err := ds.record(opMerge, pricesCollection, "", points); err != nil {
+		return err
+	}
+	ds.prices = mergePrices(ds.prices, points)
+	return nil
+}
+
+// SavePrices writes the price history to file
+func (ds *DataStore) SavePrices() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.persist(pricesCollection, ds.prices)
+}
+
+// mergePrices upserts points into prices by symbol and date
+func mergePrices(prices, points []models.PricePoint) []models.PricePoint {
+	type key struct{ symbol, date string }
+	index := make(map[key]int, len(prices))
+	for i, p := range prices {
+		index[key{p.Symbol, p.Date}] = i
+	}
+	for _, p := range points {
+		k := key{p.Symbol, p.Date}
+		if i, ok := index[k]; ok {
+			prices[i] = p
+			continue
+		}
+		index[k] = len(prices)
+		prices = append(prices, p)
+	}
+	return prices
+}
+
+// ----- SQLiteStore -----
+
+// GetPrices returns the price history of symbol, oldest first
+func (s *SQLiteStore) GetPrices(symbol string) []models.PricePoint {
+	points, err := s.queryPrices("SELECT symbol, date, price, source FROM prices WHERE symbol = ? ORDER BY date", symbol)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return []models.PricePoint{}
+	}
+	return points
+}
+
+// AddPrices stores a batch of prices, overwriting any existing price for the
+// same symbol and date
+func (s *SQLiteStore) AddPrices(points []models.PricePoint) error {
+	for i := range points {
+		if err := points[i].Validate(); err != nil {
+			return fmt.Errorf("invalid price: %w", err)
+		}
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin price update: %w", err)
+	}
+	defer tx.Rollback()
+	if err := upsertPrices(tx, points); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SavePrices is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SavePrices() error { return nil }
+
+// allPrices returns every stored price for export
+func (s *SQLiteStore) allPrices() []models.PricePoint {
+	points, err := s.queryPrices("SELECT symbol, date, price, source FROM prices ORDER BY symbol, date")
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return []models.PricePoint{}
+	}
+	return points
+}
+
+func (s *SQLiteStore) queryPrices(query string, args ...interface{}) ([]models.PricePoint, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prices: %w", err)
+	}
+	defer rows.Close()
+
+	points := []models.PricePoint{}
+	for rows.Next() {
+		var p models.PricePoint
+		if err := rows.Scan(&p.Symbol, &p.Date, &p.Price, &p.Source); err != nil {
+			return nil, fmt.Errorf("failed to read price: %w", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// upsertPrices inserts or overwrites prices by symbol and date
+func upsertPrices(e execer, points []models.PricePoint) error {
+	for _, p := range points {
+		_, err := e.Exec(`INSERT INTO prices (symbol, date, price, source) VALUES (?, ?, ?, ?)
+			ON CONFLICT (symbol, date) DO UPDATE SET price = excluded.price, source = excluded.source`,
+			p.Symbol, p.Date, p.Price, p.Source)
+		if err != nil {
+			return fmt.Errorf("failed to store price %s@%s: %w", p.Symbol, p.Date, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"finance-tracker/internal/models"
+	"finance-tracker/internal/tags"
+)
+
+// Query describes filtering, sorting and pagination for the list endpoints.
+// Zero values mean "no constraint"; Limit 0 returns every match.
+type Query struct {
+	From          string   // Inclusive start date (YYYY-MM-DD)
+	To            string   // Inclusive end date (YYYY-MM-DD)
+	Category      string   // Expense/income category, or investment type
+	AddedBy       string   // Member name (expenses and incomes)
+	PaymentMethod string   // Payment method (expenses and incomes)
+	AccountID     string   // Account (expenses and incomes)
+	MinAmount     *float64 // Amount (or invested amount) lower bound
+	MaxAmount     *float64 // Amount (or invested amount) upper bound
+	Search        string   // Case-insensitive match on Desc, Source or Name
+	Tags          []string // Tags every match carries, ignoring case
+	SortBy        string   // One of SortFields; empty keeps insertion order
+	SortDesc      bool     // Sort descending
+	Limit         int      // Page size, 0 for no limit
+	Offset        int      // Number of matches to skip
+}
+
+// SortFields lists the accepted Query.SortBy values
+var SortFields = []string{"date", "amount", "category", "addedBy", "paymentMethod", "name", "createdAt", "updatedAt"}
+
+// Validate checks the query for unsupported values
+func (q Query) Validate() error {
+	if q.SortBy != "" && !contains(SortFields, q.SortBy) {
+		return fmt.Errorf("unsupported sort field %q (expected one of %s)", q.SortBy, strings.Join(SortFields, ", "))
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return fmt.Errorf("limit and offset cannot be negative")
+	}
+	if q.MinAmount != nil && q.MaxAmount != nil && *q.MinAmount > *q.MaxAmount {
+		return fmt.Errorf("minAmount cannot be greater than maxAmount")
+	}
+	if q.From != "" && q.To != "" && q.From > q.To {
+		return fmt.Errorf("from date cannot be after to date")
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ----- IN-MEMORY MATCHING (DataStore) -----
+
+func (q Query) matchDate(date string) bool {
+	if q.From != "" && date < q.From {
+		return false
+	}
+	if q.To != "" && date > q.toBound() {
+		return false
+	}
+	return true
+}
+
+// toBound is the upper bound for date comparisons. Dates may carry a time
+// part ("2025-01-31T10:00"), which must still fall on or before To.
+func (q Query) toBound() string {
+	return q.To + "~"
+}
+
+func (q Query) matchAmount(amount float64) bool {
+	if q.MinAmount != nil && amount < *q.MinAmount {
+		return false
+	}
+	if q.MaxAmount != nil && amount > *q.MaxAmount {
+		return false
+	}
+	return true
+}
+
+func (q Query) matchText(field string) bool {
+	return q.Search == "" || strings.Contains(strings.ToLower(field), strings.ToLower(q.Search))
+}
+
+func matchEqual(want, got string) bool {
+	return want == "" || want == got
+}
+
+func (q Query) matchExpense(exp models.Expense) bool {
+	return q.matchDate(exp.Date) && q.matchAmount(exp.Amount) && q.matchText(exp.Desc) &&
+		matchEqual(q.Category, exp.Category) && matchEqual(q.AddedBy, exp.AddedBy) &&
+		matchEqual(q.PaymentMethod, exp.PaymentMethod) && matchEqual(q.AccountID, exp.AccountID) &&
+		tags.HasAll(exp.Tags, q.Tags)
+}
+
+func (q Query) matchIncome(inc models.Income) bool {
+	return q.matchDate(inc.Date) && q.matchAmount(inc.Amount) && q.matchText(inc.Source) &&
+		matchEqual(q.Category, inc.Category) && matchEqual(q.AddedBy, inc.AddedBy) &&
+		matchEqual(q.PaymentMethod, inc.PaymentMethod) && matchEqual(q.AccountID, inc.AccountID) &&
+		tags.HasAll(inc.Tags, q.Tags)
+}
+
+func (q Query) matchInvestment(inv models.Investment) bool {
+	return q.matchDate(inv.Date) && q.matchAmount(inv.Invested) && q.matchText(inv.Name) &&
+		matchEqual(q.Category, inv.Type) && tags.HasAll(inv.Tags, q.Tags)
+}
+
+// sortKey returns the value compared for q.SortBy; string and float keys are
+// never mixed for one field
+type sortKey struct {
+	s string
+	f float64
+}
+
+func expenseSortKey(field string, exp models.Expense) sortKey {
+	switch field {
+	case "date":
+		return sortKey{s: exp.Date}
+	case "amount":
+		return sortKey{f: exp.Amount}
+	case "category":
+		return sortKey{s: strings.ToLower(exp.Category)}
+	case "addedBy":
+		return sortKey{s: strings.ToLower(exp.AddedBy)}
+	case "paymentMethod":
+		return sortKey{s: strings.ToLower(exp.PaymentMethod)}
+	case "name":
+		return sortKey{s: strings.ToLower(exp.Desc)}
+	case "createdAt":
+		return sortKey{s: exp.CreatedAt}
+	case "updatedAt":
+		return sortKey{s: exp.UpdatedAt}
+	default:
+		return sortKey{}
+	}
+}
+
+func incomeSortKey(field string, inc models.Income) sortKey {
+	switch field {
+	case "date":
+		return sortKey{s: inc.Date}
+	case "amount":
+		return sortKey{f: inc.Amount}
+	case "category":
+		return sortKey{s: strings.ToLower(inc.Category)}
+	case "addedBy":
+		return sortKey{s: strings.ToLower(inc.AddedBy)}
+	case "paymentMethod":
+		return sortKey{s: strings.ToLower(inc.PaymentMethod)}
+	case "name":
+		return sortKey{s: strings.ToLower(inc.Source)}
+	case "createdAt":
+		return sortKey{s: inc.CreatedAt}
+	case "updatedAt":
+		return sortKey{s: inc.UpdatedAt}
+	default:
+		return sortKey{}
+	}
+}
+
+func investmentSortKey(field string, inv models.Investment) sortKey {
+	switch field {
+	case "date":
+		return sortKey{s: inv.Date}
+	case "amount":
+		return sortKey{f: inv.Invested}
+	case "category":
+		return sortKey{s: strings.ToLower(inv.Type)}
+	case "name":
+		return sortKey{s: strings.ToLower(inv.Name)}
+	case "createdAt":
+		return sortKey{s: inv.CreatedAt}
+	case "updatedAt":
+		return sortKey{s: inv.UpdatedAt}
+	default:
+		// addedBy/paymentMethod do not apply to investments
+		return sortKey{}
+	}
+}
+
+// filterSortPage applies q to items and returns the requested page and the
+// total number of matches. Sorting is stable, so ties keep insertion order.
+func filterSortPage[T any](items []T, q Query, match func(T) bool, key func(string, T) sortKey) ([]T, int) {
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		if match(item) {
+			matched = append(matched, item)
+		}
+	}
+
+	if q.SortBy != "" {
+		sort.SliceStable(matched, func(i, j int) bool {
+			a, b := key(q.SortBy, matched[i]), key(q.SortBy, matched[j])
+			if q.SortDesc {
+				a, b = b, a
+			}
+			if a.s != b.s {
+				return a.s < b.s
+			}
+			return a.f < b.f
+		})
+	}
+
+	total := len(matched)
+	if q.Offset >= total {
+		return matched[:0], total
+	}
+	matched = matched[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(matched) {
+		matched = matched[:q.Limit]
+	}
+	return matched, total
+}
@@ -0,0 +1,55 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// recurringRuleDocs stores recurring rules (see docKind)
+var recurringRuleDocs = docKind[models.RecurringRule]{
+	noun:       "recurring rule",
+	collection: recurringCollection,
+	table:      "recurring_rules",
+	slice:      func(ds *DataStore) *[]models.RecurringRule { return &ds.recurring },
+	id:         func(rule models.RecurringRule) string { return rule.ID },
+	validate:   (*models.RecurringRule).Validate,
+}
+
+// ----- DataStore -----
+
+// GetRecurringRules returns all recurring rules
+func (ds *DataStore) GetRecurringRules() []models.RecurringRule { return recurringRuleDocs.dsGet(ds) }
+
+// AddRecurringRule adds a new recurring rule
+func (ds *DataStore) AddRecurringRule(rule models.RecurringRule) error {
+	return recurringRuleDocs.dsAdd(ds, rule)
+}
+
+// UpdateRecurringRule updates an existing recurring rule
+func (ds *DataStore) UpdateRecurringRule(id string, updated models.RecurringRule) error {
+	return recurringRuleDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteRecurringRule removes a recurring rule
+func (ds *DataStore) DeleteRecurringRule(id string) error { return recurringRuleDocs.dsDelete(ds, id) }
+
+// SaveRecurringRules writes recurring rules to file
+func (ds *DataStore) SaveRecurringRules() error { return recurringRuleDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetRecurringRules returns all recurring rules
+func (s *SQLiteStore) GetRecurringRules() []models.RecurringRule { return recurringRuleDocs.sqlGet(s) }
+
+// AddRecurringRule adds a new recurring rule
+func (s *SQLiteStore) AddRecurringRule(rule models.RecurringRule) error {
+	return recurringRuleDocs.sqlAdd(s, rule)
+}
+
+// UpdateRecurringRule updates an existing recurring rule
+func (s *SQLiteStore) UpdateRecurringRule(id string, updated models.RecurringRule) error {
+	return recurringRuleDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteRecurringRule removes a recurring rule
+func (s *SQLiteStore) DeleteRecurringRule(id string) error { return recurringRuleDocs.sqlDelete(s, id) }
+
+// SaveRecurringRules is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveRecurringRules() error { return nil }
@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// ruleDocs stores rules (see docKind)
+var ruleDocs = docKind[models.Rule]{
+	noun:       "rule",
+	collection: rulesCollection,
+	table:      "rules",
+	slice:      func(ds *DataStore) *[]models.Rule { return &ds.rules },
+	id:         func(rule models.Rule) string { return rule.ID },
+	validate:   (*models.Rule).Validate,
+}
+
+// ----- DataStore -----
+
+// GetRules returns all rules
+func (ds *DataStore) GetRules() []models.Rule { return ruleDocs.dsGet(ds) }
+
+// AddRule adds a new rule
+func (ds *DataStore) AddRule(rule models.Rule) error { return ruleDocs.dsAdd(ds, rule) }
+
+// UpdateRule updates an existing rule
+func (ds *DataStore) UpdateRule(id string, updated models.Rule) error {
+	return ruleDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteRule removes a rule
+func (ds *DataStore) DeleteRule(id string) error { return ruleDocs.dsDelete(ds, id) }
+
+// SaveRules writes rules to file
+func (ds *DataStore) SaveRules() error { return ruleDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetRules returns all rules
+func (s *SQLiteStore) GetRules() []models.Rule { return ruleDocs.sqlGet(s) }
+
+// AddRule adds a new rule
+func (s *SQLiteStore) AddRule(rule models.Rule) error { return ruleDocs.sqlAdd(s, rule) }
+
+// UpdateRule updates an existing rule
+func (s *SQLiteStore) UpdateRule(id string, updated models.Rule) error {
+	return ruleDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteRule removes a rule
+func (s *SQLiteStore) DeleteRule(id string) error { return ruleDocs.sqlDelete(s, id) }
+
+// SaveRules is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveRules() error { return nil }
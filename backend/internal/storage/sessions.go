@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// sessionDocs stores sessions (see docKind)
+var sessionDocs = docKind[models.Session]{
+	noun:       "session",
+	collection: sessionsCollection,
+	table:      "sessions",
+	slice:      func(ds *DataStore) *[]models.Session { return &ds.sessions },
+	id:         func(sess models.Session) string { return sess.ID },
+	validate:   (*models.Session).Validate,
+}
+
+// ----- DataStore -----
+
+// GetSessions returns all sessions
+func (ds *DataStore) GetSessions() []models.Session { return sessionDocs.dsGet(ds) }
+
+// AddSession adds a new session
+func (ds *DataStore) AddSession(sess models.Session) error { return sessionDocs.dsAdd(ds, sess) }
+
+// UpdateSession updates an existing session
+func (ds *DataStore) UpdateSession(id string, updated models.Session) error {
+	return sessionDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteSession removes a session
+func (ds *DataStore) DeleteSession(id string) error { return sessionDocs.dsDelete(ds, id) }
+
+// SaveSessions writes sessions to file
+func (ds *DataStore) SaveSessions() error { return sessionDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetSessions returns all sessions
+func (s *SQLiteStore) GetSessions() []models.Session { return sessionDocs.sqlGet(s) }
+
+// AddSession adds a new session
+func (s *SQLiteStore) AddSession(sess models.Session) error { return sessionDocs.sqlAdd(s, sess) }
+
+// UpdateSession updates an existing session
+func (s *SQLiteStore) UpdateSession(id string, updated models.Session) error {
+	return sessionDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteSession removes a session
+func (s *SQLiteStore) DeleteSession(id string) error { return sessionDocs.sqlDelete(s, id) }
+
+// SaveSessions is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveSessions() error { return nil }
@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// settlementDocs stores settlements (see docKind)
+var settlementDocs = docKind[models.Settlement]{
+	noun:       "settlement",
+	collection: settlementsCollection,
+	table:      "settlements",
+	slice:      func(ds *DataStore) *[]models.Settlement { return &ds.settlements },
+	id:         func(st models.Settlement) string { return st.ID },
+	validate:   (*models.Settlement).Validate,
+}
+
+// ----- DataStore -----
+
+// GetSettlements returns all settlements
+func (ds *DataStore) GetSettlements() []models.Settlement { return settlementDocs.dsGet(ds) }
+
+// AddSettlement adds a new settlement
+func (ds *DataStore) AddSettlement(st models.Settlement) error { return settlementDocs.dsAdd(ds, st) }
+
+// UpdateSettlement updates an existing settlement
+func (ds *DataStore) UpdateSettlement(id string, updated models.Settlement) error {
+	return settlementDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteSettlement removes a settlement
+func (ds *DataStore) DeleteSettlement(id string) error { return settlementDocs.dsDelete(ds, id) }
+
+// SaveSettlements writes settlements to file
+func (ds *DataStore) SaveSettlements() error { return settlementDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetSettlements returns all settlements
+func (s *SQLiteStore) GetSettlements() []models.Settlement { return settlementDocs.sqlGet(s) }
+
+// AddSettlement adds a new settlement
+func (s *SQLiteStore) AddSettlement(st models.Settlement) error { return settlementDocs.sqlAdd(s, st) }
+
+// UpdateSettlement updates an existing settlement
+func (s *SQLiteStore) UpdateSettlement(id string, updated models.Settlement) error {
+	return settlementDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteSettlement removes a settlement
+func (s *SQLiteStore) DeleteSettlement(id string) error { return settlementDocs.sqlDelete(s, id) }
+
+// SaveSettlements is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveSettlements() error { return nil }
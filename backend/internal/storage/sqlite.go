@@ -0,0 +1,725 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite" // pure-Go driver, registers "sqlite"
+
+	"finance-tracker/internal/models"
+)
+
+// sqliteFileName is the database file created inside the data directory
+const sqliteFileName = "finance.db"
+
+// SQLiteStore implements Storage on an embedded SQLite database.
+// Every mutation is written immediately, so the Save* methods are no-ops.
+type SQLiteStore struct {
+	db      *sql.DB
+	dataDir string
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// NewSQLiteStore opens (or creates) the database in dataDir, applies the
+// schema and imports any JSON data files left by the file-based store
+func NewSQLiteStore(dataDir string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	dsn := "file:" + filepath.Join(dataDir, sqliteFileName) +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; serialising here avoids SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &SQLiteStore{db: db, dataDir: dataDir}
+	if err := s.migrateFromJSON(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := s.migrateFlatInvestments(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// migrateFromJSON performs the one-shot import of an existing JSON data
+// directory. It is recorded in the meta table so it never runs twice.
+func (s *SQLiteStore) migrateFromJSON() error {
+	var done string
+	err := s.db.QueryRow("SELECT value FROM meta WHERE key = 'json_migrated'").Scan(&done)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read migration state: %w", err)
+	}
+
+	found := false
+	for _, name := range []string{"investments.json", "incomes.json", "expenses.json", "settings.json"} {
+		if _, err := os.Stat(filepath.Join(s.dataDir, name)); err == nil {
+			found = true
+			break
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin JSON migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	if found {
+		ds, err := NewDataStore(s.dataDir)
+		if err != nil {
+			return fmt.Errorf("failed to read JSON data for migration: %w", err)
+		}
+		data := ds.GetExportData()
+		ds.Close()
+		if err := importTx(tx, data); err != nil {
+			return fmt.Errorf("failed to migrate JSON data: %w", err)
+		}
+		log.Printf("Migrated %d investments, %d incomes, %d expenses from %s",
+			len(data.Investments), len(data.Incomes), len(data.Expenses), s.dataDir)
+	}
+
+	if _, err := tx.Exec("INSERT INTO meta (key, value) VALUES ('json_migrated', datetime('now'))"); err != nil {
+		return fmt.Errorf("failed to record JSON migration: %w", err)
+	}
+	return tx.Commit()
+}
+
+// SaveInvestments is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveInvestments() error { return nil }
+
+// SaveIncomes is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveIncomes() error { return nil }
+
+// SaveExpenses is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveExpenses() error { return nil }
+
+// SaveSettings is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveSettings() error { return nil }
+
+// ----- INVESTMENTS -----
+
+const investmentColumns = "id, name, type, invested, current, date, scheme_code, units, " +
+	"total_invested, realized_gain, transactions, asset_class, grandfathered_fmv, deposit, tags, created_at, updated_at"
+
+func scanInvestments(rows *sql.Rows) ([]models.Investment, error) {
+	defer rows.Close()
+	investments := []models.Investment{}
+	for rows.Next() {
+		var inv models.Investment
+		var txns, deposit, tags string
+		if err := rows.Scan(&inv.ID, &inv.Name, &inv.Type, &inv.Invested, &inv.Current, &inv.Date,
+			&inv.SchemeCode, &inv.Units, &inv.TotalInvested, &inv.RealizedGain, &txns,
+			&inv.AssetClass, &inv.GrandfatheredFMV, &deposit, &tags, &inv.CreatedAt, &inv.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(txns), &inv.Transactions); err != nil {
+			return nil, fmt.Errorf("failed to decode transactions of investment %s: %w", inv.ID, err)
+		}
+		if deposit != "" {
+			if err := json.Unmarshal([]byte(deposit), &inv.Deposit); err != nil {
+				return nil, fmt.Errorf("failed to decode deposit terms of investment %s: %w", inv.ID, err)
+			}
+		}
+		if err := unmarshalTags(tags, &inv.Tags); err != nil {
+			return nil, fmt.Errorf("investment %s: %w", inv.ID, err)
+		}
+		investments = append(investments, inv)
+	}
+	return investments, rows.Err()
+}
+
+func insertInvestment(e execer, inv models.Investment) error {
+	inv.EnsureTransactions()
+	txns, err := marshalTransactions(inv.Transactions)
+	if err != nil {
+		return err
+	}
+	deposit, err := marshalDeposit(inv.Deposit)
+	if err != nil {
+		return err
+	}
+	_, err = e.Exec("INSERT INTO investments ("+investmentColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		inv.ID, inv.Name, inv.Type, inv.Invested, inv.Current, inv.Date,
+		inv.SchemeCode, inv.Units, inv.TotalInvested, inv.RealizedGain, txns,
+		inv.AssetClass, inv.GrandfatheredFMV, deposit, marshalTags(inv.Tags), inv.CreatedAt, inv.UpdatedAt)
+	return err
+}
+
+// marshalDeposit encodes deposit terms; holdings without them store an empty string
+func marshalDeposit(d *models.Deposit) (string, error) {
+	if d == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode deposit terms: %w", err)
+	}
+	return string(data), nil
+}
+
+func marshalTransactions(txns []models.InvestmentTransaction) (string, error) {
+	if txns == nil {
+		txns = []models.InvestmentTransaction{}
+	}
+	data, err := json.Marshal(txns)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transactions: %w", err)
+	}
+	return string(data), nil
+}
+
+// migrateFlatInvestments gives investments stored before transactions
+// existed their opening transaction. One that cannot be migrated is left
+// flat with a warning rather than stopping the server from starting.
+func (s *SQLiteStore) migrateFlatInvestments() error {
+	rows, err := s.db.Query("SELECT " + investmentColumns + " FROM investments WHERE transactions = '[]'")
+	if err != nil {
+		return fmt.Errorf("failed to query flat investments: %w", err)
+	}
+	flat, err := scanInvestments(rows)
+	if err != nil {
+		return fmt.Errorf("failed to read flat investments: %w", err)
+	}
+	migrated := 0
+	for _, inv := range flat {
+		if !inv.NeedsTransactions() {
+			continue
+		}
+		if !inv.EnsureTransactions() {
+			log.Printf("Warning: Investment %s (%s) left without an opening transaction: date %q is not YYYY-MM-DD or DD/MM/YYYY", inv.ID, inv.Name, inv.Date)
+			continue
+		}
+		if err := s.UpdateInvestment(inv.ID, inv); err != nil {
+			log.Printf("Warning: Failed to migrate investment %s (%s): %v", inv.ID, inv.Name, err)
+			continue
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		log.Printf("Migrated %d investments to opening transactions", migrated)
+	}
+	return nil
+}
+
+// GetInvestments returns all investments
+func (s *SQLiteStore) GetInvestments() []models.Investment {
+	rows, err := s.db.Query("SELECT " + investmentColumns + " FROM investments ORDER BY rowid")
+	if err != nil {
+		log.Printf("Warning: Failed to query investments: %v", err)
+		return []models.Investment{}
+	}
+	investments, err := scanInvestments(rows)
+	if err != nil {
+		log.Printf("Warning: Failed to read investments: %v", err)
+		return []models.Investment{}
+	}
+	return investments
+}
+
+// AddInvestment adds a new investment
+func (s *SQLiteStore) AddInvestment(inv models.Investment) error {
+	inv.EnsureTransactions()
+	if err := inv.Validate(); err != nil {
+		return fmt.Errorf("invalid investment: %w", err)
+	}
+	if err := insertInvestment(s.db, inv); err != nil {
+		return fmt.Errorf("failed to insert investment: %w", err)
+	}
+	return nil
+}
+
+// UpdateInvestment updates an existing investment
+func (s *SQLiteStore) UpdateInvestment(id string, updated models.Investment) error {
+	return updateInvestment(s.db, id, updated)
+}
+
+// ModifyInvestment applies change to an investment read in the same
+// database transaction as it is written
+func (s *SQLiteStore) ModifyInvestment(id string, change func(*models.Investment) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin investment update: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT "+investmentColumns+" FROM investments WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to query investment: %w", err)
+	}
+	found, err := scanInvestments(rows)
+	if err != nil {
+		return fmt.Errorf("failed to read investment: %w", err)
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("investment %w", ErrNotFound)
+	}
+	inv := found[0]
+	if err := change(&inv); err != nil {
+		return err
+	}
+	if err := updateInvestment(tx, id, inv); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func updateInvestment(e execer, id string, updated models.Investment) error {
+	updated.EnsureTransactions()
+	if err := updated.Validate(); err != nil {
+		return fmt.Errorf("invalid investment: %w", err)
+	}
+	txns, err := marshalTransactions(updated.Transactions)
+	if err != nil {
+		return err
+	}
+	deposit, err := marshalDeposit(updated.Deposit)
+	if err != nil {
+		return err
+	}
+	res, err := e.Exec(`UPDATE investments SET name = ?, type = ?, invested = ?, current = ?, date = ?,
+		scheme_code = ?, units = ?, total_invested = ?, realized_gain = ?, transactions = ?,
+		asset_class = ?, grandfathered_fmv = ?, deposit = ?, tags = ?, created_at = ?, updated_at = ? WHERE id = ?`,
+		updated.Name, updated.Type, updated.Invested, updated.Current, updated.Date,
+		updated.SchemeCode, updated.Units, updated.TotalInvested, updated.RealizedGain, txns,
+		updated.AssetClass, updated.GrandfatheredFMV, deposit, marshalTags(updated.Tags), updated.CreatedAt, updated.UpdatedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update investment: %w", err)
+	}
+	return requireRow(res, "investment not found")
+}
+
+// DeleteInvestment removes an investment
+func (s *SQLiteStore) DeleteInvestment(id string) error {
+	res, err := s.db.Exec("DELETE FROM investments WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete investment: %w", err)
+	}
+	return requireRow(res, "investment not found")
+}
+
+// ----- INCOMES -----
+
+const incomeColumns = "id, source, amount, category, date, added_by, payment_method, account_id, fit_id, tags, created_at, updated_at"
+
+func scanIncomes(rows *sql.Rows) ([]models.Income, error) {
+	defer rows.Close()
+	incomes := []models.Income{}
+	for rows.Next() {
+		var inc models.Income
+		var tags string
+		if err := rows.Scan(&inc.ID, &inc.Source, &inc.Amount, &inc.Category, &inc.Date,
+			&inc.AddedBy, &inc.PaymentMethod, &inc.AccountID, &inc.FITID, &tags, &inc.CreatedAt, &inc.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := unmarshalTags(tags, &inc.Tags); err != nil {
+			return nil, fmt.Errorf("income %s: %w", inc.ID, err)
+		}
+		incomes = append(incomes, inc)
+	}
+	return incomes, rows.Err()
+}
+
+func insertIncome(e execer, inc models.Income) error {
+	_, err := e.Exec("INSERT INTO incomes ("+incomeColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		inc.ID, inc.Source, inc.Amount, inc.Category, inc.Date,
+		inc.AddedBy, inc.PaymentMethod, inc.AccountID, inc.FITID, marshalTags(inc.Tags), inc.CreatedAt, inc.UpdatedAt)
+	return err
+}
+
+// GetIncomes returns all incomes
+func (s *SQLiteStore) GetIncomes() []models.Income {
+	rows, err := s.db.Query("SELECT " + incomeColumns + " FROM incomes ORDER BY rowid")
+	if err != nil {
+		log.Printf("Warning: Failed to query incomes: %v", err)
+		return []models.Income{}
+	}
+	incomes, err := scanIncomes(rows)
+	if err != nil {
+		log.Printf("Warning: Failed to read incomes: %v", err)
+		return []models.Income{}
+	}
+	return incomes
+}
+
+// AddIncome adds a new income
+func (s *SQLiteStore) AddIncome(inc models.Income) error {
+	if err := inc.Validate(); err != nil {
+		return fmt.Errorf("invalid income: %w", err)
+	}
+	if err := insertIncome(s.db, inc); err != nil {
+		return fmt.Errorf("failed to insert income: %w", err)
+	}
+	return nil
+}
+
+// UpdateIncome updates an existing income
+func (s *SQLiteStore) UpdateIncome(id string, updated models.Income) error {
+	if err := updated.Validate(); err != nil {
+		return fmt.Errorf("invalid income: %w", err)
+	}
+	res, err := s.db.Exec(`UPDATE incomes SET source = ?, amount = ?, category = ?, date = ?,
+		added_by = ?, payment_method = ?, account_id = ?, fit_id = ?, tags = ?, created_at = ?, updated_at = ? WHERE id = ?`,
+		updated.Source, updated.Amount, updated.Category, updated.Date,
+		updated.AddedBy, updated.PaymentMethod, updated.AccountID, updated.FITID, marshalTags(updated.Tags), updated.CreatedAt, updated.UpdatedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update income: %w", err)
+	}
+	return requireRow(res, "income not found")
+}
+
+// DeleteIncome removes an income
+func (s *SQLiteStore) DeleteIncome(id string) error {
+	res, err := s.db.Exec("DELETE FROM incomes WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete income: %w", err)
+	}
+	return requireRow(res, "income not found")
+}
+
+// marshalTags encodes tags as a JSON array; records without tags store an
+// empty string
+func marshalTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(tags)
+	return string(data)
+}
+
+func unmarshalTags(s string, tags *[]string) error {
+	if s == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(s), tags); err != nil {
+		return fmt.Errorf("failed to decode tags: %w", err)
+	}
+	return nil
+}
+
+// ----- EXPENSES -----
+
+const expenseColumns = "id, description, amount, category, date, added_by, payment_method, " +
+	"loan_id, instalment, account_id, fit_id, tags, splits, paid_by, share_mode, shared_among, created_at, updated_at"
+
+func scanExpenses(rows *sql.Rows) ([]models.Expense, error) {
+	defer rows.Close()
+	expenses := []models.Expense{}
+	for rows.Next() {
+		var exp models.Expense
+		var tags, splits, shares string
+		if err := rows.Scan(&exp.ID, &exp.Desc, &exp.Amount, &exp.Category, &exp.Date,
+			&exp.AddedBy, &exp.PaymentMethod, &exp.LoanID, &exp.Instalment, &exp.AccountID,
+			&exp.FITID, &tags, &splits, &exp.PaidBy, &exp.ShareMode, &shares, &exp.CreatedAt, &exp.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := unmarshalTags(tags, &exp.Tags); err != nil {
+			return nil, fmt.Errorf("expense %s: %w", exp.ID, err)
+		}
+		if splits != "" {
+			if err := json.Unmarshal([]byte(splits), &exp.Splits); err != nil {
+				return nil, fmt.Errorf("failed to decode splits of expense %s: %w", exp.ID, err)
+			}
+		}
+		if shares != "" {
+			if err := json.Unmarshal([]byte(shares), &exp.SharedAmong); err != nil {
+				return nil, fmt.Errorf("failed to decode shares of expense %s: %w", exp.ID, err)
+			}
+		}
+		expenses = append(expenses, exp)
+	}
+	return expenses, rows.Err()
+}
+
+func insertExpense(e execer, exp models.Expense) error {
+	_, err := e.Exec("INSERT INTO expenses ("+expenseColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		exp.ID, exp.Desc, exp.Amount, exp.Category, exp.Date,
+		exp.AddedBy, exp.PaymentMethod, exp.LoanID, exp.Instalment, exp.AccountID,
+		exp.FITID, marshalTags(exp.Tags), marshalSplits(exp.Splits),
+		exp.PaidBy, exp.ShareMode, marshalShares(exp.SharedAmong), exp.CreatedAt, exp.UpdatedAt)
+	return err
+}
+
+// marshalSplits encodes split lines; expenses that are not split store an
+// empty string
+func marshalSplits(splits []models.Split) string {
+	if len(splits) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(splits)
+	return string(data)
+}
+
+// marshalShares encodes the shares of a shared expense; others store an
+// empty string
+func marshalShares(shares []models.Share) string {
+	if len(shares) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(shares)
+	return string(data)
+}
+
+// GetExpenses returns all expenses
+func (s *SQLiteStore) GetExpenses() []models.Expense {
+	rows, err := s.db.Query("SELECT " + expenseColumns + " FROM expenses ORDER BY rowid")
+	if err != nil {
+		log.Printf("Warning: Failed to query expenses: %v", err)
+		return []models.Expense{}
+	}
+	expenses, err := scanExpenses(rows)
+	if err != nil {
+		log.Printf("Warning: Failed to read expenses: %v", err)
+		return []models.Expense{}
+	}
+	return expenses
+}
+
+// AddExpense adds a new expense
+func (s *SQLiteStore) AddExpense(exp models.Expense) error {
+	if err := exp.Validate(); err != nil {
+		return fmt.Errorf("invalid expense: %w", err)
+	}
+	if err := insertExpense(s.db, exp); err != nil {
+		return fmt.Errorf("failed to insert expense: %w", err)
+	}
+	return nil
+}
+
+// UpdateExpense updates an existing expense
+func (s *SQLiteStore) UpdateExpense(id string, updated models.Expense) error {
+	if err := updated.Validate(); err != nil {
+		return fmt.Errorf("invalid expense: %w", err)
+	}
+	res, err := s.db.Exec(`UPDATE expenses SET description = ?, amount = ?, category = ?, date = ?,
+		added_by = ?, payment_method = ?, loan_id = ?, instalment = ?, account_id = ?,
+		fit_id = ?, tags = ?, splits = ?, paid_by = ?, share_mode = ?, shared_among = ?,
+		created_at = ?, updated_at = ? WHERE id = ?`,
+		updated.Desc, updated.Amount, updated.Category, updated.Date,
+		updated.AddedBy, updated.PaymentMethod, updated.LoanID, updated.Instalment, updated.AccountID,
+		updated.FITID, marshalTags(updated.Tags), marshalSplits(updated.Splits),
+		updated.PaidBy, updated.ShareMode, marshalShares(updated.SharedAmong), updated.CreatedAt, updated.UpdatedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update expense: %w", err)
+	}
+	return requireRow(res, "expense not found")
+}
+
+// DeleteExpense removes an expense
+func (s *SQLiteStore) DeleteExpense(id string) error {
+	res, err := s.db.Exec("DELETE FROM expenses WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete expense: %w", err)
+	}
+	return requireRow(res, "expense not found")
+}
+
+// ----- SETTINGS -----
+
+// GetSettings returns current settings
+func (s *SQLiteStore) GetSettings() models.Settings {
+	settings := defaultSettings()
+	var value string
+	err := s.db.QueryRow("SELECT value FROM settings WHERE key = 'settings'").Scan(&value)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Warning: Failed to read settings: %v", err)
+		}
+		return settings
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		log.Printf("Warning: Failed to decode settings: %v", err)
+	}
+	return settings
+}
+
+// UpdateSettings updates settings
+func (s *SQLiteStore) UpdateSettings(settings models.Settings) error {
+	return putSettings(s.db, settings)
+}
+
+func putSettings(e execer, settings models.Settings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if _, err := e.Exec(`INSERT INTO settings (key, value) VALUES ('settings', ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, string(data)); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
+
+// ----- EXPORT/IMPORT -----
+
+// GetExportData returns all data for export
+func (s *SQLiteStore) GetExportData() models.ExportData {
+	return models.ExportData{
+		Investments:       s.GetInvestments(),
+		Incomes:           s.GetIncomes(),
+		Expenses:          s.GetExpenses(),
+		Settings:          s.GetSettings(),
+		Budgets:           s.GetBudgets(),
+		Recurring:         s.GetRecurringRules(),
+		Prices:            s.allPrices(),
+		Chits:             s.GetChits(),
+		Loans:             s.GetLoans(),
+		Accounts:          s.GetAccounts(),
+		Transfers:         s.GetTransfers(),
+		StatementProfiles: s.GetStatementProfiles(),
+		Merges:            s.GetMerges(),
+		Rules:             s.GetRules(),
+		Settlements:       s.GetSettlements(),
+	}
+}
+
+// ImportData imports data from export, replacing each non-empty collection
+func (s *SQLiteStore) ImportData(data models.ExportData) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+	if err := importTx(tx, data); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// importTx mirrors DataStore.ImportData inside a transaction
+func importTx(tx *sql.Tx, data models.ExportData) error {
+	if len(data.Investments) > 0 {
+		if _, err := tx.Exec("DELETE FROM investments"); err != nil {
+			return fmt.Errorf("failed to clear investments: %w", err)
+		}
+		for _, inv := range data.Investments {
+			if err := insertInvestment(tx, inv); err != nil {
+				return fmt.Errorf("failed to import investment %s: %w", inv.ID, err)
+			}
+		}
+	}
+	if len(data.Incomes) > 0 {
+		if _, err := tx.Exec("DELETE FROM incomes"); err != nil {
+			return fmt.Errorf("failed to clear incomes: %w", err)
+		}
+		for _, inc := range data.Incomes {
+			if err := insertIncome(tx, inc); err != nil {
+				return fmt.Errorf("failed to import income %s: %w", inc.ID, err)
+			}
+		}
+	}
+	if len(data.Expenses) > 0 {
+		if _, err := tx.Exec("DELETE FROM expenses"); err != nil {
+			return fmt.Errorf("failed to clear expenses: %w", err)
+		}
+		for _, exp := range data.Expenses {
+			if err := insertExpense(tx, exp); err != nil {
+				return fmt.Errorf("failed to import expense %s: %w", exp.ID, err)
+			}
+		}
+	}
+	if len(data.Settings.Categories) > 0 {
+		if err := putSettings(tx, data.Settings); err != nil {
+			return err
+		}
+	}
+	if len(data.Budgets) > 0 {
+		if err := replaceDocs(tx, "budgets", data.Budgets, func(b models.Budget) string { return b.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.Recurring) > 0 {
+		if err := replaceDocs(tx, "recurring_rules", data.Recurring, func(v models.RecurringRule) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.Prices) > 0 {
+		if _, err := tx.Exec("DELETE FROM prices"); err != nil {
+			return fmt.Errorf("failed to clear prices: %w", err)
+		}
+		if err := upsertPrices(tx, data.Prices); err != nil {
+			return err
+		}
+	}
+	if len(data.Chits) > 0 {
+		if err := replaceDocs(tx, "chits", data.Chits, func(v models.Chit) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.Loans) > 0 {
+		if err := replaceDocs(tx, "loans", data.Loans, func(v models.Loan) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.Accounts) > 0 {
+		if err := replaceDocs(tx, "accounts", data.Accounts, func(v models.Account) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.Transfers) > 0 {
+		if err := replaceDocs(tx, "transfers", data.Transfers, func(v models.Transfer) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.StatementProfiles) > 0 {
+		if err := replaceDocs(tx, "statement_profiles", data.StatementProfiles, func(v models.StatementProfile) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.Merges) > 0 {
+		if err := replaceDocs(tx, "merges", data.Merges, func(v models.Merge) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.Rules) > 0 {
+		if err := replaceDocs(tx, "rules", data.Rules, func(v models.Rule) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	if len(data.Settlements) > 0 {
+		if err := replaceDocs(tx, "settlements", data.Settlements, func(v models.Settlement) string { return v.ID }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireRow turns "no rows affected" into a not-found error
+func requireRow(res sql.Result, notFound string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New(notFound)
+	}
+	return nil
+}
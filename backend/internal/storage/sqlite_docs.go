@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Document tables store each record as JSON under its ID. Rows come back in
+// insertion order, matching the slices kept by DataStore.
+
+// listDocs decodes every row of table
+func listDocs[T any](s *SQLiteStore, table string) ([]T, error) {
+	rows, err := s.db.Query("SELECT data FROM " + table + " ORDER BY rowid")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		var item T
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil, fmt.Errorf("failed to decode %s row: %w", table, err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// insertDoc adds a new row
+func insertDoc(e execer, table, id string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s row: %w", table, err)
+	}
+	if _, err := e.Exec("INSERT INTO "+table+" (id, data) VALUES (?, ?)", id, string(data)); err != nil {
+		return fmt.Errorf("failed to insert into %s: %w", table, err)
+	}
+	return nil
+}
+
+// updateDoc replaces an existing row, returning notFound if there is none
+func updateDoc(e execer, table, id string, v interface{}, notFound string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s row: %w", table, err)
+	}
+	res, err := e.Exec("UPDATE "+table+" SET data = ? WHERE id = ?", string(data), id)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", table, err)
+	}
+	return requireRow(res, notFound)
+}
+
+// deleteDoc removes a row, returning notFound if there is none
+func deleteDoc(e execer, table, id, notFound string) error {
+	res, err := e.Exec("DELETE FROM "+table+" WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete from %s: %w", table, err)
+	}
+	return requireRow(res, notFound)
+}
+
+// replaceDocs swaps the whole table for items (used by import)
+func replaceDocs[T any](e execer, table string, items []T, idOf func(T) string) error {
+	if _, err := e.Exec("DELETE FROM " + table); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", table, err)
+	}
+	for _, item := range items {
+		if err := insertDoc(e, table, idOf(item), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"finance-tracker/internal/models"
+)
+
+// queryColumns maps the Query fields onto one table's columns.
+// Empty names mark filters that do not apply to the table.
+type queryColumns struct {
+	table         string
+	amount        string
+	category      string
+	addedBy       string
+	paymentMethod string
+	accountID     string
+	text          string
+}
+
+var (
+	investmentQueryColumns = queryColumns{table: "investments", amount: "invested", category: "type", text: "name"}
+	incomeQueryColumns     = queryColumns{table: "incomes", amount: "amount", category: "category", addedBy: "added_by", paymentMethod: "payment_method", accountID: "account_id", text: "source"}
+	expenseQueryColumns    = queryColumns{table: "expenses", amount: "amount", category: "category", addedBy: "added_by", paymentMethod: "payment_method", accountID: "account_id", text: "description"}
+)
+
+// sqlWhere builds the WHERE clause (including the keyword, or empty) for q
+func (q Query) sqlWhere(c queryColumns) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	if q.From != "" {
+		conds = append(conds, "date >= ?")
+		args = append(args, q.From)
+	}
+	if q.To != "" {
+		conds = append(conds, "date <= ?")
+		args = append(args, q.toBound())
+	}
+	if q.Category != "" && c.category != "" {
+		conds = append(conds, c.category+" = ?")
+		args = append(args, q.Category)
+	}
+	if q.AddedBy != "" && c.addedBy != "" {
+		conds = append(conds, c.addedBy+" = ?")
+		args = append(args, q.AddedBy)
+	}
+	if q.PaymentMethod != "" && c.paymentMethod != "" {
+		conds = append(conds, c.paymentMethod+" = ?")
+		args = append(args, q.PaymentMethod)
+	}
+	if q.AccountID != "" && c.accountID != "" {
+		conds = append(conds, c.accountID+" = ?")
+		args = append(args, q.AccountID)
+	}
+	if q.MinAmount != nil {
+		conds = append(conds, c.amount+" >= ?")
+		args = append(args, *q.MinAmount)
+	}
+	if q.MaxAmount != nil {
+		conds = append(conds, c.amount+" <= ?")
+		args = append(args, *q.MaxAmount)
+	}
+	if q.Search != "" {
+		conds = append(conds, "lower("+c.text+") LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(strings.ToLower(q.Search))+"%")
+	}
+	// tags holds a JSON array, or '' for none
+	for _, tag := range q.Tags {
+		conds = append(conds, "EXISTS (SELECT 1 FROM json_each(nullif(tags, '')) WHERE lower(value) = ?)")
+		args = append(args, strings.ToLower(tag))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// sqlOrder builds the ORDER BY clause; rowid keeps ties in insertion order
+func (q Query) sqlOrder(c queryColumns) string {
+	var col string
+	switch q.SortBy {
+	case "date":
+		col = "date"
+	case "amount":
+		col = c.amount
+	case "category":
+		col = "lower(" + c.category + ")"
+	case "addedBy":
+		if c.addedBy != "" {
+			col = "lower(" + c.addedBy + ")"
+		}
+	case "paymentMethod":
+		if c.paymentMethod != "" {
+			col = "lower(" + c.paymentMethod + ")"
+		}
+	case "name":
+		col = "lower(" + c.text + ")"
+	case "createdAt":
+		col = "created_at"
+	case "updatedAt":
+		col = "updated_at"
+	}
+	if col == "" {
+		return " ORDER BY rowid"
+	}
+	dir := "ASC"
+	if q.SortDesc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf(" ORDER BY %s %s, rowid", col, dir)
+}
+
+// sqlLimit builds the LIMIT/OFFSET clause
+func (q Query) sqlLimit() string {
+	if q.Limit == 0 && q.Offset == 0 {
+		return ""
+	}
+	limit := q.Limit
+	if limit == 0 {
+		limit = -1 // SQLite needs a LIMIT before OFFSET
+	}
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, q.Offset)
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// runQuery counts the matches for q and returns the selected page's rows
+func (s *SQLiteStore) runQuery(q Query, c queryColumns, columns string) (*sql.Rows, int, error) {
+	if err := q.Validate(); err != nil {
+		return nil, 0, err
+	}
+	where, args := q.sqlWhere(c)
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM "+c.table+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count %s: %w", c.table, err)
+	}
+
+	rows, err := s.db.Query("SELECT "+columns+" FROM "+c.table+where+q.sqlOrder(c)+q.sqlLimit(), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query %s: %w", c.table, err)
+	}
+	return rows, total, nil
+}
+
+// QueryInvestments returns one page of investments matching q and the total match count
+func (s *SQLiteStore) QueryInvestments(q Query) ([]models.Investment, int, error) {
+	rows, total, err := s.runQuery(q, investmentQueryColumns, investmentColumns)
+	if err != nil {
+		return nil, 0, err
+	}
+	investments, err := scanInvestments(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read investments: %w", err)
+	}
+	return investments, total, nil
+}
+
+// QueryIncomes returns one page of incomes matching q and the total match count
+func (s *SQLiteStore) QueryIncomes(q Query) ([]models.Income, int, error) {
+	rows, total, err := s.runQuery(q, incomeQueryColumns, incomeColumns)
+	if err != nil {
+		return nil, 0, err
+	}
+	incomes, err := scanIncomes(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read incomes: %w", err)
+	}
+	return incomes, total, nil
+}
+
+// QueryExpenses returns one page of expenses matching q and the total match count
+func (s *SQLiteStore) QueryExpenses(q Query) ([]models.Expense, int, error) {
+	rows, total, err := s.runQuery(q, expenseQueryColumns, expenseColumns)
+	if err != nil {
+		return nil, 0, err
+	}
+	expenses, err := scanExpenses(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read expenses: %w", err)
+	}
+	return expenses, total, nil
+}
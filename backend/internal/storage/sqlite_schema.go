@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// sqliteMigrations holds the schema steps in order. The database records how
+// many have been applied in PRAGMA user_version, so new steps must only ever
+// be appended to this list.
+//
+// The core transaction tables have real columns so they can be filtered and
+// indexed. Smaller collections that are always read whole are document
+// tables: an ID plus the record as JSON (see sqlite_docs.go).
+var sqliteMigrations = []string{
+	// 1: initial schema
+	`
+	CREATE TABLE IF NOT EXISTS investments (
+		id          TEXT PRIMARY KEY,
+		name        TEXT NOT NULL,
+		type        TEXT NOT NULL,
+		invested    REAL NOT NULL DEFAULT 0,
+		current     REAL NOT NULL DEFAULT 0,
+		date        TEXT NOT NULL,
+		scheme_code TEXT NOT NULL DEFAULT '',
+		units       REAL NOT NULL DEFAULT 0,
+		created_at  TEXT NOT NULL DEFAULT '',
+		updated_at  TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_investments_date ON investments(date);
+	CREATE INDEX IF NOT EXISTS idx_investments_type ON investments(type);
+
+	CREATE TABLE IF NOT EXISTS incomes (
+		id             TEXT PRIMARY KEY,
+		source         TEXT NOT NULL,
+		amount         REAL NOT NULL,
+		category       TEXT NOT NULL,
+		date           TEXT NOT NULL,
+		added_by       TEXT NOT NULL,
+		payment_method TEXT NOT NULL DEFAULT '',
+		created_at     TEXT NOT NULL DEFAULT '',
+		updated_at     TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_incomes_date ON incomes(date);
+	CREATE INDEX IF NOT EXISTS idx_incomes_category ON incomes(category);
+	CREATE INDEX IF NOT EXISTS idx_incomes_added_by ON incomes(added_by);
+
+	CREATE TABLE IF NOT EXISTS expenses (
+		id             TEXT PRIMARY KEY,
+		description    TEXT NOT NULL,
+		amount         REAL NOT NULL,
+		category       TEXT NOT NULL,
+		date           TEXT NOT NULL,
+		added_by       TEXT NOT NULL,
+		payment_method TEXT NOT NULL DEFAULT '',
+		created_at     TEXT NOT NULL DEFAULT '',
+		updated_at     TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_expenses_date ON expenses(date);
+	CREATE INDEX IF NOT EXISTS idx_expenses_category ON expenses(category);
+	CREATE INDEX IF NOT EXISTS idx_expenses_added_by ON expenses(added_by);
+
+	CREATE TABLE IF NOT EXISTS settings (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS meta (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	`,
+	// 2: budgets
+	`CREATE TABLE IF NOT EXISTS budgets (id TEXT PRIMARY KEY, data TEXT NOT NULL);`,
+	// 3: recurring rules
+	`CREATE TABLE IF NOT EXISTS recurring_rules (id TEXT PRIMARY KEY, data TEXT NOT NULL);`,
+	// 4: price history, one row per symbol and day
+	`
+	CREATE TABLE IF NOT EXISTS prices (
+		symbol TEXT NOT NULL,
+		date   TEXT NOT NULL,
+		price  REAL NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (symbol, date)
+	);
+	`,
+	// 5: investment transactions; the flat columns become derived values
+	`
+	ALTER TABLE investments ADD COLUMN total_invested REAL NOT NULL DEFAULT 0;
+	ALTER TABLE investments ADD COLUMN realized_gain REAL NOT NULL DEFAULT 0;
+	ALTER TABLE investments ADD COLUMN transactions TEXT NOT NULL DEFAULT '[]';
+	`,
+	// 6: capital gains classification
+	`
+	ALTER TABLE investments ADD COLUMN asset_class TEXT NOT NULL DEFAULT '';
+	ALTER TABLE investments ADD COLUMN grandfathered_fmv REAL NOT NULL DEFAULT 0;
+	`,
+	// 7: chit funds
+	`CREATE TABLE IF NOT EXISTS chits (id TEXT PRIMARY KEY, data TEXT NOT NULL);`,
+	// 8: loans, and EMI expenses linked to schedule rows
+	`
+	CREATE TABLE IF NOT EXISTS loans (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	ALTER TABLE expenses ADD COLUMN loan_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE expenses ADD COLUMN instalment INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX IF NOT EXISTS idx_expenses_loan_id ON expenses(loan_id);
+	`,
+	// 9: fixed and recurring deposit terms
+	`ALTER TABLE investments ADD COLUMN deposit TEXT NOT NULL DEFAULT '';`,
+	// 10: accounts and transfers, and the account each income and expense moved
+	`
+	CREATE TABLE IF NOT EXISTS accounts (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	CREATE TABLE IF NOT EXISTS transfers (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	ALTER TABLE incomes ADD COLUMN account_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE expenses ADD COLUMN account_id TEXT NOT NULL DEFAULT '';
+	`,
+	// 11: statement column mapping profiles and imports awaiting review
+	`
+	CREATE TABLE IF NOT EXISTS statement_profiles (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	CREATE TABLE IF NOT EXISTS statement_imports (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	`,
+	// 12: bank transaction IDs of records imported from OFX
+	`
+	ALTER TABLE incomes ADD COLUMN fit_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE expenses ADD COLUMN fit_id TEXT NOT NULL DEFAULT '';
+	`,
+	// 13: history of merged duplicate expenses and incomes
+	`CREATE TABLE IF NOT EXISTS merges (id TEXT PRIMARY KEY, data TEXT NOT NULL);`,
+	// 14: categorisation rules, and tags on incomes and expenses
+	`
+	CREATE TABLE IF NOT EXISTS rules (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	ALTER TABLE incomes ADD COLUMN tags TEXT NOT NULL DEFAULT '';
+	ALTER TABLE expenses ADD COLUMN tags TEXT NOT NULL DEFAULT '';
+	`,
+	// 15: tags on investments
+	`ALTER TABLE investments ADD COLUMN tags TEXT NOT NULL DEFAULT '';`,
+	// 16: split lines of expenses
+	`ALTER TABLE expenses ADD COLUMN splits TEXT NOT NULL DEFAULT '';`,
+	// 17: settlements between members, and who paid and shared each expense
+	`
+	CREATE TABLE IF NOT EXISTS settlements (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	ALTER TABLE expenses ADD COLUMN paid_by TEXT NOT NULL DEFAULT '';
+	ALTER TABLE expenses ADD COLUMN share_mode TEXT NOT NULL DEFAULT '';
+	ALTER TABLE expenses ADD COLUMN shared_among TEXT NOT NULL DEFAULT '';
+	`,
+	// 18: user logins and their sessions
+	`
+	CREATE TABLE IF NOT EXISTS users (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	CREATE TABLE IF NOT EXISTS sessions (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+	`,
+	// 19: API tokens for scripts
+	`CREATE TABLE IF NOT EXISTS api_tokens (id TEXT PRIMARY KEY, data TEXT NOT NULL);`,
+}
+
+// migrate brings the database schema up to date
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", version, len(sqliteMigrations))
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
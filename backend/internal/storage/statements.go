@@ -0,0 +1,123 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// statementProfileDocs stores statement profiles (see docKind)
+var statementProfileDocs = docKind[models.StatementProfile]{
+	noun:       "statement profile",
+	collection: statementProfilesCollection,
+	table:      "statement_profiles",
+	slice:      func(ds *DataStore) *[]models.StatementProfile { return &ds.statementProfiles },
+	id:         func(p models.StatementProfile) string { return p.ID },
+	validate:   (*models.StatementProfile).Validate,
+}
+
+// ----- DataStore -----
+
+// GetStatementProfiles returns all statement profiles
+func (ds *DataStore) GetStatementProfiles() []models.StatementProfile {
+	return statementProfileDocs.dsGet(ds)
+}
+
+// AddStatementProfile adds a new statement profile
+func (ds *DataStore) AddStatementProfile(p models.StatementProfile) error {
+	return statementProfileDocs.dsAdd(ds, p)
+}
+
+// UpdateStatementProfile updates an existing statement profile
+func (ds *DataStore) UpdateStatementProfile(id string, updated models.StatementProfile) error {
+	return statementProfileDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteStatementProfile removes a statement profile
+func (ds *DataStore) DeleteStatementProfile(id string) error {
+	return statementProfileDocs.dsDelete(ds, id)
+}
+
+// SaveStatementProfiles writes statement profiles to file
+func (ds *DataStore) SaveStatementProfiles() error { return statementProfileDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetStatementProfiles returns all statement profiles
+func (s *SQLiteStore) GetStatementProfiles() []models.StatementProfile {
+	return statementProfileDocs.sqlGet(s)
+}
+
+// AddStatementProfile adds a new statement profile
+func (s *SQLiteStore) AddStatementProfile(p models.StatementProfile) error {
+	return statementProfileDocs.sqlAdd(s, p)
+}
+
+// UpdateStatementProfile updates an existing statement profile
+func (s *SQLiteStore) UpdateStatementProfile(id string, updated models.StatementProfile) error {
+	return statementProfileDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteStatementProfile removes a statement profile
+func (s *SQLiteStore) DeleteStatementProfile(id string) error {
+	return statementProfileDocs.sqlDelete(s, id)
+}
+
+// SaveStatementProfiles is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveStatementProfiles() error { return nil }
+
+// statementImportDocs stores statement imports (see docKind)
+var statementImportDocs = docKind[models.StatementImport]{
+	noun:       "statement import",
+	collection: statementImportsCollection,
+	table:      "statement_imports",
+	slice:      func(ds *DataStore) *[]models.StatementImport { return &ds.statementImports },
+	id:         func(si models.StatementImport) string { return si.ID },
+	validate:   (*models.StatementImport).Validate,
+}
+
+// ----- DataStore -----
+
+// GetStatementImports returns all statement imports
+func (ds *DataStore) GetStatementImports() []models.StatementImport {
+	return statementImportDocs.dsGet(ds)
+}
+
+// AddStatementImport adds a new statement import
+func (ds *DataStore) AddStatementImport(si models.StatementImport) error {
+	return statementImportDocs.dsAdd(ds, si)
+}
+
+// UpdateStatementImport updates an existing statement import
+func (ds *DataStore) UpdateStatementImport(id string, updated models.StatementImport) error {
+	return statementImportDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteStatementImport removes a statement import
+func (ds *DataStore) DeleteStatementImport(id string) error {
+	return statementImportDocs.dsDelete(ds, id)
+}
+
+// SaveStatementImports writes statement imports to file
+func (ds *DataStore) SaveStatementImports() error { return statementImportDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetStatementImports returns all statement imports
+func (s *SQLiteStore) GetStatementImports() []models.StatementImport {
+	return statementImportDocs.sqlGet(s)
+}
+
+// AddStatementImport adds a new statement import
+func (s *SQLiteStore) AddStatementImport(si models.StatementImport) error {
+	return statementImportDocs.sqlAdd(s, si)
+}
+
+// UpdateStatementImport updates an existing statement import
+func (s *SQLiteStore) UpdateStatementImport(id string, updated models.StatementImport) error {
+	return statementImportDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteStatementImport removes a statement import
+func (s *SQLiteStore) DeleteStatementImport(id string) error {
+	return statementImportDocs.sqlDelete(s, id)
+}
+
+// SaveStatementImports is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveStatementImports() error { return nil }
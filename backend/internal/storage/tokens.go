@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// apiTokenDocs stores API tokens (see docKind)
+var apiTokenDocs = docKind[models.APIToken]{
+	noun:       "API token",
+	collection: apiTokensCollection,
+	table:      "api_tokens",
+	slice:      func(ds *DataStore) *[]models.APIToken { return &ds.apiTokens },
+	id:         func(tok models.APIToken) string { return tok.ID },
+	validate:   (*models.APIToken).Validate,
+}
+
+// ----- DataStore -----
+
+// GetAPITokens returns all API tokens
+func (ds *DataStore) GetAPITokens() []models.APIToken { return apiTokenDocs.dsGet(ds) }
+
+// AddAPIToken adds a new API token
+func (ds *DataStore) AddAPIToken(tok models.APIToken) error { return apiTokenDocs.dsAdd(ds, tok) }
+
+// UpdateAPIToken updates an existing API token
+func (ds *DataStore) UpdateAPIToken(id string, updated models.APIToken) error {
+	return apiTokenDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteAPIToken removes a API token
+func (ds *DataStore) DeleteAPIToken(id string) error { return apiTokenDocs.dsDelete(ds, id) }
+
+// SaveAPITokens writes API tokens to file
+func (ds *DataStore) SaveAPITokens() error { return apiTokenDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetAPITokens returns all API tokens
+func (s *SQLiteStore) GetAPITokens() []models.APIToken { return apiTokenDocs.sqlGet(s) }
+
+// AddAPIToken adds a new API token
+func (s *SQLiteStore) AddAPIToken(tok models.APIToken) error { return apiTokenDocs.sqlAdd(s, tok) }
+
+// UpdateAPIToken updates an existing API token
+func (s *SQLiteStore) UpdateAPIToken(id string, updated models.APIToken) error {
+	return apiTokenDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteAPIToken removes a API token
+func (s *SQLiteStore) DeleteAPIToken(id string) error { return apiTokenDocs.sqlDelete(s, id) }
+
+// SaveAPITokens is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveAPITokens() error { return nil }
@@ -0,0 +1,51 @@
+package storage
+
+import "finance-tracker/internal/models"
+
+// userDocs stores users (see docKind)
+var userDocs = docKind[models.User]{
+	noun:       "user",
+	collection: usersCollection,
+	table:      "users",
+	slice:      func(ds *DataStore) *[]models.User { return &ds.users },
+	id:         func(u models.User) string { return u.ID },
+	validate:   (*models.User).Validate,
+}
+
+// ----- DataStore -----
+
+// GetUsers returns all users
+func (ds *DataStore) GetUsers() []models.User { return userDocs.dsGet(ds) }
+
+// AddUser adds a new user
+func (ds *DataStore) AddUser(u models.User) error { return userDocs.dsAdd(ds, u) }
+
+// UpdateUser updates an existing user
+func (ds *DataStore) UpdateUser(id string, updated models.User) error {
+	return userDocs.dsUpdate(ds, id, updated)
+}
+
+// DeleteUser removes a user
+func (ds *DataStore) DeleteUser(id string) error { return userDocs.dsDelete(ds, id) }
+
+// SaveUsers writes users to file
+func (ds *DataStore) SaveUsers() error { return userDocs.dsSave(ds) }
+
+// ----- SQLiteStore -----
+
+// GetUsers returns all users
+func (s *SQLiteStore) GetUsers() []models.User { return userDocs.sqlGet(s) }
+
+// AddUser adds a new user
+func (s *SQLiteStore) AddUser(u models.User) error { return userDocs.sqlAdd(s, u) }
+
+// UpdateUser updates an existing user
+func (s *SQLiteStore) UpdateUser(id string, updated models.User) error {
+	return userDocs.sqlUpdate(s, id, updated)
+}
+
+// DeleteUser removes a user
+func (s *SQLiteStore) DeleteUser(id string) error { return userDocs.sqlDelete(s, id) }
+
+// SaveUsers is a no-op; changes are persisted as they are made
+func (s *SQLiteStore) SaveUsers() error { return nil }
@@ -0,0 +1,56 @@
+// Package tags handles the free-form labels on expenses, incomes and
+// investments. Tags are compared ignoring case, and keep the spelling they
+// were first given.
+package tags
+
+import "strings"
+
+// Add appends the tags of add not already in tags
+func Add(tags []string, add ...string) []string {
+	for _, tag := range add {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !Has(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Has reports whether tags holds tag
+func Has(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAll reports whether tags holds every tag of want
+func HasAll(tags, want []string) bool {
+	for _, tag := range want {
+		if !Has(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Replace swaps the tags of from found in tags for to, in the place of the
+// first one, and reports whether any was found. Renaming a tag to one the
+// record already has merges the two.
+func Replace(tags []string, from []string, to string) ([]string, bool) {
+	var out []string
+	found := false
+	for _, t := range tags {
+		if Has(from, t) {
+			found = true
+			t = to
+		}
+		out = Add(out, t)
+	}
+	if !found {
+		return tags, false
+	}
+	return out, true
+}
@@ -4,6 +4,7 @@ import { Navigation } from './components/Layout/Navigation';
 import { Dashboard } from './components/Dashboard/Dashboard';
 import { LoadingSpinner } from './components/common/LoadingSpinner';
 import { ErrorMessage } from './components/common/ErrorMessage';
+import { Login } from './components/Auth/Login';
 import AutocompleteInput from './components/common/AutocompleteInput';
 import { useInvestments } from './hooks/useInvestments';
 import { useIncomes } from './hooks/useIncomes';
@@ -29,6 +30,8 @@ export default function App() {
   const [editingItem, setEditingItem] = useState(null);
   const [globalError, setGlobalError] = useState(null);
   const [isInitialLoading, setIsInitialLoading] = useState(true);
+  const [user, setUser] = useState(null);
+  const [needsLogin, setNeedsLogin] = useState(false);
   
   // Month filter for tabs (YYYY-MM format)
   const getCurrentMonthString = () => {
@@ -98,6 +101,11 @@ export default function App() {
 
   // ----- LOAD DATA ON START -----
   useEffect(() => {
+    // Any 401 from the backend means logging in again
+    api.setUnauthorizedHandler(() => {
+      setUser(null);
+      setNeedsLogin(true);
+    });
     loadData();
   }, []);
 
@@ -113,6 +121,13 @@ export default function App() {
         throw new Error('⚠️ Backend server is not running. Please start the server on port 5000.');
       }
 
+      // Everything else needs a logged-in user
+      if (!api.hasSession()) {
+        setNeedsLogin(true);
+        return;
+      }
+      setUser(await api.getMe());
+
       // Load data in parallel
       await Promise.all([
         fetchInvestments(),
@@ -127,6 +142,23 @@ export default function App() {
     }
   };
 
+  // ----- LOGIN/LOGOUT -----
+
+  const handleLogin = (loggedIn) => {
+    setUser(loggedIn);
+    setNeedsLogin(false);
+    loadData();
+  };
+
+  const handleLogout = async () => {
+    try {
+      await api.logout();
+    } finally {
+      setUser(null);
+      setNeedsLogin(true);
+    }
+  };
+
   // ----- INVESTMENT FUNCTIONS -----
 
   // Refresh NAV for all mutual fund investments
@@ -386,13 +418,18 @@ export default function App() {
   // Show loading state
   if (isInitialLoading) return <LoadingSpinner message="Loading your financial data..." />;
 
+  // Show login screen until someone logs in
+  if (needsLogin) return <Login onLogin={handleLogin} />;
+
   return (
     <div className="app">
       {/* HEADER */}
       <Header 
+        user={user}
         onExport={handleExport}
         onImport={handleImport}
         onRefresh={loadData}
+        onLogout={handleLogout}
       />
 
       {/* NAVIGATION TABS */}
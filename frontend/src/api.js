@@ -40,20 +40,47 @@ async function checkBackendHealth() {
   }
 }
 
+// Session token from logging in, kept across page reloads
+const TOKEN_KEY = 'financeTrackerToken';
+let authToken = localStorage.getItem(TOKEN_KEY);
+
+function setToken(token) {
+  authToken = token;
+  if (token) {
+    localStorage.setItem(TOKEN_KEY, token);
+  } else {
+    localStorage.removeItem(TOKEN_KEY);
+  }
+}
+
+// Called when the backend answers 401, so the app can show the login screen
+let onUnauthorized = () => {};
+
 // Generic request function - handles all HTTP calls
 async function request(endpoint, options = {}) {
   const controller = new AbortController();
   const timeoutId = setTimeout(() => controller.abort(), 10000); // 10 second timeout for requests
 
   try {
+    const headers = { 'Content-Type': 'application/json' };
+    if (authToken) {
+      headers.Authorization = `Bearer ${authToken}`;
+    }
     const response = await fetch(`${BASE_URL}${endpoint}`, {
-      headers: { 'Content-Type': 'application/json' },
+      headers,
+      credentials: 'include', // Sends the session cookie too
       signal: controller.signal,
       ...options,
     });
 
     clearTimeout(timeoutId);
 
+    // Session expired or never logged in: back to the login screen
+    if (response.status === 401 && endpoint !== '/auth/login' && endpoint !== '/auth/setup') {
+      setToken(null);
+      onUnauthorized();
+    }
+
     // If request failed, throw error
     if (!response.ok) {
       const errorData = await response.json().catch(() => ({ error: response.statusText }));
@@ -75,6 +102,16 @@ async function request(endpoint, options = {}) {
   }
 }
 
+// Log in (or create the first user) and keep the session token
+async function startSession(endpoint, credentials) {
+  const session = await request(endpoint, {
+    method: 'POST',
+    body: JSON.stringify(credentials)
+  });
+  setToken(session.token);
+  return session.user;
+}
+
 // Export all API functions
 export const api = {
   // ===== HEALTH CHECK =====
@@ -83,6 +120,35 @@ export const api = {
   // Usage: const isUp = await api.checkHealth();
   checkHealth: checkBackendHealth,
 
+  // ===== AUTHENTICATION =====
+
+  // Whether a session token is kept from an earlier login
+  hasSession: () => Boolean(authToken),
+
+  // Called with no arguments whenever the session is no longer valid
+  // Usage: api.setUnauthorizedHandler(() => setUser(null));
+  setUnauthorizedHandler: (handler) => { onUnauthorized = handler; },
+
+  // Log in and return the user
+  // Usage: const user = await api.login("ravi", "password");
+  login: (username, password) => startSession('/auth/login', { username, password }),
+
+  // Create the first user with the setup code the server printed at startup
+  // Usage: const user = await api.setup({username, password, member, setupCode});
+  setup: (data) => startSession('/auth/setup', data),
+
+  // The logged-in user
+  getMe: () => request('/auth/me'),
+
+  // End the session
+  logout: async () => {
+    try {
+      await request('/auth/logout', { method: 'POST' });
+    } finally {
+      setToken(null);
+    }
+  },
+
   // ===== INVESTMENTS =====
   
   // Get all investments
@@ -0,0 +1,89 @@
+import { useState } from 'react';
+import { ErrorMessage } from '../common/ErrorMessage';
+import { api } from '../../api';
+import './Login.css';
+
+// Login screen, shown until a user logs in. The first time, the owner
+// creates their login with the setup code the backend prints at startup.
+export const Login = ({ onLogin }) => {
+  const [mode, setMode] = useState('login'); // 'login' or 'setup'
+  const [form, setForm] = useState({ username: '', password: '', member: '', setupCode: '' });
+  const [error, setError] = useState(null);
+  const [submitting, setSubmitting] = useState(false);
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setError(null);
+    setSubmitting(true);
+    try {
+      const user = mode === 'setup'
+        ? await api.setup(form)
+        : await api.login(form.username, form.password);
+      onLogin(user);
+    } catch (err) {
+      setError(err.message);
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  const switchMode = () => {
+    setMode(mode === 'login' ? 'setup' : 'login');
+    setError(null);
+  };
+
+  return (
+    <div className="login-screen">
+      <form className="form card login-card" onSubmit={handleSubmit}>
+        <h2 className="login-title">
+          <span className="app-icon">💰</span>
+          {mode === 'setup' ? 'Set up Finance Tracker' : 'Finance Tracker'}
+        </h2>
+
+        {error && <ErrorMessage message={error} />}
+
+        <input
+          placeholder="Username"
+          autoComplete="username"
+          value={form.username}
+          onChange={e => setForm({...form, username: e.target.value})}
+          required
+        />
+        <input
+          type="password"
+          placeholder="Password"
+          autoComplete={mode === 'setup' ? 'new-password' : 'current-password'}
+          value={form.password}
+          onChange={e => setForm({...form, password: e.target.value})}
+          required
+        />
+        {mode === 'setup' && (
+          <>
+            <input
+              placeholder="Family member (as in settings, e.g. Ravi)"
+              value={form.member}
+              onChange={e => setForm({...form, member: e.target.value})}
+              required
+            />
+            <input
+              placeholder="Setup code (printed when the backend starts)"
+              value={form.setupCode}
+              onChange={e => setForm({...form, setupCode: e.target.value})}
+              required
+            />
+          </>
+        )}
+
+        <div className="btn-row">
+          <button type="submit" className="primary" disabled={submitting}>
+            {mode === 'setup' ? 'Create owner login' : 'Log in'}
+          </button>
+        </div>
+
+        <button type="button" className="login-switch" onClick={switchMode}>
+          {mode === 'login' ? 'First time here? Set up the first login' : 'Back to log in'}
+        </button>
+      </form>
+    </div>
+  );
+};
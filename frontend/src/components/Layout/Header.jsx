@@ -1,6 +1,6 @@
 import './Header.css';
 
-export const Header = ({ onExport, onImport, onRefresh }) => {
+export const Header = ({ user, onExport, onImport, onRefresh, onLogout }) => {
   const handleImportClick = () => {
     const input = document.createElement('input');
     input.type = 'file';
@@ -25,6 +25,11 @@ export const Header = ({ onExport, onImport, onRefresh }) => {
         <button onClick={onRefresh} className="header-btn refresh-btn" title="Refresh Data">
           ↻
         </button>
+        {user && (
+          <button onClick={onLogout} className="header-btn" title={`Logged in as ${user.username}`}>
+            Log out
+          </button>
+        )}
       </div>
     </header>
   );